@@ -2,24 +2,37 @@ package api
 
 import (
 	"fmt"
+	"html"
 	"net/http"
-	"os"
+	"strings"
 
 	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/problem"
 )
 
 // GET /admin/metrics
+//
+// It shows the same metrics as GET /metrics, for humans.
 func GetAdminMetrics(env *appenv.Env) http.Handler {
 	return http.StripPrefix("/admin/",
-		http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+			var metrics strings.Builder
+
+			if err := env.Metrics.WriteText(&metrics); err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
 			writer.Header().Set("Content-Type", "text/html; charset=utf-8")
 
 			fmt.Fprintf(writer, `<html>
   <body>
     <h1>Welcome, Chirpy Admin</h1>
     <p>Chirpy has been visited %d times!</p>
+    <pre>%s</pre>
   </body>
-</html>`, env.FileserverHits.Load())
+</html>`, env.Metrics.FileserverHits(), html.EscapeString(metrics.String()))
 		}),
 	)
 }
@@ -27,19 +40,19 @@ func GetAdminMetrics(env *appenv.Env) http.Handler {
 func PostAdminReset(env *appenv.Env) http.Handler {
 	return http.HandlerFunc(
 		func(writer http.ResponseWriter, req *http.Request) {
-			if os.Getenv("PLATFORM") != "dev" {
-				writer.WriteHeader(http.StatusForbidden)
+			if env.Config.Platform != "dev" {
+				problem.Forbidden(writer)
 
 				return
 			}
 
 			if err := env.DB.DeleteAllUsers(req.Context()); err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.Internal(writer, req, err)
 
 				return
 			}
 
-			env.FileserverHits.Swap(0)
+			env.Metrics.ResetFileserverHits()
 
 			writer.WriteHeader(http.StatusOK)
 		},
@@ -2,26 +2,32 @@
 package api
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/zyrterviews/chirpy/internal/appenv"
 	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/chirptext"
 	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/validate"
 )
 
-const (
-	jwtExpirationTime          = 1 * time.Hour
-	refreshTokenExpirationTime = 60 * 24 * time.Hour // 60 days
-)
+// uniqueViolation is the Postgres error code raised when a UNIQUE constraint
+// is violated.
+const uniqueViolation pq.ErrorCode = "23505"
 
 type User struct {
 	ID           uuid.UUID `json:"id"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	Email        string    `json:"email"`
+	Username     string    `json:"username,omitempty"`
 	Token        string    `json:"token"`
 	RefreshToken string    `json:"refresh_token"`
 	IsChirpyRed  bool      `json:"is_chirpy_red"`
@@ -38,47 +44,51 @@ func Login(env *appenv.Env) http.Handler {
 
 			var data input
 
-			decoder := json.NewDecoder(req.Body)
-
-			if err := decoder.Decode(&data); err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
-
+			if !validate.Decode(writer, req, &data) {
 				return
 			}
 
 			user, err := env.DB.GetUserByEmail(req.Context(), data.Email)
 			if err != nil {
-				http.Error(
+				env.Metrics.Login(false)
+
+				problem.Write(
 					writer,
-					"email or password does not match",
 					http.StatusUnauthorized,
+					problem.CodeInvalidCredentials,
+					"email or password does not match",
 				)
 
 				return
 			}
 
 			if err := auth.CheckPasswordHash(data.Password, user.HashedPassword); err != nil {
-				http.Error(
+				env.Metrics.Login(false)
+
+				problem.Write(
 					writer,
-					"email or password does not match",
 					http.StatusUnauthorized,
+					problem.CodeInvalidCredentials,
+					"email or password does not match",
 				)
 
 				return
 			}
 
-			jwtExpiresIn := jwtExpirationTime
-
-			token, err := auth.MakeJWT(user.ID, env.JWTSecret, jwtExpiresIn)
+			token, err := auth.MakeJWT(
+				user.ID,
+				env.Config.JWTSecret.Reveal(),
+				env.Config.AccessTokenTTL,
+			)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.Internal(writer, req, err)
 
 				return
 			}
 
 			refreshToken, err := auth.MakeRefreshToken()
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.Internal(writer, req, err)
 
 				return
 			}
@@ -86,21 +96,24 @@ func Login(env *appenv.Env) http.Handler {
 			opts := database.CreateRefreshTokenParams{
 				Token:     refreshToken,
 				UserID:    user.ID,
-				ExpiresAt: time.Now().UTC().Add(refreshTokenExpirationTime),
+				ExpiresAt: time.Now().UTC().Add(env.Config.RefreshTokenTTL),
 			}
 
 			_, err = env.DB.CreateRefreshToken(req.Context(), opts)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.Internal(writer, req, err)
 
 				return
 			}
 
+			env.Metrics.Login(true)
+
 			resData := User{
 				ID:           user.ID,
 				CreatedAt:    user.CreatedAt,
 				UpdatedAt:    user.UpdatedAt,
 				Email:        user.Email,
+				Username:     user.Username.String,
 				Token:        token,
 				RefreshToken: refreshToken,
 				IsChirpyRed:  user.IsChirpyRed,
@@ -108,7 +121,7 @@ func Login(env *appenv.Env) http.Handler {
 
 			res, err := json.Marshal(&resData)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.Internal(writer, req, err)
 
 				return
 			}
@@ -126,7 +139,12 @@ func Refresh(env *appenv.Env) http.Handler {
 		func(writer http.ResponseWriter, req *http.Request) {
 			token, err := auth.GetBearerToken(req.Header)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusUnauthorized)
+				problem.Write(
+					writer,
+					http.StatusUnauthorized,
+					problem.CodeUnauthorized,
+					err.Error(),
+				)
 
 				return
 			}
@@ -135,31 +153,36 @@ func Refresh(env *appenv.Env) http.Handler {
 				dbToken.ExpiresAt.Before(time.Now().UTC()) ||
 				dbToken.ExpiresAt.Equal(time.Now().UTC()) ||
 				dbToken.RevokedAt.Valid {
-				msg := "token expired"
+				if err != nil && !errors.Is(err, sql.ErrNoRows) {
+					problem.Internal(writer, req, err)
 
-				if err != nil {
-					msg = err.Error()
+					return
 				}
 
-				http.Error(writer, msg, http.StatusUnauthorized)
+				problem.Write(
+					writer,
+					http.StatusUnauthorized,
+					problem.CodeUnauthorized,
+					"refresh token is unknown, expired or revoked",
+				)
 
 				return
 			}
 
 			user, err := env.DB.GetUserFromRefreshToken(req.Context(), token)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.Internal(writer, req, err)
 
 				return
 			}
 
 			newToken, err := auth.MakeJWT(
 				user.ID,
-				env.JWTSecret,
-				jwtExpirationTime,
+				env.Config.JWTSecret.Reveal(),
+				env.Config.AccessTokenTTL,
 			)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.Internal(writer, req, err)
 
 				return
 			}
@@ -170,7 +193,7 @@ func Refresh(env *appenv.Env) http.Handler {
 
 			res, err := json.Marshal(resData)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.Internal(writer, req, err)
 
 				return
 			}
@@ -186,14 +209,19 @@ func Revoke(env *appenv.Env) http.Handler {
 		func(writer http.ResponseWriter, req *http.Request) {
 			token, err := auth.GetBearerToken(req.Header)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusUnauthorized)
+				problem.Write(
+					writer,
+					http.StatusUnauthorized,
+					problem.CodeUnauthorized,
+					err.Error(),
+				)
 
 				return
 			}
 
 			err = env.DB.RevokeRefreshToken(req.Context(), token)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusUnauthorized)
+				problem.Internal(writer, req, err)
 
 				return
 			}
@@ -214,17 +242,22 @@ func Signup(env *appenv.Env) http.Handler {
 
 			var data input
 
-			decoder := json.NewDecoder(req.Body)
+			if !validate.Decode(writer, req, &data) {
+				return
+			}
+
+			var errs validate.Errors
 
-			if err := decoder.Decode(&data); err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+			errs.Email("email", data.Email)
+			errs.Password("password", data.Password)
 
+			if errs.Write(writer) {
 				return
 			}
 
 			hashedPwd, err := auth.HashPassword(data.Password)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.Internal(writer, req, err)
 
 				return
 			}
@@ -236,7 +269,19 @@ func Signup(env *appenv.Env) http.Handler {
 
 			user, err := env.DB.CreateUser(req.Context(), opts)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				var pqErr *pq.Error
+				if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+					problem.Write(
+						writer,
+						http.StatusConflict,
+						problem.CodeAlreadyTaken,
+						"email is already taken",
+					)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
 
 				return
 			}
@@ -251,7 +296,7 @@ func Signup(env *appenv.Env) http.Handler {
 
 			res, err := json.Marshal(&resData)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.Internal(writer, req, err)
 
 				return
 			}
@@ -268,43 +313,86 @@ func Signup(env *appenv.Env) http.Handler {
 func PutUser(env *appenv.Env) http.Handler {
 	return http.HandlerFunc(
 		func(writer http.ResponseWriter, req *http.Request) {
-			if env.UserID == uuid.Nil {
-				http.Error(writer, "UNAUTHORIZED", http.StatusUnauthorized)
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
 
 				return
 			}
 
 			type input struct {
-				Email    string `json:"email"`
-				Password string `json:"password"`
+				Email    string  `json:"email"`
+				Password string  `json:"password"`
+				Username *string `json:"username"`
 			}
 
 			var data input
 
-			decoder := json.NewDecoder(req.Body)
+			if !validate.Decode(writer, req, &data) {
+				return
+			}
+
+			var (
+				errs     validate.Errors
+				username sql.NullString
+			)
+
+			errs.Email("email", data.Email)
+			errs.Password("password", data.Password)
+
+			if data.Username != nil {
+				if !chirptext.IsValidUsername(*data.Username) {
+					errs.Add(
+						"username",
+						"must be 3 to 30 letters, digits or underscores",
+					)
+				}
 
-			if err := decoder.Decode(&data); err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				username = sql.NullString{
+					String: chirptext.NormalizeUsername(*data.Username),
+					Valid:  true,
+				}
+			}
 
+			if errs.Write(writer) {
 				return
 			}
 
 			hashedPwd, err := auth.HashPassword(data.Password)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.Internal(writer, req, err)
 
 				return
 			}
 
 			opts := database.UpdateUserParams{
-				ID:             env.UserID,
+				ID:             userID,
 				Email:          data.Email,
 				HashedPassword: hashedPwd,
+				Username:       username,
 			}
 
 			newUser, err := env.DB.UpdateUser(req.Context(), opts)
+			if errors.Is(err, sql.ErrNoRows) {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				var pqErr *pq.Error
+				if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+					problem.Write(
+						writer,
+						http.StatusConflict,
+						problem.CodeAlreadyTaken,
+						"email or username is already taken",
+					)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
 
 				return
 			}
@@ -314,12 +402,13 @@ func PutUser(env *appenv.Env) http.Handler {
 				CreatedAt:   newUser.CreatedAt,
 				UpdatedAt:   newUser.UpdatedAt,
 				Email:       newUser.Email,
+				Username:    newUser.Username.String,
 				IsChirpyRed: newUser.IsChirpyRed,
 			}
 
 			res, err := json.Marshal(resData)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.Internal(writer, req, err)
 
 				return
 			}
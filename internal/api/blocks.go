@@ -0,0 +1,123 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/problem"
+)
+
+// PUT /api/users/{userID}/block
+//
+// Blocks a user: neither can follow or send direct messages to the other
+// until the block is lifted. Existing follows between them are removed.
+// Blocking a user twice is a no-op.
+func PutBlock(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("userID"))
+			if err != nil {
+				problem.BadRequest(writer, "userID must be a UUID")
+
+				return
+			}
+
+			if id == userID {
+				problem.BadRequest(writer, "you cannot block yourself")
+
+				return
+			}
+
+			blocked, err := env.DB.GetUserByID(req.Context(), id)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.NotFound(writer)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			err = env.InTx(req.Context(), func(qtx *database.Queries) error {
+				err := qtx.CreateBlock(req.Context(), database.CreateBlockParams{
+					BlockerID: userID,
+					BlockedID: blocked.ID,
+				})
+				if err != nil {
+					return err
+				}
+
+				return qtx.DeleteFollowsBetween(
+					req.Context(),
+					database.DeleteFollowsBetweenParams{
+						UserID:  userID,
+						OtherID: blocked.ID,
+					},
+				)
+			})
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
+
+// DELETE /api/users/{userID}/block
+func DeleteBlock(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("userID"))
+			if err != nil {
+				problem.BadRequest(writer, "userID must be a UUID")
+
+				return
+			}
+
+			opts := database.DeleteBlockParams{
+				BlockerID: userID,
+				BlockedID: id,
+			}
+
+			deleted, err := env.DB.DeleteBlock(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if deleted == 0 {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/pagination"
+	"github.com/zyrterviews/chirpy/internal/problem"
+)
+
+// GET /api/bookmarks
+func GetBookmarks(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			limit, offset, err := pagination.Parse(req)
+			if err != nil {
+				problem.BadRequest(writer, err.Error())
+
+				return
+			}
+
+			opts := database.GetBookmarkedChirpsParams{
+				UserID:    userID,
+				RowLimit:  limit,
+				RowOffset: offset,
+			}
+
+			chirps, err := env.DB.GetBookmarkedChirps(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeChirps(writer, req, env, chirps)
+		},
+	)
+}
+
+// PUT /api/bookmarks/{chirpID}
+//
+// Bookmarking a chirp twice is a no-op.
+func PutBookmark(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("chirpID"))
+			if err != nil {
+				problem.BadRequest(writer, "chirpID must be a UUID")
+
+				return
+			}
+
+			chirp, err := env.DB.GetChirpByID(req.Context(), id)
+			if err == nil && chirp.Status != chirpStatusPublished {
+				err = sql.ErrNoRows
+			}
+
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.NotFound(writer)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			opts := database.CreateBookmarkParams{
+				UserID:  userID,
+				ChirpID: chirp.ID,
+			}
+
+			if err := env.DB.CreateBookmark(req.Context(), opts); err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
+
+// DELETE /api/bookmarks/{chirpID}
+func DeleteBookmark(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("chirpID"))
+			if err != nil {
+				problem.BadRequest(writer, "chirpID must be a UUID")
+
+				return
+			}
+
+			opts := database.DeleteBookmarkParams{
+				UserID:  userID,
+				ChirpID: id,
+			}
+
+			deleted, err := env.DB.DeleteBookmark(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if deleted == 0 {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
@@ -1,118 +1,229 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"regexp"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
 	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/events"
+	"github.com/zyrterviews/chirpy/internal/logging"
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/validate"
 )
 
 const maxChirpLength int = 140
 
+const (
+	chirpStatusPublished = "published"
+	chirpStatusScheduled = "scheduled"
+	chirpStatusDraft     = "draft"
+)
+
+var errChirpTooLong = fmt.Errorf(
+	"chirp is longer than %d characters",
+	maxChirpLength,
+)
+
 type Chirp struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
-	UserID    uuid.UUID `json:"user_id"`
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Body      string     `json:"body"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Status    string     `json:"status"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	Entities  []Entity   `json:"entities"`
+	Media     []Media    `json:"media"`
+	Poll      *Poll      `json:"poll,omitempty"`
+	ReplyToID *uuid.UUID `json:"reply_to_id,omitempty"`
+	LikeCount int64      `json:"like_count"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // POST /api/chirps
 func PostOneChirp(env *appenv.Env) http.Handler {
 	return http.HandlerFunc(
 		func(writer http.ResponseWriter, req *http.Request) {
-			if env.UserID == uuid.Nil {
-				http.Error(writer, "UNAUTHORIZED", http.StatusUnauthorized)
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
 
 				return
 			}
 
 			type input struct {
-				Body string `json:"body"`
-			}
-
-			profanities := []string{
-				"kerfuffle",
-				"sharbert",
-				"fornax",
+				Body     string      `json:"body"`
+				MediaIDs []uuid.UUID `json:"media_ids"`
+				// PublishAt schedules the chirp instead of publishing it
+				// right away.
+				PublishAt *time.Time `json:"publish_at"`
+				// Draft keeps the chirp unpublished until the author
+				// publishes it.
+				Draft bool `json:"draft"`
+				// Poll attaches a poll that closes at Poll.ClosesAt.
+				Poll *PollInput `json:"poll"`
+				// ReplyToID makes the chirp a reply to another one.
+				ReplyToID *uuid.UUID `json:"reply_to_id"`
 			}
 
 			writer.Header().Set("Content-Type", "application/json")
 
 			var data input
 
-			decoder := json.NewDecoder(req.Body)
+			if !validate.Decode(writer, req, &data) {
+				return
+			}
+
+			var errs validate.Errors
 
-			if err := decoder.Decode(&data); err != nil {
-				resData := struct {
-					Error string `json:"error"`
-				}{Error: "Something went wrong"}
-				res, _ := json.Marshal(resData)
+			errs.MaxLength("body", data.Body, maxChirpLength)
 
-				writer.WriteHeader(http.StatusInternalServerError)
-				_, _ = writer.Write(res)
+			status, publishAt := chirpSchedule(data.Draft, data.PublishAt)
 
-				return
+			var (
+				pollLabels []string
+				err        error
+			)
+
+			if data.Poll != nil {
+				opensAt := time.Now()
+				if publishAt.Valid {
+					opensAt = publishAt.Time
+				}
+
+				pollLabels, err = validatePoll(data.Poll, opensAt)
+				if err != nil {
+					errs.Add("poll", err.Error())
+				}
 			}
 
-			if len(data.Body) > maxChirpLength {
-				resData := struct {
-					Error string `json:"error"`
-				}{Error: "Chirp is too long"}
-				res, _ := json.Marshal(resData)
+			var replyTo uuid.NullUUID
 
-				writer.WriteHeader(http.StatusBadRequest)
-				_, _ = writer.Write(res)
+			if data.ReplyToID != nil {
+				parent, err := env.DB.GetChirpByID(req.Context(), *data.ReplyToID)
+				if err != nil && !errors.Is(err, sql.ErrNoRows) {
+					problem.Internal(writer, req, err)
 
-				return
+					return
+				}
+
+				if err != nil || parent.Status != chirpStatusPublished {
+					errs.Add("reply_to_id", "must be a published chirp")
+				}
+
+				replyTo = uuid.NullUUID{UUID: *data.ReplyToID, Valid: true}
 			}
 
-			for _, p := range profanities {
-				re := regexp.MustCompile("(?i)" + p)
-				data.Body = re.ReplaceAllString(data.Body, "****")
+			if errs.Write(writer) {
+				return
 			}
 
+			body := censorChirpBody(data.Body)
+
 			opts := database.CreateChirpParams{
-				Body:   data.Body,
-				UserID: env.UserID,
+				Body:      body,
+				UserID:    userID,
+				Status:    status,
+				PublishAt: publishAt,
+				ReplyToID: replyTo,
 			}
 
-			chirp, err := env.DB.CreateChirp(req.Context(), opts)
-			if err != nil {
-				resData := struct {
-					Error string `json:"error"`
-				}{Error: "Something went wrong"}
-				res, _ := json.Marshal(resData)
+			var (
+				chirp     database.Chirp
+				entities  []Entity
+				mentioned []uuid.UUID
+				attached  []database.Medium
+			)
+
+			err = env.InTx(req.Context(), func(qtx *database.Queries) error {
+				var err error
+
+				chirp, err = qtx.CreateChirp(req.Context(), opts)
+				if err != nil {
+					return err
+				}
+
+				entities, mentioned, err = syncChirpEntities(
+					req.Context(),
+					qtx,
+					chirp,
+				)
+				if err != nil {
+					return err
+				}
+
+				attached, err = attachChirpMedia(
+					req.Context(),
+					qtx,
+					userID,
+					chirp.ID,
+					data.MediaIDs,
+				)
+				if err != nil || data.Poll == nil {
+					return err
+				}
+
+				return createChirpPoll(
+					req.Context(),
+					qtx,
+					chirp.ID,
+					pollLabels,
+					data.Poll.ClosesAt,
+				)
+			})
+			if errors.Is(err, errUnknownMedia) {
+				problem.InvalidField(writer, "media_ids", err.Error())
+
+				return
+			}
 
-				writer.WriteHeader(http.StatusInternalServerError)
-				_, _ = writer.Write(res)
+			if err != nil {
+				problem.Internal(writer, req, err)
 
 				return
 			}
 
-			resData := Chirp{
-				ID:        chirp.ID,
-				CreatedAt: chirp.CreatedAt,
-				UpdatedAt: chirp.UpdatedAt,
-				Body:      chirp.Body,
-				UserID:    chirp.UserID,
+			env.Metrics.ChirpCreated(chirp.Status)
+
+			if chirp.Status == chirpStatusPublished {
+				notifyMentions(req.Context(), env, chirp, mentioned)
+				notifyReply(req.Context(), env, chirp)
+			}
+
+			resData := toChirp(chirp)
+			resData.Entities = entities
+
+			for _, medium := range attached {
+				resData.Media = append(resData.Media, toMedia(env, medium))
+			}
+
+			if data.Poll != nil {
+				polls, err := loadChirpPolls(
+					req.Context(),
+					env,
+					[]uuid.UUID{chirp.ID},
+					userID,
+				)
+				if err == nil {
+					resData.Poll = polls[chirp.ID]
+				}
 			}
 
 			res, err := json.Marshal(resData)
 			if err != nil {
-				resData := struct {
-					Error string `json:"error"`
-				}{Error: "Something went wrong"}
-				res, _ := json.Marshal(resData)
+				problem.Internal(writer, req, err)
 
-				writer.WriteHeader(http.StatusInternalServerError)
-				_, _ = writer.Write(res)
+				return
 			}
 
 			writer.WriteHeader(http.StatusCreated)
@@ -137,22 +248,16 @@ func GetAllChirps(env *appenv.Env) http.Handler {
 			case "":
 				chirps, err = env.DB.GetAllChirps(req.Context(), sort)
 				if err != nil {
-					http.Error(
-						writer,
-						err.Error(),
-						http.StatusInternalServerError,
-					)
+					problem.Internal(writer, req, err)
 
 					return
 				}
 			default:
 				id, perr := uuid.Parse(authorID)
 				if perr != nil {
-					http.Error(
-						writer,
-						perr.Error(),
-						http.StatusInternalServerError,
-					)
+					problem.BadRequest(writer, "author_id must be a UUID")
+
+					return
 				}
 
 				opts := database.GetAllChirpsForUserParams{
@@ -162,36 +267,13 @@ func GetAllChirps(env *appenv.Env) http.Handler {
 
 				chirps, err = env.DB.GetAllChirpsForUser(req.Context(), opts)
 				if err != nil {
-					http.Error(
-						writer,
-						err.Error(),
-						http.StatusInternalServerError,
-					)
+					problem.Internal(writer, req, err)
 
 					return
 				}
 			}
 
-			resData := make([]Chirp, 0, len(chirps))
-
-			for _, chirp := range chirps {
-				resData = append(resData, Chirp{
-					ID:        chirp.ID,
-					CreatedAt: chirp.CreatedAt,
-					UpdatedAt: chirp.UpdatedAt,
-					Body:      chirp.Body,
-					UserID:    chirp.UserID,
-				})
-			}
-
-			res, err := json.Marshal(&resData)
-			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
-
-				return
-			}
-
-			_, _ = writer.Write(res)
+			writeChirps(writer, req, env, chirps)
 		},
 	)
 }
@@ -202,7 +284,7 @@ func GetOneChirpByID(env *appenv.Env) http.Handler {
 		func(writer http.ResponseWriter, req *http.Request) {
 			id, err := uuid.Parse(req.PathValue("chirpID"))
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.BadRequest(writer, "chirpID must be a UUID")
 
 				return
 			}
@@ -210,69 +292,75 @@ func GetOneChirpByID(env *appenv.Env) http.Handler {
 			chirp, err := env.DB.GetChirpByID(req.Context(), id)
 			if err != nil {
 				if errors.Is(err, sql.ErrNoRows) {
-					http.NotFound(writer, req)
+					problem.NotFound(writer)
 
 					return
 				}
 
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.Internal(writer, req, err)
 
 				return
 			}
 
-			resData := Chirp{
-				ID:        chirp.ID,
-				CreatedAt: chirp.CreatedAt,
-				UpdatedAt: chirp.UpdatedAt,
-				Body:      chirp.Body,
-				UserID:    chirp.UserID,
-			}
-
-			res, err := json.Marshal(&resData)
-			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+			if chirp.Status != chirpStatusPublished {
+				problem.NotFound(writer)
 
 				return
 			}
 
-			_, _ = writer.Write(res)
+			writeChirp(writer, req, env, chirp, http.StatusOK)
 		},
 	)
 }
 
 // DELETE /api/chirps/{chirpID}
+//
+// Moves the chirp to the author's trash, from which it can be restored until
+// it is purged.
 func DeleteChirpByID(env *appenv.Env) http.Handler {
 	return http.HandlerFunc(
 		func(writer http.ResponseWriter, req *http.Request) {
-			if env.UserID == uuid.Nil {
-				http.Error(writer, "UNAUTHORIZED", http.StatusUnauthorized)
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
 
 				return
 			}
 
 			id, err := uuid.Parse(req.PathValue("chirpID"))
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.BadRequest(writer, "chirpID must be a UUID")
 
 				return
 			}
 
 			chirp, err := env.DB.GetChirpByID(req.Context(), id)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.NotFound(writer)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
 
 				return
 			}
 
-			if chirp.UserID != env.UserID {
-				http.Error(writer, "FORBIDDEN", http.StatusForbidden)
+			if chirp.UserID != userID {
+				problem.Forbidden(writer)
 
 				return
 			}
 
-			err = env.DB.DeleteChirpByID(req.Context(), id)
+			opts := database.SoftDeleteChirpParams{
+				ID:     id,
+				UserID: userID,
+			}
+
+			_, err = env.DB.SoftDeleteChirp(req.Context(), opts)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				problem.Internal(writer, req, err)
 
 				return
 			}
@@ -281,3 +369,262 @@ func DeleteChirpByID(env *appenv.Env) http.Handler {
 		},
 	)
 }
+
+// toChirps converts database chirps into API chirps with their entities,
+// media, like counts and polls, the latter as seen by the current user.
+func toChirps(
+	ctx context.Context,
+	env *appenv.Env,
+	chirps []database.Chirp,
+) ([]Chirp, error) {
+	ids := make([]uuid.UUID, 0, len(chirps))
+
+	for _, chirp := range chirps {
+		ids = append(ids, chirp.ID)
+	}
+
+	entities, err := loadChirpEntities(ctx, env, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := loadChirpMedia(ctx, env, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	likes, err := loadLikeCounts(ctx, env, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	polls, err := loadChirpPolls(ctx, env, ids, auth.UserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	resData := make([]Chirp, 0, len(chirps))
+
+	for _, chirp := range chirps {
+		chirpEntities := entities[chirp.ID]
+		if chirpEntities == nil {
+			chirpEntities = []Entity{}
+		}
+
+		chirpMedia := attachments[chirp.ID]
+		if chirpMedia == nil {
+			chirpMedia = []Media{}
+		}
+
+		resChirp := toChirp(chirp)
+		resChirp.Entities = chirpEntities
+		resChirp.Media = chirpMedia
+		resChirp.LikeCount = likes[chirp.ID]
+		resChirp.Poll = polls[chirp.ID]
+
+		resData = append(resData, resChirp)
+	}
+
+	return resData, nil
+}
+
+func writeChirps(
+	writer http.ResponseWriter,
+	req *http.Request,
+	env *appenv.Env,
+	chirps []database.Chirp,
+) {
+	resData, err := toChirps(req.Context(), env, chirps)
+	if err != nil {
+		problem.Internal(writer, req, err)
+
+		return
+	}
+
+	res, err := json.Marshal(&resData)
+	if err != nil {
+		problem.Internal(writer, req, err)
+
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+
+	_, _ = writer.Write(res)
+}
+
+func writeChirp(
+	writer http.ResponseWriter,
+	req *http.Request,
+	env *appenv.Env,
+	chirp database.Chirp,
+	status int,
+) {
+	resData, err := toChirps(req.Context(), env, []database.Chirp{chirp})
+	if err != nil {
+		problem.Internal(writer, req, err)
+
+		return
+	}
+
+	res, err := json.Marshal(&resData[0])
+	if err != nil {
+		problem.Internal(writer, req, err)
+
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+
+	_, _ = writer.Write(res)
+}
+
+// toChirp converts a database chirp without loading its entities or media.
+func toChirp(chirp database.Chirp) Chirp {
+	var publishAt *time.Time
+	if chirp.PublishAt.Valid {
+		publishAt = &chirp.PublishAt.Time
+	}
+
+	var deletedAt *time.Time
+	if chirp.DeletedAt.Valid {
+		deletedAt = &chirp.DeletedAt.Time
+	}
+
+	var replyToID *uuid.UUID
+	if chirp.ReplyToID.Valid {
+		replyToID = &chirp.ReplyToID.UUID
+	}
+
+	return Chirp{
+		ID:        chirp.ID,
+		CreatedAt: chirp.CreatedAt,
+		UpdatedAt: chirp.UpdatedAt,
+		Body:      chirp.Body,
+		UserID:    chirp.UserID,
+		Status:    chirp.Status,
+		PublishAt: publishAt,
+		ReplyToID: replyToID,
+		DeletedAt: deletedAt,
+		Entities:  []Entity{},
+		Media:     []Media{},
+	}
+}
+
+// ImportChirp stores a published chirp written outside the API, keeping its
+// original creation time. The body goes through the same checks and entity
+// parsing as POST /api/chirps, but mentioned users are not notified.
+func ImportChirp(
+	ctx context.Context,
+	env *appenv.Env,
+	userID uuid.UUID,
+	body string,
+	createdAt time.Time,
+) (database.Chirp, error) {
+	if utf8.RuneCountInString(body) > maxChirpLength {
+		return database.Chirp{}, errChirpTooLong
+	}
+
+	body = censorChirpBody(body)
+
+	var chirp database.Chirp
+
+	err := env.InTx(ctx, func(qtx *database.Queries) error {
+		var err error
+
+		chirp, err = qtx.ImportChirp(ctx, database.ImportChirpParams{
+			Body:      body,
+			UserID:    userID,
+			CreatedAt: createdAt.UTC(),
+		})
+		if err != nil {
+			return err
+		}
+
+		_, _, err = syncChirpEntities(ctx, qtx, chirp)
+
+		return err
+	})
+
+	return chirp, err
+}
+
+// censorChirpBody masks profanities in a chirp body.
+func censorChirpBody(body string) string {
+	profanities := []string{
+		"kerfuffle",
+		"sharbert",
+		"fornax",
+	}
+
+	for _, p := range profanities {
+		re := regexp.MustCompile("(?i)" + p)
+		body = re.ReplaceAllString(body, "****")
+	}
+
+	return body
+}
+
+// chirpSchedule works out the status of a new or edited chirp: a draft, a
+// chirp scheduled for publishAt, or a chirp published right away when
+// publishAt is missing or already past.
+func chirpSchedule(draft bool, publishAt *time.Time) (string, sql.NullTime) {
+	switch {
+	case draft:
+		return chirpStatusDraft, sql.NullTime{}
+	case publishAt != nil && publishAt.After(time.Now()):
+		return chirpStatusScheduled, sql.NullTime{
+			Time:  publishAt.UTC(),
+			Valid: true,
+		}
+	default:
+		return chirpStatusPublished, sql.NullTime{}
+	}
+}
+
+// notifyMentions tells every user mentioned in a newly published chirp.
+func notifyMentions(
+	ctx context.Context,
+	env *appenv.Env,
+	chirp database.Chirp,
+	mentioned []uuid.UUID,
+) {
+	for _, userID := range mentioned {
+		events.Dispatch(ctx, env, events.Event{
+			Kind:      events.KindMention,
+			Recipient: userID,
+			Actor:     chirp.UserID,
+			Subject:   chirp.ID,
+		})
+	}
+}
+
+// notifyReply tells the author of the chirp a newly published chirp replies
+// to. Replies to the same chirp collapse into one notification.
+func notifyReply(ctx context.Context, env *appenv.Env, chirp database.Chirp) {
+	if !chirp.ReplyToID.Valid {
+		return
+	}
+
+	parent, err := env.DB.GetChirpByID(ctx, chirp.ReplyToID.UUID)
+	if err != nil {
+		// The chirp replied to may have been deleted since.
+		if !errors.Is(err, sql.ErrNoRows) {
+			logging.FromContext(ctx).Error(
+				"api: could not load replied chirp",
+				"chirp_id", chirp.ReplyToID.UUID,
+				"error", err,
+			)
+		}
+
+		return
+	}
+
+	events.Dispatch(ctx, env, events.Event{
+		Kind:      events.KindReply,
+		Recipient: parent.UserID,
+		Actor:     chirp.UserID,
+		Subject:   parent.ID,
+	})
+}
@@ -0,0 +1,254 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/validate"
+)
+
+// GET /api/drafts
+func GetDrafts(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			chirps, err := env.DB.GetUnpublishedChirpsForUser(
+				req.Context(),
+				userID,
+			)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeChirps(writer, req, env, chirps)
+		},
+	)
+}
+
+// PUT /api/drafts/{chirpID}
+//
+// Edits the body of a draft or scheduled chirp and sets when it goes out:
+// a future `publish_at` (re)schedules it, leaving it out turns it back into
+// a draft. A poll attached to the chirp must still be valid for the new
+// publication time.
+func PutDraft(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("chirpID"))
+			if err != nil {
+				problem.BadRequest(writer, "chirpID must be a UUID")
+
+				return
+			}
+
+			type input struct {
+				Body      string     `json:"body"`
+				PublishAt *time.Time `json:"publish_at"`
+			}
+
+			var data input
+
+			if !validate.Decode(writer, req, &data) {
+				return
+			}
+
+			var errs validate.Errors
+
+			errs.MaxLength("body", data.Body, maxChirpLength)
+
+			if data.PublishAt != nil && !data.PublishAt.After(time.Now()) {
+				errs.Add(
+					"publish_at",
+					"must be in the future, publish the draft instead",
+				)
+			}
+
+			if errs.Write(writer) {
+				return
+			}
+
+			body := censorChirpBody(data.Body)
+
+			status, publishAt := chirpSchedule(
+				data.PublishAt == nil,
+				data.PublishAt,
+			)
+
+			var chirp database.Chirp
+
+			err = env.InTx(req.Context(), func(qtx *database.Queries) error {
+				var err error
+
+				chirp, err = qtx.UpdateUnpublishedChirp(
+					req.Context(),
+					database.UpdateUnpublishedChirpParams{
+						Body:      body,
+						Status:    status,
+						PublishAt: publishAt,
+						ID:        id,
+						UserID:    userID,
+					},
+				)
+				if err != nil {
+					return err
+				}
+
+				opensAt := time.Now()
+				if publishAt.Valid {
+					opensAt = publishAt.Time
+				}
+
+				err = revalidatePoll(req.Context(), qtx, chirp.ID, opensAt)
+				if err != nil {
+					return err
+				}
+
+				_, _, err = syncChirpEntities(req.Context(), qtx, chirp)
+
+				return err
+			})
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.NotFound(writer)
+
+					return
+				}
+
+				if errors.Is(err, errInvalidPoll) {
+					errs.Add("poll", err.Error())
+					errs.Write(writer)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeChirp(writer, req, env, chirp, http.StatusOK)
+		},
+	)
+}
+
+// POST /api/drafts/{chirpID}/publish
+func PostPublishDraft(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("chirpID"))
+			if err != nil {
+				problem.BadRequest(writer, "chirpID must be a UUID")
+
+				return
+			}
+
+			opts := database.PublishChirpParams{
+				ID:     id,
+				UserID: userID,
+			}
+
+			chirp, err := env.DB.PublishChirp(req.Context(), opts)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.NotFound(writer)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			mentions, err := env.DB.GetMentionsForChirps(
+				req.Context(),
+				[]uuid.UUID{chirp.ID},
+			)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			mentioned := make([]uuid.UUID, 0, len(mentions))
+			for _, mention := range mentions {
+				mentioned = append(mentioned, mention.UserID)
+			}
+
+			notifyMentions(req.Context(), env, chirp, mentioned)
+			notifyReply(req.Context(), env, chirp)
+
+			writeChirp(writer, req, env, chirp, http.StatusOK)
+		},
+	)
+}
+
+// DELETE /api/drafts/{chirpID}
+func DeleteDraft(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("chirpID"))
+			if err != nil {
+				problem.BadRequest(writer, "chirpID must be a UUID")
+
+				return
+			}
+
+			opts := database.DeleteUnpublishedChirpParams{
+				ID:     id,
+				UserID: userID,
+			}
+
+			deleted, err := env.DB.DeleteUnpublishedChirp(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if deleted == 0 {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
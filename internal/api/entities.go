@@ -0,0 +1,220 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/chirptext"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/problem"
+)
+
+// Entity is a mention or hashtag inside a chirp body. Start and End are byte
+// offsets into the body.
+type Entity struct {
+	Type   chirptext.EntityType `json:"type"`
+	Text   string               `json:"text"`
+	Start  int                  `json:"start"`
+	End    int                  `json:"end"`
+	UserID *uuid.UUID           `json:"user_id,omitempty"`
+}
+
+// GET /api/hashtags/{tag}/chirps
+func GetHashtagChirps(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			opts := database.GetAllChirpsForHashtagParams{
+				Tag:  chirptext.NormalizeHashtag(req.PathValue("tag")),
+				Sort: req.URL.Query().Get("sort"),
+			}
+
+			chirps, err := env.DB.GetAllChirpsForHashtag(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeChirps(writer, req, env, chirps)
+		},
+	)
+}
+
+// GET /api/users/{userID}/mentions
+func GetUserMentions(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			id, err := uuid.Parse(req.PathValue("userID"))
+			if err != nil {
+				problem.BadRequest(writer, "userID must be a UUID")
+
+				return
+			}
+
+			opts := database.GetAllChirpsMentioningUserParams{
+				UserID: id,
+				Sort:   req.URL.Query().Get("sort"),
+			}
+
+			chirps, err := env.DB.GetAllChirpsMentioningUser(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeChirps(writer, req, env, chirps)
+		},
+	)
+}
+
+// syncChirpEntities replaces the stored mentions and hashtags of chirp with
+// the ones currently in its body. Mentions of unknown usernames are dropped.
+// It returns the entities along with the IDs of the mentioned users.
+func syncChirpEntities(
+	ctx context.Context,
+	qtx *database.Queries,
+	chirp database.Chirp,
+) ([]Entity, []uuid.UUID, error) {
+	parsed := chirptext.Parse(chirp.Body)
+
+	var usernames []string
+
+	for _, entity := range parsed {
+		if entity.Type == chirptext.EntityMention {
+			usernames = append(usernames, entity.Value)
+		}
+	}
+
+	userIDs := make(map[string]uuid.UUID, len(usernames))
+
+	if len(usernames) > 0 {
+		users, err := qtx.GetUsersByUsernames(ctx, usernames)
+		if err != nil {
+			return nil, nil, fmt.Errorf("resolve mentions: %w", err)
+		}
+
+		for _, user := range users {
+			userIDs[user.Username.String] = user.ID
+		}
+	}
+
+	if err := qtx.DeleteChirpMentions(ctx, chirp.ID); err != nil {
+		return nil, nil, fmt.Errorf("clear mentions: %w", err)
+	}
+
+	if err := qtx.DeleteChirpHashtags(ctx, chirp.ID); err != nil {
+		return nil, nil, fmt.Errorf("clear hashtags: %w", err)
+	}
+
+	entities := make([]Entity, 0, len(parsed))
+	mentioned := make([]uuid.UUID, 0, len(userIDs))
+
+	for _, entity := range parsed {
+		switch entity.Type {
+		case chirptext.EntityMention:
+			userID, ok := userIDs[entity.Value]
+			if !ok {
+				continue
+			}
+
+			err := qtx.CreateChirpMention(ctx, database.CreateChirpMentionParams{
+				ChirpID:     chirp.ID,
+				UserID:      userID,
+				StartOffset: int32(entity.Start), //nolint:gosec
+				EndOffset:   int32(entity.End),   //nolint:gosec
+			})
+			if err != nil {
+				return nil, nil, fmt.Errorf("store mention: %w", err)
+			}
+
+			if !slices.Contains(mentioned, userID) {
+				mentioned = append(mentioned, userID)
+			}
+
+			entities = append(entities, Entity{
+				Type:   entity.Type,
+				Text:   entity.Value,
+				Start:  entity.Start,
+				End:    entity.End,
+				UserID: &userID,
+			})
+		case chirptext.EntityHashtag:
+			err := qtx.CreateChirpHashtag(ctx, database.CreateChirpHashtagParams{
+				ChirpID:     chirp.ID,
+				Tag:         entity.Value,
+				StartOffset: int32(entity.Start), //nolint:gosec
+				EndOffset:   int32(entity.End),   //nolint:gosec
+			})
+			if err != nil {
+				return nil, nil, fmt.Errorf("store hashtag: %w", err)
+			}
+
+			entities = append(entities, Entity{
+				Type:  entity.Type,
+				Text:  entity.Value,
+				Start: entity.Start,
+				End:   entity.End,
+			})
+		}
+	}
+
+	return entities, mentioned, nil
+}
+
+// loadChirpEntities fetches the stored entities of every given chirp, keyed
+// by chirp ID and ordered by offset.
+func loadChirpEntities(
+	ctx context.Context,
+	env *appenv.Env,
+	chirpIDs []uuid.UUID,
+) (map[uuid.UUID][]Entity, error) {
+	entities := make(map[uuid.UUID][]Entity, len(chirpIDs))
+
+	if len(chirpIDs) == 0 {
+		return entities, nil
+	}
+
+	mentions, err := env.DB.GetMentionsForChirps(ctx, chirpIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load mentions: %w", err)
+	}
+
+	for _, mention := range mentions {
+		userID := mention.UserID
+
+		entities[mention.ChirpID] = append(entities[mention.ChirpID], Entity{
+			Type:   chirptext.EntityMention,
+			Text:   mention.Username.String,
+			Start:  int(mention.StartOffset),
+			End:    int(mention.EndOffset),
+			UserID: &userID,
+		})
+	}
+
+	hashtags, err := env.DB.GetHashtagsForChirps(ctx, chirpIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load hashtags: %w", err)
+	}
+
+	for _, hashtag := range hashtags {
+		entities[hashtag.ChirpID] = append(entities[hashtag.ChirpID], Entity{
+			Type:  chirptext.EntityHashtag,
+			Text:  hashtag.Tag,
+			Start: int(hashtag.StartOffset),
+			End:   int(hashtag.EndOffset),
+		})
+	}
+
+	for _, list := range entities {
+		slices.SortFunc(list, func(a, b Entity) int {
+			return a.Start - b.Start
+		})
+	}
+
+	return entities, nil
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/export"
+	"github.com/zyrterviews/chirpy/internal/logging"
+	"github.com/zyrterviews/chirpy/internal/problem"
+)
+
+type DataExport struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Status      string    `json:"status"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	DownloadURL string    `json:"download_url"`
+}
+
+// POST /api/users/me/export
+//
+// Queues an export of the caller's data. The download link in the response
+// only works once the export is ready, which the user is notified of, and
+// can be used a single time before it expires.
+func PostDataExport(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			token, hash, err := export.NewToken()
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			opts := database.CreateDataExportParams{
+				UserID:    userID,
+				TokenHash: hash,
+				ExpiresAt: time.Now().UTC().Add(export.Lifetime),
+			}
+
+			exp, err := env.DB.CreateDataExport(req.Context(), opts)
+			if err != nil {
+				var pqErr *pq.Error
+				if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+					problem.Write(
+						writer,
+						http.StatusConflict,
+						problem.CodeExportInProgress,
+						"an export is already being prepared",
+					)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			resData := DataExport{
+				ID:          exp.ID,
+				CreatedAt:   exp.CreatedAt,
+				Status:      exp.Status,
+				ExpiresAt:   exp.ExpiresAt,
+				DownloadURL: "/api/exports/" + token,
+			}
+
+			res, err := json.Marshal(&resData)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusAccepted)
+
+			_, _ = writer.Write(res)
+		},
+	)
+}
+
+// GET /api/exports/{token}
+//
+// Downloads a data export. The token is the credential, so the link works
+// without logging in, but only once: a download that fails before the whole
+// archive is sent leaves the link usable.
+func GetDataExportDownload(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			ctx := req.Context()
+			hash := export.HashToken(req.PathValue("token"))
+
+			exp, err := env.DB.GetDataExportByTokenHash(ctx, hash)
+			if errors.Is(err, sql.ErrNoRows) {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if exp.Status == "pending" {
+				problem.Write(
+					writer,
+					http.StatusConflict,
+					problem.CodeExportNotReady,
+					"export is not ready yet",
+				)
+
+				return
+			}
+
+			if exp.Status != "ready" ||
+				exp.DownloadedAt.Valid ||
+				!exp.ExpiresAt.After(time.Now()) {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			// The archive is opened before the link is spent, so a storage
+			// failure does not burn it.
+			archive, err := env.Exports.Get(ctx, exp.BlobKey.String)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+			defer archive.Close()
+
+			// Concurrent requests for the same link race for the claim, and
+			// only one of them sends the archive.
+			_, err = env.DB.ClaimDataExportDownload(ctx, hash)
+			if errors.Is(err, sql.ErrNoRows) {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.Header().Set("Content-Type", "application/zip")
+			writer.Header().Set(
+				"Content-Disposition",
+				fmt.Sprintf(
+					`attachment; filename="chirpy-export-%s.zip"`,
+					exp.CreatedAt.Format("2006-01-02"),
+				),
+			)
+			writer.Header().Set("Cache-Control", "no-store")
+
+			if _, err := io.Copy(writer, archive); err != nil {
+				logging.FromContext(ctx).Warn(
+					"export: download interrupted",
+					"export_id", exp.ID,
+					"error", err,
+				)
+
+				// The client is likely gone, taking the request context
+				// with it.
+				err := env.DB.ReleaseDataExportDownload(
+					context.WithoutCancel(ctx),
+					exp.ID,
+				)
+				if err != nil {
+					logging.FromContext(ctx).Error(
+						"export: could not release download",
+						"export_id", exp.ID,
+						"error", err,
+					)
+				}
+			}
+		},
+	)
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/events"
+	"github.com/zyrterviews/chirpy/internal/problem"
+)
+
+// PUT /api/users/{userID}/follow
+//
+// Following a user twice is a no-op. Users who blocked one another cannot
+// follow each other.
+func PutFollow(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("userID"))
+			if err != nil {
+				problem.BadRequest(writer, "userID must be a UUID")
+
+				return
+			}
+
+			if id == userID {
+				problem.BadRequest(writer, "you cannot follow yourself")
+
+				return
+			}
+
+			followee, err := env.DB.GetUserByID(req.Context(), id)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.NotFound(writer)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			blocked, err := env.DB.IsBlockedBetween(
+				req.Context(),
+				database.IsBlockedBetweenParams{
+					UserID:  userID,
+					OtherID: followee.ID,
+				},
+			)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if blocked {
+				problem.Forbidden(writer)
+
+				return
+			}
+
+			opts := database.CreateFollowParams{
+				FollowerID: userID,
+				FolloweeID: followee.ID,
+			}
+
+			followed, err := env.DB.CreateFollow(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			// Only a new follow is news to the followee.
+			if followed > 0 {
+				events.Dispatch(req.Context(), env, events.Event{
+					Kind:      events.KindFollow,
+					Recipient: followee.ID,
+					Actor:     userID,
+				})
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
+
+// DELETE /api/users/{userID}/follow
+func DeleteFollow(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("userID"))
+			if err != nil {
+				problem.BadRequest(writer, "userID must be a UUID")
+
+				return
+			}
+
+			opts := database.DeleteFollowParams{
+				FollowerID: userID,
+				FolloweeID: id,
+			}
+
+			deleted, err := env.DB.DeleteFollow(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if deleted == 0 {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/events"
+	"github.com/zyrterviews/chirpy/internal/problem"
+)
+
+// PUT /api/chirps/{chirpID}/like
+//
+// Liking a chirp twice is a no-op.
+func PutLike(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("chirpID"))
+			if err != nil {
+				problem.BadRequest(writer, "chirpID must be a UUID")
+
+				return
+			}
+
+			chirp, err := env.DB.GetChirpByID(req.Context(), id)
+			if err == nil && chirp.Status != chirpStatusPublished {
+				err = sql.ErrNoRows
+			}
+
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.NotFound(writer)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			opts := database.CreateLikeParams{
+				UserID:  userID,
+				ChirpID: chirp.ID,
+			}
+
+			liked, err := env.DB.CreateLike(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			// Likes of the same chirp collapse into one notification.
+			if liked > 0 {
+				events.Dispatch(req.Context(), env, events.Event{
+					Kind:      events.KindLike,
+					Recipient: chirp.UserID,
+					Actor:     userID,
+					Subject:   chirp.ID,
+				})
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
+
+// DELETE /api/chirps/{chirpID}/like
+func DeleteLike(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("chirpID"))
+			if err != nil {
+				problem.BadRequest(writer, "chirpID must be a UUID")
+
+				return
+			}
+
+			opts := database.DeleteLikeParams{
+				UserID:  userID,
+				ChirpID: id,
+			}
+
+			deleted, err := env.DB.DeleteLike(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if deleted == 0 {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
+
+// loadLikeCounts returns how many users like each chirp, leaving out deleted
+// accounts.
+func loadLikeCounts(
+	ctx context.Context,
+	env *appenv.Env,
+	chirpIDs []uuid.UUID,
+) (map[uuid.UUID]int64, error) {
+	counts := make(map[uuid.UUID]int64, len(chirpIDs))
+
+	if len(chirpIDs) == 0 {
+		return counts, nil
+	}
+
+	rows, err := env.DB.CountLikesForChirps(ctx, chirpIDs)
+	if err != nil {
+		return nil, fmt.Errorf("count likes: %w", err)
+	}
+
+	for _, row := range rows {
+		counts[row.ChirpID] = row.LikeCount
+	}
+
+	return counts, nil
+}
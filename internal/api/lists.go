@@ -0,0 +1,434 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/pagination"
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/validate"
+)
+
+const maxListNameLength int = 50
+
+var errInvalidListName = fmt.Errorf(
+	"list name must be between 1 and %d characters",
+	maxListNameLength,
+)
+
+// List is a named set of accounts curated by its owner. Private lists are
+// only visible to their owner.
+type List struct {
+	ID        uuid.UUID   `json:"id"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	UserID    uuid.UUID   `json:"user_id"`
+	Name      string      `json:"name"`
+	Public    bool        `json:"public"`
+	MemberIDs []uuid.UUID `json:"member_ids,omitempty"`
+}
+
+type listInput struct {
+	Name   string `json:"name"`
+	Public bool   `json:"public"`
+}
+
+// POST /api/lists
+func PostList(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			var data listInput
+
+			if !validate.Decode(writer, req, &data) {
+				return
+			}
+
+			name, err := cleanListName(data.Name)
+			if err != nil {
+				problem.InvalidField(writer, "name", err.Error())
+
+				return
+			}
+
+			opts := database.CreateListParams{
+				UserID:   userID,
+				Name:     name,
+				IsPublic: data.Public,
+			}
+
+			list, err := env.DB.CreateList(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeList(writer, req, toList(list), http.StatusCreated)
+		},
+	)
+}
+
+// GET /api/lists
+//
+// Returns the caller's own lists, public and private.
+func GetLists(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			lists, err := env.DB.GetListsForUser(req.Context(), userID)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			resData := make([]List, 0, len(lists))
+
+			for _, list := range lists {
+				resData = append(resData, toList(list))
+			}
+
+			res, err := json.Marshal(&resData)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.Header().Set("Content-Type", "application/json")
+
+			_, _ = writer.Write(res)
+		},
+	)
+}
+
+// GET /api/lists/{listID}
+func GetList(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			list, ok := readList(writer, req, env, false)
+			if !ok {
+				return
+			}
+
+			members, err := env.DB.GetListMemberIDs(req.Context(), list.ID)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			resData := toList(list)
+			resData.MemberIDs = members
+
+			writeList(writer, req, resData, http.StatusOK)
+		},
+	)
+}
+
+// PUT /api/lists/{listID}
+func PutList(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			list, ok := readList(writer, req, env, true)
+			if !ok {
+				return
+			}
+
+			var data listInput
+
+			if !validate.Decode(writer, req, &data) {
+				return
+			}
+
+			name, err := cleanListName(data.Name)
+			if err != nil {
+				problem.InvalidField(writer, "name", err.Error())
+
+				return
+			}
+
+			opts := database.UpdateListParams{
+				Name:     name,
+				IsPublic: data.Public,
+				ID:       list.ID,
+				UserID:   auth.UserID(req.Context()),
+			}
+
+			list, err = env.DB.UpdateList(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeList(writer, req, toList(list), http.StatusOK)
+		},
+	)
+}
+
+// DELETE /api/lists/{listID}
+func DeleteList(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			list, ok := readList(writer, req, env, true)
+			if !ok {
+				return
+			}
+
+			opts := database.DeleteListParams{
+				ID:     list.ID,
+				UserID: auth.UserID(req.Context()),
+			}
+
+			if _, err := env.DB.DeleteList(req.Context(), opts); err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
+
+// PUT /api/lists/{listID}/members/{userID}
+//
+// Adding an account that is already a member is a no-op.
+func PutListMember(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			list, ok := readList(writer, req, env, true)
+			if !ok {
+				return
+			}
+
+			userID, err := uuid.Parse(req.PathValue("userID"))
+			if err != nil {
+				problem.BadRequest(writer, "userID must be a UUID")
+
+				return
+			}
+
+			_, err = env.DB.GetUserByID(req.Context(), userID)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.NotFound(writer)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			opts := database.AddListMemberParams{
+				ListID: list.ID,
+				UserID: userID,
+			}
+
+			if err := env.DB.AddListMember(req.Context(), opts); err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
+
+// DELETE /api/lists/{listID}/members/{userID}
+func DeleteListMember(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			list, ok := readList(writer, req, env, true)
+			if !ok {
+				return
+			}
+
+			userID, err := uuid.Parse(req.PathValue("userID"))
+			if err != nil {
+				problem.BadRequest(writer, "userID must be a UUID")
+
+				return
+			}
+
+			opts := database.RemoveListMemberParams{
+				ListID: list.ID,
+				UserID: userID,
+			}
+
+			removed, err := env.DB.RemoveListMember(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if removed == 0 {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
+
+// GET /api/lists/{listID}/chirps
+//
+// Returns the chirps of the list members, newest first unless `sort=asc`.
+func GetListChirps(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			list, ok := readList(writer, req, env, false)
+			if !ok {
+				return
+			}
+
+			limit, offset, err := pagination.Parse(req)
+			if err != nil {
+				problem.BadRequest(writer, err.Error())
+
+				return
+			}
+
+			opts := database.GetAllChirpsForListParams{
+				ListID:    list.ID,
+				Sort:      req.URL.Query().Get("sort"),
+				RowLimit:  limit,
+				RowOffset: offset,
+			}
+
+			chirps, err := env.DB.GetAllChirpsForList(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeChirps(writer, req, env, chirps)
+		},
+	)
+}
+
+// readList looks up the list named in the path and checks the caller may see
+// it, or edit it when mustOwn is set. Lists the caller may not see are
+// reported as missing so private lists do not leak. On failure it writes the
+// response and returns false.
+func readList(
+	writer http.ResponseWriter,
+	req *http.Request,
+	env *appenv.Env,
+	mustOwn bool,
+) (database.List, bool) {
+	userID := auth.UserID(req.Context())
+	if mustOwn && userID == uuid.Nil {
+		problem.Unauthorized(writer)
+
+		return database.List{}, false
+	}
+
+	id, err := uuid.Parse(req.PathValue("listID"))
+	if err != nil {
+		problem.BadRequest(writer, "listID must be a UUID")
+
+		return database.List{}, false
+	}
+
+	list, err := env.DB.GetListByID(req.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			problem.NotFound(writer)
+
+			return database.List{}, false
+		}
+
+		problem.Internal(writer, req, err)
+
+		return database.List{}, false
+	}
+
+	owned := list.UserID == userID
+
+	if !owned && !list.IsPublic {
+		problem.NotFound(writer)
+
+		return database.List{}, false
+	}
+
+	if mustOwn && !owned {
+		problem.Forbidden(writer)
+
+		return database.List{}, false
+	}
+
+	return list, true
+}
+
+func writeList(
+	writer http.ResponseWriter,
+	req *http.Request,
+	list List,
+	status int,
+) {
+	res, err := json.Marshal(&list)
+	if err != nil {
+		problem.Internal(writer, req, err)
+
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+
+	_, _ = writer.Write(res)
+}
+
+func toList(list database.List) List {
+	return List{
+		ID:        list.ID,
+		CreatedAt: list.CreatedAt,
+		UpdatedAt: list.UpdatedAt,
+		UserID:    list.UserID,
+		Name:      list.Name,
+		Public:    list.IsPublic,
+	}
+}
+
+// cleanListName trims a list name and checks its length.
+func cleanListName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+
+	if name == "" || utf8.RuneCountInString(name) > maxListNameLength {
+		return "", errInvalidListName
+	}
+
+	return name, nil
+}
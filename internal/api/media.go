@@ -0,0 +1,342 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/blob"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/logging"
+	"github.com/zyrterviews/chirpy/internal/media"
+	"github.com/zyrterviews/chirpy/internal/problem"
+)
+
+// multipartOverhead leaves room for the multipart boundaries and headers on
+// top of the image itself.
+const multipartOverhead int64 = 1 << 20
+
+var errUnknownMedia = fmt.Errorf(
+	"media_ids must be at most %d distinct media uploaded by you",
+	media.MaxAttachments,
+)
+
+type Media struct {
+	ID           uuid.UUID `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	ContentType  string    `json:"content_type"`
+	Width        int32     `json:"width"`
+	Height       int32     `json:"height"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+}
+
+// POST /api/media
+func PostMedia(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			req.Body = http.MaxBytesReader(
+				writer,
+				req.Body,
+				media.MaxUploadSize+multipartOverhead,
+			)
+
+			file, _, err := req.FormFile("file")
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					problem.Write(
+						writer,
+						http.StatusRequestEntityTooLarge,
+						problem.CodePayloadTooLarge,
+						media.ErrTooLarge.Error(),
+					)
+
+					return
+				}
+
+				problem.BadRequest(
+					writer,
+					"expected a multipart form with a `file` field",
+				)
+
+				return
+			}
+			defer file.Close()
+
+			data, err := io.ReadAll(io.LimitReader(file, media.MaxUploadSize+1))
+			if err != nil {
+				problem.BadRequest(writer, "the uploaded file could not be read")
+
+				return
+			}
+
+			img, err := media.Process(data)
+			if err != nil {
+				switch {
+				case errors.Is(err, media.ErrTooLarge):
+					problem.Write(
+						writer,
+						http.StatusRequestEntityTooLarge,
+						problem.CodePayloadTooLarge,
+						err.Error(),
+					)
+				case errors.Is(err, media.ErrUnsupportedType):
+					problem.Write(
+						writer,
+						http.StatusUnsupportedMediaType,
+						problem.CodeUnsupportedMediaType,
+						err.Error(),
+					)
+				default:
+					problem.BadRequest(writer, err.Error())
+				}
+
+				return
+			}
+
+			id := uuid.New()
+			ext := map[string]string{"image/jpeg": "jpg", "image/png": "png"}[img.ContentType]
+			blobKey := fmt.Sprintf("%s/original.%s", id, ext)
+			thumbnailKey := fmt.Sprintf("%s/thumbnail.%s", id, ext)
+
+			if err := env.Blobs.Put(req.Context(), blobKey, img.ContentType, img.Data); err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if err := env.Blobs.Put(req.Context(), thumbnailKey, img.ContentType, img.Thumbnail); err != nil {
+				deleteBlobs(req.Context(), env, blobKey)
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			opts := database.CreateMediaParams{
+				ID:           id,
+				UserID:       userID,
+				ContentType:  img.ContentType,
+				SizeBytes:    int64(len(img.Data)),
+				Width:        int32(img.Width),  //nolint:gosec
+				Height:       int32(img.Height), //nolint:gosec
+				BlobKey:      blobKey,
+				ThumbnailKey: thumbnailKey,
+			}
+
+			medium, err := env.DB.CreateMedia(req.Context(), opts)
+			if err != nil {
+				deleteBlobs(req.Context(), env, blobKey, thumbnailKey)
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			res, err := json.Marshal(toMedia(env, medium))
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusCreated)
+
+			_, _ = writer.Write(res)
+		},
+	)
+}
+
+// GET /app/media/{key...}
+//
+// Serves a media file from the local blob store. Media of deleted chirps
+// are not served, even though their files are kept until the chirps are
+// purged.
+func GetMediaFile(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			key := req.PathValue("key")
+
+			// Keys are "<media ID>/<file>".
+			prefix, _, _ := strings.Cut(key, "/")
+
+			id, err := uuid.Parse(prefix)
+			if err != nil {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			medium, err := env.DB.GetServableMediaByID(req.Context(), id)
+			if errors.Is(err, sql.ErrNoRows) {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if key != medium.BlobKey && key != medium.ThumbnailKey {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			file, err := env.Blobs.Get(req.Context(), key)
+			if errors.Is(err, blob.ErrNotFound) {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+			defer file.Close()
+
+			writer.Header().Set("Content-Type", medium.ContentType)
+
+			_, _ = io.Copy(writer, file)
+		},
+	)
+}
+
+// attachChirpMedia attaches mediaIDs, in order, to a chirp. Every ID must
+// belong to a media uploaded by userID, otherwise errUnknownMedia is
+// returned.
+func attachChirpMedia(
+	ctx context.Context,
+	qtx *database.Queries,
+	userID, chirpID uuid.UUID,
+	mediaIDs []uuid.UUID,
+) ([]database.Medium, error) {
+	if len(mediaIDs) == 0 {
+		return nil, nil
+	}
+
+	if len(mediaIDs) > media.MaxAttachments {
+		return nil, errUnknownMedia
+	}
+
+	owned, err := qtx.GetMediaByIDsForUser(ctx, database.GetMediaByIDsForUserParams{
+		Ids:    mediaIDs,
+		UserID: userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load media: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]database.Medium, len(owned))
+	for _, medium := range owned {
+		byID[medium.ID] = medium
+	}
+
+	attached := make([]database.Medium, 0, len(mediaIDs))
+
+	for position, id := range mediaIDs {
+		medium, ok := byID[id]
+		if !ok {
+			return nil, errUnknownMedia
+		}
+
+		// Forget it so that a repeated ID is reported as unknown.
+		delete(byID, id)
+
+		err := qtx.AttachMediaToChirp(ctx, database.AttachMediaToChirpParams{
+			ChirpID:  chirpID,
+			MediaID:  id,
+			Position: int32(position), //nolint:gosec
+		})
+		if err != nil {
+			return nil, fmt.Errorf("attach media: %w", err)
+		}
+
+		attached = append(attached, medium)
+	}
+
+	return attached, nil
+}
+
+// loadChirpMedia fetches the media attached to every given chirp, keyed by
+// chirp ID and in attachment order.
+func loadChirpMedia(
+	ctx context.Context,
+	env *appenv.Env,
+	chirpIDs []uuid.UUID,
+) (map[uuid.UUID][]Media, error) {
+	attachments := make(map[uuid.UUID][]Media, len(chirpIDs))
+
+	if len(chirpIDs) == 0 {
+		return attachments, nil
+	}
+
+	rows, err := env.DB.GetMediaForChirps(ctx, chirpIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load media: %w", err)
+	}
+
+	for _, row := range rows {
+		attachments[row.ChirpID] = append(attachments[row.ChirpID], toMedia(
+			env,
+			database.Medium{
+				ID:           row.ID,
+				CreatedAt:    row.CreatedAt,
+				UserID:       row.UserID,
+				ContentType:  row.ContentType,
+				SizeBytes:    row.SizeBytes,
+				Width:        row.Width,
+				Height:       row.Height,
+				BlobKey:      row.BlobKey,
+				ThumbnailKey: row.ThumbnailKey,
+			},
+		))
+	}
+
+	return attachments, nil
+}
+
+func toMedia(env *appenv.Env, medium database.Medium) Media {
+	return Media{
+		ID:           medium.ID,
+		CreatedAt:    medium.CreatedAt,
+		ContentType:  medium.ContentType,
+		Width:        medium.Width,
+		Height:       medium.Height,
+		URL:          env.Blobs.URL(medium.BlobKey),
+		ThumbnailURL: env.Blobs.URL(medium.ThumbnailKey),
+	}
+}
+
+func deleteBlobs(ctx context.Context, env *appenv.Env, keys ...string) {
+	for _, key := range keys {
+		if err := env.Blobs.Delete(ctx, key); err != nil {
+			logging.FromContext(ctx).Warn(
+				"media: could not clean up blob",
+				"key", key,
+				"error", err,
+			)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/pagination"
+	"github.com/zyrterviews/chirpy/internal/problem"
+)
+
+// GET /api/moderation/chirps/{chirpID}
+//
+// Returns any chirp, including deleted and unpublished ones, for moderators
+// investigating a report.
+func GetModerationChirp(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			id, err := uuid.Parse(req.PathValue("chirpID"))
+			if err != nil {
+				problem.BadRequest(writer, "chirpID must be a UUID")
+
+				return
+			}
+
+			chirp, err := env.DB.GetChirpByIDIncludingDeleted(req.Context(), id)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.NotFound(writer)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeChirp(writer, req, env, chirp, http.StatusOK)
+		},
+	)
+}
+
+// GET /api/moderation/users/{userID}/chirps
+//
+// Returns every chirp of a user, including deleted and unpublished ones,
+// newest first. Deleted accounts are included too.
+func GetModerationUserChirps(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			id, err := uuid.Parse(req.PathValue("userID"))
+			if err != nil {
+				problem.BadRequest(writer, "userID must be a UUID")
+
+				return
+			}
+
+			limit, offset, err := pagination.Parse(req)
+			if err != nil {
+				problem.BadRequest(writer, err.Error())
+
+				return
+			}
+
+			opts := database.GetAllChirpsForUserIncludingDeletedParams{
+				UserID:    id,
+				RowLimit:  limit,
+				RowOffset: offset,
+			}
+
+			chirps, err := env.DB.GetAllChirpsForUserIncludingDeleted(
+				req.Context(),
+				opts,
+			)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeChirps(writer, req, env, chirps)
+		},
+	)
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/events"
+	"github.com/zyrterviews/chirpy/internal/pagination"
+	"github.com/zyrterviews/chirpy/internal/problem"
+)
+
+const maxNotificationActors int = 3
+
+type Notification struct {
+	ID         uuid.UUID   `json:"id"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+	Kind       string      `json:"kind"`
+	SubjectID  *uuid.UUID  `json:"subject_id"`
+	ActorIDs   []uuid.UUID `json:"actor_ids"`
+	ActorCount int64       `json:"actor_count"`
+	Summary    string      `json:"summary"`
+	Read       bool        `json:"read"`
+}
+
+// GET /api/notifications
+func GetNotifications(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			limit, offset, err := pagination.Parse(req)
+			if err != nil {
+				problem.BadRequest(writer, err.Error())
+
+				return
+			}
+
+			opts := database.ListNotificationsForUserParams{
+				UserID:     userID,
+				UnreadOnly: req.URL.Query().Get("unread") == "true",
+				RowLimit:   limit,
+				RowOffset:  offset,
+			}
+
+			notifications, err := env.DB.ListNotificationsForUser(
+				req.Context(),
+				opts,
+			)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			resData := make([]Notification, 0, len(notifications))
+
+			for _, n := range notifications {
+				resData = append(resData, toNotification(n))
+			}
+
+			res, err := json.Marshal(&resData)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.Header().Set("Content-Type", "application/json")
+
+			_, _ = writer.Write(res)
+		},
+	)
+}
+
+// GET /api/notifications/unread_count
+func GetUnreadNotificationCount(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			count, err := env.DB.GetUnreadNotificationCount(
+				req.Context(),
+				userID,
+			)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			resData := struct {
+				Count int64 `json:"count"`
+			}{Count: count}
+
+			res, err := json.Marshal(resData)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.Header().Set("Content-Type", "application/json")
+
+			_, _ = writer.Write(res)
+		},
+	)
+}
+
+// POST /api/notifications/{notificationID}/read
+func PostNotificationRead(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("notificationID"))
+			if err != nil {
+				problem.BadRequest(writer, "notificationID must be a UUID")
+
+				return
+			}
+
+			opts := database.MarkNotificationReadParams{
+				ID:     id,
+				UserID: userID,
+			}
+
+			updated, err := env.DB.MarkNotificationRead(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if updated == 0 {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
+
+// POST /api/notifications/read
+func PostAllNotificationsRead(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			err := env.DB.MarkAllNotificationsRead(req.Context(), userID)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
+
+func toNotification(n database.ListNotificationsForUserRow) Notification {
+	actorIDs := n.ActorIds
+	if actorIDs == nil {
+		actorIDs = []uuid.UUID{}
+	}
+
+	if len(actorIDs) > maxNotificationActors {
+		actorIDs = actorIDs[:maxNotificationActors]
+	}
+
+	lead := "Someone"
+	if len(actorIDs) > 0 {
+		lead = actorIDs[0].String()
+	}
+
+	var subjectID *uuid.UUID
+	if n.SubjectID.Valid {
+		subjectID = &n.SubjectID.UUID
+	}
+
+	return Notification{
+		ID:         n.ID,
+		CreatedAt:  n.CreatedAt,
+		UpdatedAt:  n.UpdatedAt,
+		Kind:       n.Kind,
+		SubjectID:  subjectID,
+		ActorIDs:   actorIDs,
+		ActorCount: n.ActorCount,
+		Summary:    events.Summary(events.Kind(n.Kind), lead, n.ActorCount),
+		Read:       n.ReadAt.Valid,
+	}
+}
@@ -0,0 +1,379 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/validate"
+)
+
+const (
+	minPollOptions     int = 2
+	maxPollOptions     int = 4
+	maxPollLabelLength int = 25
+	maxPollDuration        = 7 * 24 * time.Hour
+)
+
+var errInvalidPoll = fmt.Errorf(
+	"a poll needs %d to %d distinct options of at most %d characters and must close within %s of being published",
+	minPollOptions,
+	maxPollOptions,
+	maxPollLabelLength,
+	maxPollDuration,
+)
+
+type PollInput struct {
+	Options  []string  `json:"options"`
+	ClosesAt time.Time `json:"closes_at"`
+}
+
+// Poll is a poll as seen by the viewer. Vote counts are only filled in once
+// the viewer has voted or the poll has closed.
+type Poll struct {
+	ID             uuid.UUID    `json:"id"`
+	ClosesAt       time.Time    `json:"closes_at"`
+	Closed         bool         `json:"closed"`
+	Options        []PollOption `json:"options"`
+	TotalVotes     *int64       `json:"total_votes,omitempty"`
+	ViewerOptionID *uuid.UUID   `json:"viewer_option_id,omitempty"`
+}
+
+type PollOption struct {
+	ID    uuid.UUID `json:"id"`
+	Label string    `json:"label"`
+	Votes *int64    `json:"votes,omitempty"`
+}
+
+// POST /api/chirps/{chirpID}/poll/votes
+//
+// Casts the caller's vote, or changes it while the poll is still open.
+func PostPollVote(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+			if err != nil {
+				problem.BadRequest(writer, "chirpID must be a UUID")
+
+				return
+			}
+
+			type input struct {
+				OptionID uuid.UUID `json:"option_id"`
+			}
+
+			var data input
+
+			if !validate.Decode(writer, req, &data) {
+				return
+			}
+
+			chirp, err := env.DB.GetChirpByID(req.Context(), chirpID)
+			if err == nil && chirp.Status != chirpStatusPublished {
+				err = sql.ErrNoRows
+			}
+
+			var poll database.Poll
+
+			if err == nil {
+				poll, err = env.DB.GetPollByChirpID(req.Context(), chirpID)
+			}
+
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.NotFound(writer)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if !poll.ClosesAt.After(time.Now()) {
+				problem.Write(
+					writer,
+					http.StatusConflict,
+					problem.CodePollClosed,
+					"poll is closed",
+				)
+
+				return
+			}
+
+			opts := database.CastPollVoteParams{
+				UserID:   userID,
+				PollID:   poll.ID,
+				OptionID: data.OptionID,
+			}
+
+			voted, err := env.DB.CastPollVote(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			// Nothing was written: either the option is not part of this
+			// poll, or the poll closed since we looked it up.
+			if voted == 0 {
+				if !poll.ClosesAt.After(time.Now()) {
+					problem.Write(
+						writer,
+						http.StatusConflict,
+						problem.CodePollClosed,
+						"poll is closed",
+					)
+
+					return
+				}
+
+				problem.InvalidField(
+					writer,
+					"option_id",
+					"is not an option of this poll",
+				)
+
+				return
+			}
+
+			polls, err := loadChirpPolls(
+				req.Context(),
+				env,
+				[]uuid.UUID{chirpID},
+				userID,
+			)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			res, err := json.Marshal(polls[chirpID])
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.Header().Set("Content-Type", "application/json")
+
+			_, _ = writer.Write(res)
+		},
+	)
+}
+
+// validatePoll checks a poll submitted along with a chirp that goes out at
+// publishAt, and returns its trimmed option labels.
+func validatePoll(poll *PollInput, publishAt time.Time) ([]string, error) {
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return nil, errInvalidPoll
+	}
+
+	if !poll.ClosesAt.After(publishAt) ||
+		poll.ClosesAt.Sub(publishAt) > maxPollDuration {
+		return nil, errInvalidPoll
+	}
+
+	labels := make([]string, 0, len(poll.Options))
+	seen := make(map[string]bool, len(poll.Options))
+
+	for _, option := range poll.Options {
+		label := strings.TrimSpace(option)
+		key := strings.ToLower(label)
+
+		if label == "" ||
+			utf8.RuneCountInString(label) > maxPollLabelLength ||
+			seen[key] {
+			return nil, errInvalidPoll
+		}
+
+		seen[key] = true
+		labels = append(labels, label)
+	}
+
+	return labels, nil
+}
+
+// revalidatePoll runs validatePoll again on the poll of a chirp, if it has
+// one, for when the chirp is rescheduled to go out at opensAt.
+func revalidatePoll(
+	ctx context.Context,
+	qtx *database.Queries,
+	chirpID uuid.UUID,
+	opensAt time.Time,
+) error {
+	poll, err := qtx.GetPollByChirpID(ctx, chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("load poll: %w", err)
+	}
+
+	options, err := qtx.GetPollOptionsWithVotes(ctx, []uuid.UUID{poll.ID})
+	if err != nil {
+		return fmt.Errorf("load poll options: %w", err)
+	}
+
+	input := PollInput{
+		Options:  make([]string, 0, len(options)),
+		ClosesAt: poll.ClosesAt,
+	}
+
+	for _, option := range options {
+		input.Options = append(input.Options, option.Label)
+	}
+
+	_, err = validatePoll(&input, opensAt)
+
+	return err
+}
+
+// createChirpPoll stores a validated poll for a chirp.
+func createChirpPoll(
+	ctx context.Context,
+	qtx *database.Queries,
+	chirpID uuid.UUID,
+	labels []string,
+	closesAt time.Time,
+) error {
+	poll, err := qtx.CreatePoll(ctx, database.CreatePollParams{
+		ChirpID:  chirpID,
+		ClosesAt: closesAt.UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("create poll: %w", err)
+	}
+
+	for position, label := range labels {
+		_, err := qtx.CreatePollOption(ctx, database.CreatePollOptionParams{
+			PollID:   poll.ID,
+			Position: int32(position), //nolint:gosec
+			Label:    label,
+		})
+		if err != nil {
+			return fmt.Errorf("create poll option: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// loadChirpPolls fetches the polls of every given chirp as seen by viewer,
+// which may be uuid.Nil for anonymous requests.
+func loadChirpPolls(
+	ctx context.Context,
+	env *appenv.Env,
+	chirpIDs []uuid.UUID,
+	viewer uuid.UUID,
+) (map[uuid.UUID]*Poll, error) {
+	polls := make(map[uuid.UUID]*Poll, len(chirpIDs))
+
+	if len(chirpIDs) == 0 {
+		return polls, nil
+	}
+
+	rows, err := env.DB.GetPollsForChirps(ctx, chirpIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load polls: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return polls, nil
+	}
+
+	pollIDs := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		pollIDs = append(pollIDs, row.ID)
+	}
+
+	options, err := env.DB.GetPollOptionsWithVotes(ctx, pollIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load poll options: %w", err)
+	}
+
+	viewerVotes := make(map[uuid.UUID]uuid.UUID)
+
+	if viewer != uuid.Nil {
+		votes, err := env.DB.GetPollVotesForUser(
+			ctx,
+			database.GetPollVotesForUserParams{
+				UserID:  viewer,
+				PollIds: pollIDs,
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("load poll votes: %w", err)
+		}
+
+		for _, vote := range votes {
+			viewerVotes[vote.PollID] = vote.OptionID
+		}
+	}
+
+	optionsByPoll := make(map[uuid.UUID][]database.GetPollOptionsWithVotesRow)
+	for _, option := range options {
+		optionsByPoll[option.PollID] = append(optionsByPoll[option.PollID], option)
+	}
+
+	now := time.Now()
+
+	for _, row := range rows {
+		poll := &Poll{
+			ID:       row.ID,
+			ClosesAt: row.ClosesAt,
+			Closed:   !row.ClosesAt.After(now),
+			Options:  make([]PollOption, 0, len(optionsByPoll[row.ID])),
+		}
+
+		optionID, voted := viewerVotes[row.ID]
+		if voted {
+			poll.ViewerOptionID = &optionID
+		}
+
+		showResults := voted || poll.Closed
+
+		var total int64
+
+		for _, option := range optionsByPoll[row.ID] {
+			resOption := PollOption{ID: option.ID, Label: option.Label}
+
+			if showResults {
+				votes := option.Votes
+				resOption.Votes = &votes
+				total += votes
+			}
+
+			poll.Options = append(poll.Options, resOption)
+		}
+
+		if showResults {
+			poll.TotalVotes = &total
+		}
+
+		polls[row.ChirpID] = poll
+	}
+
+	return polls, nil
+}
@@ -0,0 +1,365 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/chirptext"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/validate"
+)
+
+const (
+	maxDisplayNameLength int = 50
+	maxBioLength         int = 160
+)
+
+// Profile is the public view of a user. It never carries the email address
+// or the password hash.
+type Profile struct {
+	ID             uuid.UUID `json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	Username       string    `json:"username,omitempty"`
+	DisplayName    string    `json:"display_name"`
+	Bio            string    `json:"bio"`
+	Avatar         *Media    `json:"avatar"`
+	PinnedChirp    *Chirp    `json:"pinned_chirp"`
+	FollowerCount  int64     `json:"follower_count"`
+	FollowingCount int64     `json:"following_count"`
+	ChirpCount     int64     `json:"chirp_count"`
+	IsChirpyRed    bool      `json:"is_chirpy_red"`
+}
+
+// GET /api/users/{idOrUsername}
+func GetProfile(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			ident := req.PathValue("idOrUsername")
+
+			var (
+				user database.User
+				err  error
+			)
+
+			if id, perr := uuid.Parse(ident); perr == nil {
+				user, err = env.DB.GetUserByID(req.Context(), id)
+			} else {
+				user, err = env.DB.GetUserByUsername(
+					req.Context(),
+					sql.NullString{
+						String: chirptext.NormalizeUsername(ident),
+						Valid:  true,
+					},
+				)
+			}
+
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.NotFound(writer)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeProfile(writer, req, env, user)
+		},
+	)
+}
+
+// PUT /api/profile
+func PutProfile(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			type input struct {
+				DisplayName   string     `json:"display_name"`
+				Bio           string     `json:"bio"`
+				AvatarMediaID *uuid.UUID `json:"avatar_media_id"`
+			}
+
+			var data input
+
+			if !validate.Decode(writer, req, &data) {
+				return
+			}
+
+			displayName := strings.TrimSpace(data.DisplayName)
+			bio := strings.TrimSpace(data.Bio)
+
+			var errs validate.Errors
+
+			errs.MaxLength("display_name", displayName, maxDisplayNameLength)
+			errs.MaxLength("bio", bio, maxBioLength)
+
+			if errs.Write(writer) {
+				return
+			}
+
+			var avatar uuid.NullUUID
+
+			if data.AvatarMediaID != nil {
+				owned, err := env.DB.GetMediaByIDsForUser(
+					req.Context(),
+					database.GetMediaByIDsForUserParams{
+						Ids:    []uuid.UUID{*data.AvatarMediaID},
+						UserID: userID,
+					},
+				)
+				if err != nil {
+					problem.Internal(writer, req, err)
+
+					return
+				}
+
+				if len(owned) == 0 {
+					problem.InvalidField(
+						writer,
+						"avatar_media_id",
+						"must be media uploaded by you",
+					)
+
+					return
+				}
+
+				avatar = uuid.NullUUID{UUID: *data.AvatarMediaID, Valid: true}
+			}
+
+			opts := database.UpdateUserProfileParams{
+				DisplayName:   displayName,
+				Bio:           bio,
+				AvatarMediaID: avatar,
+				ID:            userID,
+			}
+
+			user, err := env.DB.UpdateUserProfile(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeProfile(writer, req, env, user)
+		},
+	)
+}
+
+// PUT /api/chirps/{chirpID}/pin
+//
+// Pins one of the caller's published chirps to their profile, replacing any
+// previously pinned chirp.
+func PutPinnedChirp(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("chirpID"))
+			if err != nil {
+				problem.BadRequest(writer, "chirpID must be a UUID")
+
+				return
+			}
+
+			chirp, err := env.DB.GetChirpByID(req.Context(), id)
+			if err == nil && chirp.Status != chirpStatusPublished {
+				err = sql.ErrNoRows
+			}
+
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.NotFound(writer)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if chirp.UserID != userID {
+				problem.Forbidden(writer)
+
+				return
+			}
+
+			opts := database.SetPinnedChirpParams{
+				PinnedChirpID: uuid.NullUUID{UUID: chirp.ID, Valid: true},
+				ID:            userID,
+			}
+
+			user, err := env.DB.SetPinnedChirp(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeProfile(writer, req, env, user)
+		},
+	)
+}
+
+// DELETE /api/chirps/{chirpID}/pin
+func DeletePinnedChirp(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("chirpID"))
+			if err != nil {
+				problem.BadRequest(writer, "chirpID must be a UUID")
+
+				return
+			}
+
+			user, err := env.DB.GetUserByID(req.Context(), userID)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if !user.PinnedChirpID.Valid || user.PinnedChirpID.UUID != id {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			opts := database.SetPinnedChirpParams{
+				PinnedChirpID: uuid.NullUUID{},
+				ID:            userID,
+			}
+
+			if _, err := env.DB.SetPinnedChirp(req.Context(), opts); err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
+
+func writeProfile(
+	writer http.ResponseWriter,
+	req *http.Request,
+	env *appenv.Env,
+	user database.User,
+) {
+	resData, err := toProfile(req.Context(), env, user)
+	if err != nil {
+		problem.Internal(writer, req, err)
+
+		return
+	}
+
+	res, err := json.Marshal(&resData)
+	if err != nil {
+		problem.Internal(writer, req, err)
+
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+
+	_, _ = writer.Write(res)
+}
+
+// toProfile builds the public profile of user along with its avatar, pinned
+// chirp, follow counts and chirp count.
+func toProfile(
+	ctx context.Context,
+	env *appenv.Env,
+	user database.User,
+) (Profile, error) {
+	profile := Profile{
+		ID:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		Username:    user.Username.String,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		IsChirpyRed: user.IsChirpyRed,
+	}
+
+	count, err := env.DB.CountPublishedChirpsForUser(ctx, user.ID)
+	if err != nil {
+		return Profile{}, fmt.Errorf("count chirps: %w", err)
+	}
+
+	profile.ChirpCount = count
+
+	follows, err := env.DB.GetFollowCounts(ctx, user.ID)
+	if err != nil {
+		return Profile{}, fmt.Errorf("count follows: %w", err)
+	}
+
+	profile.FollowerCount = follows.FollowerCount
+	profile.FollowingCount = follows.FollowingCount
+
+	if user.AvatarMediaID.Valid {
+		avatar, err := env.DB.GetMediaByIDsForUser(
+			ctx,
+			database.GetMediaByIDsForUserParams{
+				Ids:    []uuid.UUID{user.AvatarMediaID.UUID},
+				UserID: user.ID,
+			},
+		)
+		if err != nil {
+			return Profile{}, fmt.Errorf("load avatar: %w", err)
+		}
+
+		if len(avatar) > 0 {
+			resAvatar := toMedia(env, avatar[0])
+			profile.Avatar = &resAvatar
+		}
+	}
+
+	if user.PinnedChirpID.Valid {
+		chirp, err := env.DB.GetChirpByID(ctx, user.PinnedChirpID.UUID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return Profile{}, fmt.Errorf("load pinned chirp: %w", err)
+		}
+
+		if err == nil && chirp.Status == chirpStatusPublished {
+			pinned, err := toChirps(ctx, env, []database.Chirp{chirp})
+			if err != nil {
+				return Profile{}, err
+			}
+
+			profile.PinnedChirp = &pinned[0]
+		}
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,293 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/pagination"
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/purge"
+	"github.com/zyrterviews/chirpy/internal/validate"
+)
+
+// GET /api/trash
+//
+// Returns the caller's deleted chirps that can still be restored, most
+// recently deleted first.
+func GetTrash(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			limit, offset, err := pagination.Parse(req)
+			if err != nil {
+				problem.BadRequest(writer, err.Error())
+
+				return
+			}
+
+			opts := database.GetDeletedChirpsForUserParams{
+				UserID:       userID,
+				DeletedSince: time.Now().Add(-purge.Retention),
+				RowLimit:     limit,
+				RowOffset:    offset,
+			}
+
+			chirps, err := env.DB.GetDeletedChirpsForUser(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeChirps(writer, req, env, chirps)
+		},
+	)
+}
+
+// POST /api/trash/{chirpID}/restore
+func PostRestoreChirp(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("chirpID"))
+			if err != nil {
+				problem.BadRequest(writer, "chirpID must be a UUID")
+
+				return
+			}
+
+			opts := database.RestoreChirpParams{
+				ID:           id,
+				UserID:       userID,
+				DeletedSince: time.Now().Add(-purge.Retention),
+			}
+
+			chirp, err := env.DB.RestoreChirp(req.Context(), opts)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.NotFound(writer)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeChirp(writer, req, env, chirp, http.StatusOK)
+		},
+	)
+}
+
+// DELETE /api/users/me
+//
+// Deletes the caller's account along with their chirps, and signs them out
+// everywhere. The password must be given again. The account can be restored
+// with POST /api/users/restore during the cooling-off period, after which it
+// is purged for good.
+func DeleteUser(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			type input struct {
+				Password string `json:"password"`
+			}
+
+			var data input
+
+			if !validate.Decode(writer, req, &data) {
+				return
+			}
+
+			user, err := env.DB.GetUserByID(req.Context(), userID)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.NotFound(writer)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			err = auth.CheckPasswordHash(data.Password, user.HashedPassword)
+			if err != nil {
+				problem.Write(
+					writer,
+					http.StatusUnauthorized,
+					problem.CodeInvalidCredentials,
+					"password does not match",
+				)
+
+				return
+			}
+
+			err = env.InTx(req.Context(), func(qtx *database.Queries) error {
+				var err error
+
+				user, err = qtx.SoftDeleteUser(req.Context(), user.ID)
+				if err != nil {
+					return err
+				}
+
+				// Chirps share the account's deleted_at so that restoring
+				// the account brings back exactly these, and not the ones
+				// that were already in the trash.
+				err = qtx.SoftDeleteChirpsForUser(
+					req.Context(),
+					database.SoftDeleteChirpsForUserParams{
+						DeletedAt: user.DeletedAt,
+						UserID:    user.ID,
+					},
+				)
+				if err != nil {
+					return err
+				}
+
+				return qtx.RevokeRefreshTokensForUser(req.Context(), user.ID)
+			})
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.NotFound(writer)
+
+					return
+				}
+
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			resData := struct {
+				DeletedAt    time.Time `json:"deleted_at"`
+				RestoreUntil time.Time `json:"restore_until"`
+			}{
+				DeletedAt:    user.DeletedAt.Time,
+				RestoreUntil: user.DeletedAt.Time.Add(purge.Retention),
+			}
+
+			res, err := json.Marshal(resData)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.Header().Set("Content-Type", "application/json")
+
+			_, _ = writer.Write(res)
+		},
+	)
+}
+
+// POST /api/users/restore
+//
+// Restores a deleted account and its chirps. Deleted accounts cannot log in,
+// so the request carries the account credentials instead of a token.
+func PostRestoreUser(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			type input struct {
+				Email    string `json:"email"`
+				Password string `json:"password"`
+			}
+
+			var data input
+
+			if !validate.Decode(writer, req, &data) {
+				return
+			}
+
+			opts := database.GetDeletedUserByEmailParams{
+				Email:        data.Email,
+				DeletedSince: time.Now().Add(-purge.Retention),
+			}
+
+			user, err := env.DB.GetDeletedUserByEmail(req.Context(), opts)
+			if err == nil {
+				err = auth.CheckPasswordHash(data.Password, user.HashedPassword)
+			}
+
+			if err != nil {
+				problem.Write(
+					writer,
+					http.StatusUnauthorized,
+					problem.CodeInvalidCredentials,
+					"email or password does not match a deleted account",
+				)
+
+				return
+			}
+
+			err = env.InTx(req.Context(), func(qtx *database.Queries) error {
+				err := qtx.RestoreChirpsForUser(
+					req.Context(),
+					database.RestoreChirpsForUserParams{
+						UserID:    user.ID,
+						DeletedAt: user.DeletedAt,
+					},
+				)
+				if err != nil {
+					return err
+				}
+
+				user, err = qtx.RestoreUser(req.Context(), user.ID)
+
+				return err
+			})
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			resData := User{
+				ID:          user.ID,
+				CreatedAt:   user.CreatedAt,
+				UpdatedAt:   user.UpdatedAt,
+				Email:       user.Email,
+				Username:    user.Username.String,
+				IsChirpyRed: user.IsChirpyRed,
+			}
+
+			res, err := json.Marshal(&resData)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.Header().Set("Content-Type", "application/json")
+
+			_, _ = writer.Write(res)
+		},
+	)
+}
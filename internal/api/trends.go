@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/pagination"
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/trends"
+)
+
+type Trend struct {
+	Tag           string    `json:"tag"`
+	RecentCount   int64     `json:"recent_count"`
+	BaselineCount float64   `json:"baseline_count"`
+	Score         float64   `json:"score"`
+	ComputedAt    time.Time `json:"computed_at"`
+}
+
+// GET /api/trends
+//
+// Lists the trends of one of windows, the first by default.
+func GetTrends(env *appenv.Env, windows []trends.Window) http.Handler {
+	names := make([]string, 0, len(windows))
+	for _, window := range windows {
+		names = append(names, window.Name)
+	}
+
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			window := req.URL.Query().Get("window")
+			if window == "" {
+				window = names[0]
+			}
+
+			if !slices.Contains(names, window) {
+				problem.BadRequest(
+					writer,
+					"window must be one of "+strings.Join(names, ", "),
+				)
+
+				return
+			}
+
+			limit, _, err := pagination.Parse(req)
+			if err != nil {
+				problem.BadRequest(writer, err.Error())
+
+				return
+			}
+
+			opts := database.ListTrendsParams{
+				WindowName: window,
+				Limit:      limit,
+			}
+
+			rows, err := env.DB.ListTrends(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			resData := make([]Trend, 0, len(rows))
+
+			for _, trend := range rows {
+				resData = append(resData, Trend{
+					Tag:           trend.Tag,
+					RecentCount:   trend.RecentCount,
+					BaselineCount: trend.BaselineCount,
+					Score:         trend.Score,
+					ComputedAt:    trend.ComputedAt,
+				})
+			}
+
+			res, err := json.Marshal(&resData)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.Header().Set("Content-Type", "application/json")
+
+			_, _ = writer.Write(res)
+		},
+	)
+}
@@ -5,26 +5,51 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
-	"os"
 
 	"github.com/google/uuid"
 	"github.com/zyrterviews/chirpy/internal/appenv"
 	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/events"
+	"github.com/zyrterviews/chirpy/internal/metrics"
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/validate"
 )
 
+var errUnknownUser = errors.New("unknown user")
+
 // POST /api/polka/webhooks
 func PostPolkaUpradeUser(env *appenv.Env) http.Handler {
 	return http.HandlerFunc(
 		func(writer http.ResponseWriter, req *http.Request) {
+			outcome := metrics.WebhookError
+			defer func() { env.Metrics.Webhook(outcome) }()
+
 			apiKey, err := auth.GetAPIKey(req.Header)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusUnauthorized)
+				outcome = metrics.WebhookUnauthorized
+
+				problem.Write(
+					writer,
+					http.StatusUnauthorized,
+					problem.CodeUnauthorized,
+					err.Error(),
+				)
 
 				return
 			}
 
-			if apiKey != os.Getenv("POLKA_KEY") {
-				http.Error(writer, err.Error(), http.StatusUnauthorized)
+			// An unset POLKA_KEY must not match an empty "ApiKey " header.
+			if env.Config.PolkaKey == "" ||
+				apiKey != env.Config.PolkaKey.Reveal() {
+				outcome = metrics.WebhookUnauthorized
+
+				problem.Write(
+					writer,
+					http.StatusUnauthorized,
+					problem.CodeUnauthorized,
+					"invalid API key",
+				)
 
 				return
 			}
@@ -38,21 +63,30 @@ func PostPolkaUpradeUser(env *appenv.Env) http.Handler {
 
 			var data input
 
-			decoder := json.NewDecoder(req.Body)
+			// Unknown fields are allowed: Polka may add to its payloads.
+			decoder := json.NewDecoder(
+				http.MaxBytesReader(writer, req.Body, validate.MaxBodySize),
+			)
 
 			if err := decoder.Decode(&data); err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				outcome = metrics.WebhookInvalid
+
+				problem.InvalidJSON(writer)
 
 				return
 			}
 
 			if data.Data.UserID == "" {
-				http.Error(writer, "Missing user ID", http.StatusBadRequest)
+				outcome = metrics.WebhookInvalid
+
+				problem.BadRequest(writer, "data.user_id is required")
 
 				return
 			}
 
 			if data.Event != "user.upgraded" {
+				outcome = metrics.WebhookIgnored
+
 				writer.WriteHeader(http.StatusNoContent)
 
 				return
@@ -60,23 +94,57 @@ func PostPolkaUpradeUser(env *appenv.Env) http.Handler {
 
 			id, err := uuid.Parse(data.Data.UserID)
 			if err != nil {
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				outcome = metrics.WebhookInvalid
+
+				problem.BadRequest(writer, "data.user_id must be a UUID")
 
 				return
 			}
 
-			if _, err := env.DB.SetUserAsChirpyRed(req.Context(), id); err != nil {
+			// The upgrade, its audit event and the user's notification are
+			// committed together: none of them is kept without the others.
+			err = env.InTx(req.Context(), func(qtx *database.Queries) error {
+				_, err := qtx.SetUserAsChirpyRed(req.Context(), id)
 				if errors.Is(err, sql.ErrNoRows) {
-					http.NotFound(writer, req)
+					return errUnknownUser
+				}
 
-					return
+				if err != nil {
+					return err
 				}
 
-				http.Error(writer, err.Error(), http.StatusInternalServerError)
+				err = qtx.CreateSubscriptionEvent(
+					req.Context(),
+					database.CreateSubscriptionEventParams{
+						UserID: id,
+						Event:  data.Event,
+					},
+				)
+				if err != nil {
+					return err
+				}
+
+				return events.Record(req.Context(), qtx, events.Event{
+					Kind:      events.KindChirpyRed,
+					Recipient: id,
+				})
+			})
+			if errors.Is(err, errUnknownUser) {
+				outcome = metrics.WebhookUnknownUser
+
+				problem.NotFound(writer)
 
 				return
 			}
 
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			outcome = metrics.WebhookUpgraded
+
 			writer.WriteHeader(http.StatusNoContent)
 		},
 	)
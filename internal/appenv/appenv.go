@@ -1,15 +1,25 @@
 package appenv
 
 import (
-	"sync/atomic"
+	"database/sql"
+	"log/slog"
 
-	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/blob"
+	"github.com/zyrterviews/chirpy/internal/config"
 	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/metrics"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Env struct {
-	DB             *database.Queries
-	JWTSecret      string
-	FileserverHits *atomic.Int32
-	UserID         uuid.UUID
+	DB     *database.Queries
+	SQL    *sql.DB
+	Config *config.Config
+	Blobs  blob.Store
+	// Exports keeps data export archives. Unlike Blobs it must not be
+	// publicly readable.
+	Exports blob.Store
+	Logger  *slog.Logger
+	Metrics *metrics.Metrics
+	Tracing trace.TracerProvider
 }
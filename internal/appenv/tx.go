@@ -0,0 +1,41 @@
+package appenv
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/tracing"
+)
+
+// InTx runs fn with queries bound to a single transaction, committing if fn
+// succeeds and rolling back otherwise.
+func (env *Env) InTx(
+	ctx context.Context,
+	fn func(qtx *database.Queries) error,
+) error {
+	tx, err := env.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	if err := fn(env.TxQueries(tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// TxQueries returns queries bound to tx. Unlike env.DB.WithTx, their spans
+// are recorded like those of env.DB.
+func (env *Env) TxQueries(tx *sql.Tx) *database.Queries {
+	return database.New(tracing.DB(tx, env.Tracing))
+}
@@ -20,16 +20,23 @@ const (
 	hashCost                int = 12
 )
 
+// Errors returned by HashPassword for passwords that cannot be used.
+var (
+	ErrEmptyPassword   = errors.New("password cannot be empty")
+	ErrPasswordTooLong = errors.New("password is too long")
+)
+
 func HashPassword(password string) (string, error) {
 	if password == "" {
-		return "", errors.New("password cannot be empty")
+		return "", ErrEmptyPassword
 	}
 
 	bytePwd := []byte(password)
 
 	if len(bytePwd) > maxBcryptPasswordLength {
 		return "", fmt.Errorf(
-			"password is too long, received %d bytes but maximum allowed is %d bytes",
+			"%w, received %d bytes but maximum allowed is %d bytes",
+			ErrPasswordTooLong,
 			len(bytePwd),
 			maxBcryptPasswordLength,
 		)
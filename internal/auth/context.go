@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type userIDKey struct{}
+
+// WithUserID returns a context carrying the ID of the user a request was
+// authenticated as.
+func WithUserID(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserID returns the ID of the user the request carried by ctx was
+// authenticated as, or uuid.Nil for anonymous requests.
+func UserID(ctx context.Context) uuid.UUID {
+	userID, _ := ctx.Value(userIDKey{}).(uuid.UUID)
+
+	return userID
+}
@@ -2,8 +2,12 @@ package auth
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"net/http"
 
+	"github.com/google/uuid"
 	"github.com/zyrterviews/chirpy/internal/appenv"
 )
 
@@ -18,13 +22,40 @@ func (e AuthError) Error() string {
 	return fmt.Sprintf("%d %s", e.Status, e.Err)
 }
 
+var errUnauthorized = errors.New("UNAUTHORIZED") //nolint:stylecheck
+
+// IsModerator lets through users flagged as moderators.
+func IsModerator(ctx context.Context, env *appenv.Env) (bool, *AuthError) {
+	userID := UserID(ctx)
+	if userID == uuid.Nil {
+		return false, &AuthError{
+			Err:    errUnauthorized,
+			Status: http.StatusUnauthorized,
+		}
+	}
+
+	user, err := env.DB.GetUserByID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, &AuthError{
+			Err:    err,
+			Status: http.StatusInternalServerError,
+		}
+	}
+
+	return user.IsModerator, nil
+}
+
 // func CanDeleteChirps(ctx context.Context, env *appenv.Env) (bool, *AuthError) {
-// 	if env.UserID == uuid.Nil {
+// 	if UserID(ctx) == uuid.Nil {
 // 		//nolint:exhaustruct
 // 		return false, &AuthError{Status: http.StatusUnauthorized}
 // 	}
 
-// 	chirp, err := env.DB.GetAllChirpsForUser(ctx, env.UserID)
+// 	chirp, err := env.DB.GetAllChirpsForUser(ctx, UserID(ctx))
 // 	if err != nil {
 // 		return false, &AuthError{
 // 			Status: http.StatusInternalServerError,
@@ -0,0 +1,21 @@
+// Package blob stores uploaded files. Store is implemented by a local
+// filesystem backend and by an S3-compatible one.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+type Store interface {
+	// Put stores data under key, replacing any existing blob.
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Get returns a reader for the blob stored under key, or ErrNotFound.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// URL returns the address clients use to fetch the blob.
+	URL(key string) string
+}
@@ -0,0 +1,180 @@
+package blob_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zyrterviews/chirpy/internal/blob"
+)
+
+// fakeS3 is a minimal in-memory stand-in for an S3-compatible service. It
+// only accepts requests that carry a SigV4 Authorization header for the
+// expected access key and a payload hash matching the body.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=test-key/") ||
+		!strings.Contains(auth, "/test-region/s3/aws4_request") {
+		http.Error(writer, "SignatureDoesNotMatch", http.StatusForbidden)
+
+		return
+	}
+
+	body, _ := io.ReadAll(req.Body)
+	sum := sha256.Sum256(body)
+
+	if req.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		http.Error(writer, "XAmzContentSHA256Mismatch", http.StatusBadRequest)
+
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch req.Method {
+	case http.MethodPut:
+		f.objects[req.URL.Path] = body
+		f.types[req.URL.Path] = req.Header.Get("Content-Type")
+	case http.MethodGet:
+		data, ok := f.objects[req.URL.Path]
+		if !ok {
+			http.Error(writer, "NoSuchKey", http.StatusNotFound)
+
+			return
+		}
+
+		writer.Header().Set("Content-Type", f.types[req.URL.Path])
+		_, _ = writer.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, req.URL.Path)
+		writer.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+
+	t.Cleanup(server.Close)
+
+	store := &blob.S3Store{
+		Endpoint:        server.URL,
+		Region:          "test-region",
+		Bucket:          "chirpy",
+		AccessKeyID:     "test-key",
+		SecretAccessKey: "test-secret",
+		Client:          server.Client(),
+	}
+
+	testStore(t, store)
+
+	t.Run("should store objects under the bucket path", func(t *testing.T) {
+		t.Parallel()
+
+		if err := store.Put(context.Background(), "a/b.png", "image/png", []byte("x")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		if fake.types["/chirpy/a/b.png"] != "image/png" {
+			t.Fatalf("expected object at /chirpy/a/b.png, got %v", fake.types)
+		}
+	})
+
+	t.Run("should build public URLs from the endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		want := server.URL + "/chirpy/a/b.png"
+		if got := store.URL("a/b.png"); got != want {
+			t.Fatalf("unexpected URL: got %q, want %q", got, want)
+		}
+	})
+}
+
+func TestLocalStore(t *testing.T) {
+	t.Parallel()
+
+	store := blob.NewLocalStore(t.TempDir(), blob.DefaultLocalBaseURL)
+
+	testStore(t, store)
+
+	t.Run("should serve blobs through the static file server", func(t *testing.T) {
+		t.Parallel()
+
+		if got := store.URL("a/b.png"); got != "/app/media/a/b.png" {
+			t.Fatalf("unexpected URL: got %q", got)
+		}
+	})
+
+	t.Run("should refuse keys that escape the root", func(t *testing.T) {
+		t.Parallel()
+
+		err := store.Put(context.Background(), "../evil", "text/plain", []byte("x"))
+		if err == nil {
+			t.Fatal("expected an error, but got none")
+		}
+	})
+}
+
+func testStore(t *testing.T, store blob.Store) {
+	t.Helper()
+
+	t.Run("should round-trip a blob", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		data := []byte("not really a png")
+
+		if err := store.Put(ctx, "round/trip.png", "image/png", data); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		reader, err := store.Get(ctx, "round/trip.png")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer reader.Close()
+
+		got, _ := io.ReadAll(reader)
+		if !bytes.Equal(got, data) {
+			t.Fatalf("unexpected blob: got %q, want %q", got, data)
+		}
+	})
+
+	t.Run("should return ErrNotFound after a delete", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+
+		if err := store.Put(ctx, "gone.png", "image/png", []byte("x")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := store.Delete(ctx, "gone.png"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := store.Get(ctx, "gone.png"); !errors.Is(err, blob.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got: %v", err)
+		}
+	})
+}
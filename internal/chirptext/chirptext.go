@@ -0,0 +1,126 @@
+// Package chirptext extracts @mentions and #hashtags from chirp bodies.
+package chirptext
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	minUsernameLength int = 3
+	maxUsernameLength int = 30
+	maxHashtagLength  int = 100
+)
+
+type EntityType string
+
+const (
+	EntityMention EntityType = "mention"
+	EntityHashtag EntityType = "hashtag"
+)
+
+// Entity is a mention or hashtag found in a body. Start and End are byte
+// offsets of the whole token, sigil included, so that body[Start:End] is the
+// text as the author wrote it. Value is the normalised username or tag.
+type Entity struct {
+	Type  EntityType
+	Value string
+	Start int
+	End   int
+}
+
+// Parse returns every mention and hashtag in body, in order of appearance.
+// A sigil only starts an entity at the beginning of the body or after a
+// character that cannot be part of a word, so e-mail addresses and
+// `foo#bar` are left alone.
+func Parse(body string) []Entity {
+	var (
+		entities []Entity
+		prev     rune
+	)
+
+	for i := 0; i < len(body); {
+		r, size := utf8.DecodeRuneInString(body[i:])
+
+		if (r == '@' || r == '#') && !isWordRune(prev) {
+			end := i + size
+			for end < len(body) {
+				next, nextSize := utf8.DecodeRuneInString(body[end:])
+				if !isWordRune(next) {
+					break
+				}
+
+				end += nextSize
+			}
+
+			word := body[i+size : end]
+
+			switch {
+			case r == '@' && IsValidUsername(word):
+				entities = append(entities, Entity{
+					Type:  EntityMention,
+					Value: NormalizeUsername(word),
+					Start: i,
+					End:   end,
+				})
+			case r == '#' && isValidHashtag(word):
+				entities = append(entities, Entity{
+					Type:  EntityHashtag,
+					Value: NormalizeHashtag(word),
+					Start: i,
+					End:   end,
+				})
+			}
+
+			if end > i+size {
+				prev, _ = utf8.DecodeLastRuneInString(body[:end])
+				i = end
+
+				continue
+			}
+		}
+
+		prev = r
+		i += size
+	}
+
+	return entities
+}
+
+// IsValidUsername reports whether name can be used as a username, and
+// therefore be mentioned: 3 to 30 ASCII letters, digits or underscores.
+func IsValidUsername(name string) bool {
+	if len(name) < minUsernameLength || len(name) > maxUsernameLength {
+		return false
+	}
+
+	for _, r := range name {
+		if r > unicode.MaxASCII || !isWordRune(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func NormalizeUsername(name string) string {
+	return strings.ToLower(name)
+}
+
+func NormalizeHashtag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(tag, "#"))
+}
+
+func isValidHashtag(tag string) bool {
+	if tag == "" || utf8.RuneCountInString(tag) > maxHashtagLength {
+		return false
+	}
+
+	// `#1` is a number, not a topic.
+	return strings.IndexFunc(tag, unicode.IsLetter) >= 0
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,89 @@
+package chirptext_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zyrterviews/chirpy/internal/chirptext"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want []chirptext.Entity
+	}{
+		{
+			name: "should find mentions and hashtags with byte offsets",
+			body: "hey @Alice, loving #GoLang",
+			want: []chirptext.Entity{
+				{Type: chirptext.EntityMention, Value: "alice", Start: 4, End: 10},
+				{Type: chirptext.EntityHashtag, Value: "golang", Start: 19, End: 26},
+			},
+		},
+		{
+			name: "should use byte offsets after multi-byte characters",
+			body: "café #crème",
+			want: []chirptext.Entity{
+				{Type: chirptext.EntityHashtag, Value: "crème", Start: 6, End: 13},
+			},
+		},
+		{
+			name: "should ignore e-mail addresses and sigils inside words",
+			body: "mail me at bob@example.com or foo#bar",
+			want: nil,
+		},
+		{
+			name: "should ignore purely numeric hashtags",
+			body: "we're #1",
+			want: nil,
+		},
+		{
+			name: "should ignore usernames that are too short",
+			body: "cc @al",
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := chirptext.Parse(test.body)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("unexpected entities: got %+v, want %+v", got, test.want)
+			}
+
+			for _, entity := range got {
+				if test.body[entity.Start] != '@' && test.body[entity.Start] != '#' {
+					t.Fatalf(
+						"expected entity to start on a sigil, got %q",
+						test.body[entity.Start:entity.End],
+					)
+				}
+			}
+		})
+	}
+}
+
+func TestIsValidUsername(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should accept letters, digits and underscores", func(t *testing.T) {
+		t.Parallel()
+
+		if !chirptext.IsValidUsername("chirpy_fan_42") {
+			t.Fatal("expected username to be valid")
+		}
+	})
+
+	t.Run("should reject non-ASCII letters", func(t *testing.T) {
+		t.Parallel()
+
+		if chirptext.IsValidUsername("zoë") {
+			t.Fatal("expected username to be invalid")
+		}
+	})
+}
@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/zyrterviews/chirpy/internal/api"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/database"
+)
+
+// chirpRecord is one line of the JSON Lines format read by `chirps import`
+// and written by `chirps export`. Authors are identified by email so that
+// chirps can move between instances.
+type chirpRecord struct {
+	ID        uuid.UUID `json:"id,omitempty"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newChirpsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "chirps",
+		Short: "Import and export chirps as JSON Lines",
+	}
+
+	cmd.AddCommand(newChirpsExportCommand(), newChirpsImportCommand())
+
+	return cmd
+}
+
+func newChirpsExportCommand() *cobra.Command {
+	var email, output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write published chirps, oldest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out, closeOut, err := openOutput(cmd, output)
+			if err != nil {
+				return err
+			}
+			defer closeOut()
+
+			return withEnv(cmd.Context(),
+				func(ctx context.Context, env *appenv.Env) error {
+					return exportChirps(ctx, env, email, out)
+				},
+			)
+		},
+	}
+
+	cmd.Flags().StringVar(&email, "user", "", "only export chirps of the user with this email")
+	cmd.Flags().StringVarP(&output, "output", "o", "-", "file to write, - for stdout")
+
+	return cmd
+}
+
+func exportChirps(
+	ctx context.Context,
+	env *appenv.Env,
+	email string,
+	out io.Writer,
+) error {
+	var (
+		chirps []database.Chirp
+		err    error
+	)
+
+	authors := map[uuid.UUID]string{}
+
+	if email == "" {
+		chirps, err = env.DB.GetAllChirps(ctx, "asc")
+	} else {
+		user, lookupErr := env.DB.GetUserByEmail(ctx, email)
+		if lookupErr != nil {
+			return userLookupError(email, lookupErr)
+		}
+
+		authors[user.ID] = user.Email
+		chirps, err = env.DB.GetAllChirpsForUser(ctx,
+			database.GetAllChirpsForUserParams{
+				UserID:  user.ID,
+				Column2: "asc",
+			},
+		)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(out)
+
+	for _, chirp := range chirps {
+		author, ok := authors[chirp.UserID]
+		if !ok {
+			user, err := env.DB.GetUserByID(ctx, chirp.UserID)
+			if err != nil {
+				return fmt.Errorf("load author of chirp %s: %w", chirp.ID, err)
+			}
+
+			author = user.Email
+			authors[chirp.UserID] = author
+		}
+
+		err := encoder.Encode(chirpRecord{
+			ID:        chirp.ID,
+			Author:    author,
+			Body:      chirp.Body,
+			CreatedAt: chirp.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newChirpsImportCommand() *cobra.Command {
+	var input string
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Publish chirps from a JSON Lines file",
+		Long: "Publish one chirp per line. Each author must already have an " +
+			"account. Chirps keep their created_at, go through the same " +
+			"checks as the API and do not notify mentioned users. The " +
+			"original ids are ignored.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			in := cmd.InOrStdin()
+
+			if input != "-" {
+				file, err := os.Open(input)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+
+				in = file
+			}
+
+			return withEnv(cmd.Context(),
+				func(ctx context.Context, env *appenv.Env) error {
+					count, err := importChirps(ctx, env, in)
+
+					fmt.Fprintf(cmd.ErrOrStderr(), "imported %d chirps\n", count)
+
+					return err
+				},
+			)
+		},
+	}
+
+	cmd.Flags().StringVarP(&input, "input", "i", "-", "file to read, - for stdin")
+
+	return cmd
+}
+
+// importChirps stops at the first bad line. Lines before it stay imported,
+// so a fixed file can be re-run from that line on.
+func importChirps(
+	ctx context.Context,
+	env *appenv.Env,
+	in io.Reader,
+) (int, error) {
+	authors := map[string]uuid.UUID{}
+	scanner := bufio.NewScanner(in)
+	count := 0
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record chirpRecord
+
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return count, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		authorID, ok := authors[record.Author]
+		if !ok {
+			user, err := env.DB.GetUserByEmail(ctx, record.Author)
+			if err != nil {
+				return count, fmt.Errorf(
+					"line %d: %w",
+					line,
+					userLookupError(record.Author, err),
+				)
+			}
+
+			authorID = user.ID
+			authors[record.Author] = authorID
+		}
+
+		createdAt := record.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+
+		_, err := api.ImportChirp(ctx, env, authorID, record.Body, createdAt)
+		if err != nil {
+			return count, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		count++
+	}
+
+	return count, scanner.Err()
+}
+
+// openOutput returns where a command writes its result: stdout for "-",
+// otherwise a newly created file.
+func openOutput(cmd *cobra.Command, path string) (io.Writer, func(), error) {
+	if path == "-" {
+		return cmd.OutOrStdout(), func() {}, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return file, func() { _ = file.Close() }, nil
+}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/blob"
+	"github.com/zyrterviews/chirpy/internal/config"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/logging"
+	"github.com/zyrterviews/chirpy/internal/metrics"
+	"github.com/zyrterviews/chirpy/internal/tracing"
+)
+
+const (
+	// connectDelay is the wait before the second database ping; it doubles
+	// after every failed attempt up to maxConnectDelay.
+	connectDelay    = 500 * time.Millisecond
+	maxConnectDelay = 5 * time.Second
+
+	// flushTimeout bounds how long buffered spans may take to export on
+	// exit.
+	flushTimeout = 5 * time.Second
+)
+
+// loadEnv loads the configuration and opens the database and blob stores.
+// Every command goes through it, so the server and the admin commands always
+// agree on where data lives. Callers call closeEnv when done, which closes
+// the database and flushes buffered spans.
+func loadEnv(ctx context.Context) (env *appenv.Env, closeEnv func(), err error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	logger, err := logging.New(os.Stderr, cfg.LogLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Background jobs log through the default logger, and dependencies
+	// that use the log package end up in the same JSON stream.
+	slog.SetDefault(logger)
+
+	provider, shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	flush := func() {
+		flushCtx, cancel := context.WithTimeout(
+			context.Background(),
+			flushTimeout,
+		)
+		defer cancel()
+
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Warn("tracing: could not flush spans", "error", err)
+		}
+	}
+
+	db, err := sql.Open("postgres", cfg.DatabaseURL.Reveal())
+	if err != nil {
+		flush()
+
+		return nil, nil, fmt.Errorf("open database: %w", err)
+	}
+
+	db.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.DB.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DB.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.DB.ConnMaxIdleTime)
+
+	closeEnv = func() {
+		db.Close()
+		flush()
+	}
+
+	if err := connect(ctx, db, cfg.DB.ConnectAttempts); err != nil {
+		closeEnv()
+
+		return nil, nil, err
+	}
+
+	return &appenv.Env{
+		DB:      database.New(tracing.DB(db, provider)),
+		SQL:     db,
+		Config:  cfg,
+		Logger:  logger,
+		Blobs:   newBlobStore(cfg.Media, cfg.S3, blob.DefaultLocalBaseURL),
+		Exports: newBlobStore(cfg.Exports, cfg.S3, ""),
+		Metrics: metrics.New(db),
+		Tracing: provider,
+	}, closeEnv, nil
+}
+
+// withEnv runs fn against a freshly loaded env and closes it afterwards.
+func withEnv(
+	ctx context.Context,
+	fn func(context.Context, *appenv.Env) error,
+) error {
+	env, closeEnv, err := loadEnv(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeEnv()
+
+	return fn(ctx, env)
+}
+
+// connect pings the database until it answers, backing off between
+// attempts, so that chirpy can start next to a database that is still
+// booting.
+func connect(ctx context.Context, db *sql.DB, attempts int) error {
+	delay := connectDelay
+
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= attempts {
+			return fmt.Errorf(
+				"database unreachable after %d attempts: %w",
+				attempts,
+				err,
+			)
+		}
+
+		slog.Warn(
+			"database: not ready",
+			"attempt", attempt,
+			"attempts", attempts,
+			"error", err,
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay = min(delay*2, maxConnectDelay) //nolint:mnd
+	}
+}
+
+// newBlobStore builds the backend for store. The configuration has already
+// been validated, so store.Kind is either local or s3. Local blobs are served
+// from localBaseURL; exports pass "" because they are never served directly.
+func newBlobStore(
+	store config.Store,
+	s3 config.S3,
+	localBaseURL string,
+) blob.Store {
+	if store.Kind == config.StoreS3 {
+		return &blob.S3Store{
+			Endpoint:        s3.Endpoint,
+			Region:          s3.Region,
+			Bucket:          store.Bucket,
+			AccessKeyID:     s3.AccessKeyID,
+			SecretAccessKey: s3.SecretAccessKey.Reveal(),
+			PublicURL:       store.PublicURL,
+		}
+	}
+
+	return blob.NewLocalStore(store.Dir, localBaseURL)
+}
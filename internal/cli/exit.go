@@ -0,0 +1,28 @@
+package cli
+
+import "errors"
+
+// Exit codes returned by chirpy.
+const (
+	// ExitOK means the command finished, or the server shut down cleanly.
+	ExitOK = 0
+	// ExitFailure means the command failed or the server could not start.
+	ExitFailure = 1
+	// ExitUncleanShutdown means the server was told to stop but requests
+	// or workers were still running when SHUTDOWN_TIMEOUT ran out.
+	ExitUncleanShutdown = 2
+)
+
+var ErrUncleanShutdown = errors.New("shutdown deadline exceeded")
+
+// ExitCode maps the error returned by Execute to the process exit code.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrUncleanShutdown):
+		return ExitUncleanShutdown
+	default:
+		return ExitFailure
+	}
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/migrate"
+	"github.com/zyrterviews/chirpy/sql/schema"
+)
+
+func newMigrator(db *sql.DB) (*migrate.Runner, error) {
+	migrations, err := migrate.Load(schema.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &migrate.Runner{
+		DB:         db,
+		Migrations: migrations,
+		Log:        os.Stdout,
+	}, nil
+}
+
+func newMigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply or roll back database migrations",
+	}
+
+	cmd.AddCommand(
+		migrateCommand("up", "Apply all pending migrations",
+			func(ctx context.Context, m *migrate.Runner) error {
+				return m.Up(ctx)
+			},
+		),
+		migrateCommand("down", "Roll back the latest migration",
+			func(ctx context.Context, m *migrate.Runner) error {
+				return m.Down(ctx)
+			},
+		),
+		migrateCommand("redo", "Roll back and re-apply the latest migration",
+			func(ctx context.Context, m *migrate.Runner) error {
+				return m.Redo(ctx)
+			},
+		),
+		migrateCommand("status", "List migrations and when they were applied",
+			printMigrationStatus,
+		),
+		&cobra.Command{
+			Use:   "to VERSION",
+			Short: "Migrate up or down to VERSION",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				version, err := strconv.ParseInt(args[0], 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid version %q", args[0]) //nolint:err113
+				}
+
+				return withMigrator(cmd.Context(),
+					func(ctx context.Context, m *migrate.Runner) error {
+						return m.To(ctx, version)
+					},
+				)
+			},
+		},
+	)
+
+	return cmd
+}
+
+func migrateCommand(
+	use, short string,
+	run func(context.Context, *migrate.Runner) error,
+) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return withMigrator(cmd.Context(), run)
+		},
+	}
+}
+
+func withMigrator(
+	ctx context.Context,
+	fn func(context.Context, *migrate.Runner) error,
+) error {
+	return withEnv(ctx, func(ctx context.Context, env *appenv.Env) error {
+		migrator, err := newMigrator(env.SQL)
+		if err != nil {
+			return err
+		}
+
+		return fn(ctx, migrator)
+	})
+}
+
+func printMigrationStatus(ctx context.Context, migrator *migrate.Runner) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:mnd
+	fmt.Fprintln(out, "Applied At\tMigration")
+
+	for _, status := range statuses {
+		appliedAt := "Pending"
+		if status.Applied {
+			appliedAt = status.AppliedAt.Format(time.ANSIC)
+		}
+
+		fmt.Fprintf(out, "%s\t%s\n", appliedAt, status.Migration.Name)
+	}
+
+	return out.Flush()
+}
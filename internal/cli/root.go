@@ -0,0 +1,43 @@
+// Package cli implements the chirpy command: the API server and the operator
+// commands that share its configuration and storage.
+package cli
+
+import "github.com/spf13/cobra"
+
+// configPath is the --config flag shared by every command.
+var configPath string
+
+// NewRootCommand builds the chirpy command tree. Running chirpy without a
+// subcommand serves the API, as it always has.
+func NewRootCommand() *cobra.Command {
+	serve := newServeCommand()
+
+	root := &cobra.Command{
+		Use:           "chirpy",
+		Short:         "Chirpy API server and administration tools",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE:          serve.RunE,
+	}
+
+	root.PersistentFlags().StringVar(&configPath, "config", "",
+		"YAML config file (default $CONFIG_FILE)",
+	)
+
+	root.AddCommand(
+		serve,
+		newConfigCommand(),
+		newMigrateCommand(),
+		newUsersCommand(),
+		newTokensCommand(),
+		newChirpsCommand(),
+		newSeedCommand(),
+	)
+
+	return root
+}
+
+func Execute() error {
+	return NewRootCommand().Execute()
+}
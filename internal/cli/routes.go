@@ -0,0 +1,552 @@
+package cli
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/zyrterviews/chirpy/app"
+	"github.com/zyrterviews/chirpy/internal/api"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/config"
+	"github.com/zyrterviews/chirpy/internal/dm"
+	"github.com/zyrterviews/chirpy/internal/health"
+	"github.com/zyrterviews/chirpy/internal/logging"
+	"github.com/zyrterviews/chirpy/internal/middleware"
+	"github.com/zyrterviews/chirpy/internal/openapi"
+	"github.com/zyrterviews/chirpy/internal/ratelimit"
+	"github.com/zyrterviews/chirpy/internal/trends"
+)
+
+// Rate limit policies. Buckets refill evenly over their window: the login
+// limit allows a burst of 10 attempts, then one every 30 seconds.
+var (
+	signupLimit = ratelimit.Policy{
+		Name:  "signup",
+		Limit: ratelimit.Limit{Requests: 5, Window: time.Hour},
+	}
+	loginLimit = ratelimit.Policy{
+		Name:  "login",
+		Limit: ratelimit.Limit{Requests: 10, Window: 5 * time.Minute},
+	}
+	chirpLimit = ratelimit.Policy{
+		Name:     "chirps",
+		Limit:    ratelimit.Limit{Requests: 20, Window: 10 * time.Minute},
+		RedLimit: ratelimit.Limit{Requests: 60, Window: 10 * time.Minute},
+	}
+)
+
+// appCSP lets the web app's pages load what chirpy itself serves.
+const appCSP = "default-src 'self'; frame-ancestors 'none'"
+
+// corsPolicies says which routes pages served from other origins may call.
+// The API is open to the configured origins, a few public documents to any,
+// and everything else, from the web app to the admin endpoints, to none.
+func corsPolicies(cfg config.CORS) map[string]middleware.CORSPolicy {
+	// The configuration has already been validated.
+	origins, _ := config.ParseOrigins(cfg.AllowedOrigins)
+
+	api := middleware.CORSPolicy{
+		AllowedOrigins: origins,
+		AllowedMethods: []string{
+			http.MethodGet,
+			http.MethodPost,
+			http.MethodPut,
+			http.MethodDelete,
+		},
+		AllowedHeaders: []string{
+			"Authorization",
+			"Content-Type",
+			logging.RequestIDHeader,
+			"traceparent",
+			"tracestate",
+		},
+		ExposedHeaders: []string{
+			logging.RequestIDHeader,
+			"RateLimit-Limit",
+			"RateLimit-Remaining",
+			"RateLimit-Reset",
+			"RateLimit-Policy",
+			"Retry-After",
+		},
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
+	}
+
+	public := middleware.CORSPolicy{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{http.MethodGet},
+		MaxAge:         cfg.MaxAge,
+	}
+
+	return map[string]middleware.CORSPolicy{
+		"/api/":             api,
+		"/api/healthz":      public,
+		"/api/openapi.json": public,
+		// Only Polka's servers call it.
+		"/api/polka/webhooks": {},
+	}
+}
+
+// Handler returns what `chirpy serve` serves: every route, behind the
+// middleware each request goes through. Client tests run against it too.
+func Handler(
+	env *appenv.Env,
+	messages *dm.Hub,
+	checker *health.Checker,
+	limits ratelimit.Store,
+	windows []trends.Window,
+) http.Handler {
+	return middleware.Observe(env)(
+		middleware.SecurityHeaders(env)(
+			middleware.CORS(corsPolicies(env.Config.CORS))(
+				middleware.Unmatched(routes(env, messages, checker, limits, windows)),
+			),
+		),
+	)
+}
+
+// routes registers every HTTP endpoint served by `chirpy serve`.
+func routes(
+	env *appenv.Env,
+	messages *dm.Hub,
+	checker *health.Checker,
+	limits ratelimit.Store,
+	windows []trends.Window,
+) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	// APP
+	mux.Handle("GET /app/",
+		middleware.CSP(appCSP)(
+			middleware.MetricsInc(env)(app.GetStaticAssets()),
+		),
+	)
+	// Media are looked up before being served rather than left to the file
+	// server, which would serve those of deleted chirps too.
+	mux.Handle("GET /app/media/{key...}",
+		middleware.CSP(appCSP)(
+			middleware.MetricsInc(env)(api.GetMediaFile(env)),
+		),
+	)
+
+	// PROBES
+	mux.Handle("GET /livez", health.GetLivez())
+	mux.Handle("GET /readyz", health.GetReadyz(checker))
+
+	// API
+	mux.Handle("GET /api/healthz", api.GetHealthz())
+	mux.Handle("GET /api/openapi.json", openapi.GetSpec())
+	mux.Handle("GET /api/docs", openapi.GetDocs())
+	mux.Handle("GET /api/docs/{asset}", openapi.GetDocsAsset())
+
+	mux.Handle(
+		"POST /api/chirps",
+		middleware.Chain(
+			env,
+			middleware.Authenticate,
+			middleware.RateLimit(limits, chirpLimit),
+			middleware.New(api.PostOneChirp(env)),
+		),
+	)
+
+	mux.Handle("DELETE /api/chirps/{chirpID}",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.DeleteChirpByID(env)),
+		),
+	)
+
+	mux.Handle("POST /api/media",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PostMedia(env)),
+		),
+	)
+
+	mux.Handle("GET /api/drafts",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.GetDrafts(env)),
+		),
+	)
+
+	mux.Handle("PUT /api/drafts/{chirpID}",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PutDraft(env)),
+		),
+	)
+
+	mux.Handle("POST /api/drafts/{chirpID}/publish",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PostPublishDraft(env)),
+		),
+	)
+
+	mux.Handle("DELETE /api/drafts/{chirpID}",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.DeleteDraft(env)),
+		),
+	)
+
+	mux.Handle("GET /api/bookmarks",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.GetBookmarks(env)),
+		),
+	)
+
+	mux.Handle("PUT /api/bookmarks/{chirpID}",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PutBookmark(env)),
+		),
+	)
+
+	mux.Handle("DELETE /api/bookmarks/{chirpID}",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.DeleteBookmark(env)),
+		),
+	)
+
+	mux.Handle("POST /api/lists",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PostList(env)),
+		),
+	)
+
+	mux.Handle("GET /api/lists",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.GetLists(env)),
+		),
+	)
+
+	mux.Handle("GET /api/lists/{listID}",
+		middleware.Chain(env,
+			middleware.OptionalAuthenticate,
+			middleware.New(api.GetList(env)),
+		),
+	)
+
+	mux.Handle("PUT /api/lists/{listID}",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PutList(env)),
+		),
+	)
+
+	mux.Handle("DELETE /api/lists/{listID}",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.DeleteList(env)),
+		),
+	)
+
+	mux.Handle("PUT /api/lists/{listID}/members/{userID}",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PutListMember(env)),
+		),
+	)
+
+	mux.Handle("DELETE /api/lists/{listID}/members/{userID}",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.DeleteListMember(env)),
+		),
+	)
+
+	mux.Handle("GET /api/lists/{listID}/chirps",
+		middleware.Chain(env,
+			middleware.OptionalAuthenticate,
+			middleware.New(api.GetListChirps(env)),
+		),
+	)
+
+	mux.Handle("PUT /api/chirps/{chirpID}/pin",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PutPinnedChirp(env)),
+		),
+	)
+
+	mux.Handle("DELETE /api/chirps/{chirpID}/pin",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.DeletePinnedChirp(env)),
+		),
+	)
+
+	mux.Handle("PUT /api/chirps/{chirpID}/like",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PutLike(env)),
+		),
+	)
+
+	mux.Handle("DELETE /api/chirps/{chirpID}/like",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.DeleteLike(env)),
+		),
+	)
+
+	mux.Handle("PUT /api/profile",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PutProfile(env)),
+		),
+	)
+
+	mux.Handle("GET /api/users/{idOrUsername}",
+		middleware.Chain(env,
+			middleware.OptionalAuthenticate,
+			middleware.New(api.GetProfile(env)),
+		),
+	)
+
+	mux.Handle("PUT /api/users/{userID}/follow",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PutFollow(env)),
+		),
+	)
+
+	mux.Handle("DELETE /api/users/{userID}/follow",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.DeleteFollow(env)),
+		),
+	)
+
+	mux.Handle("PUT /api/users/{userID}/block",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PutBlock(env)),
+		),
+	)
+
+	mux.Handle("DELETE /api/users/{userID}/block",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.DeleteBlock(env)),
+		),
+	)
+
+	mux.Handle("POST /api/conversations",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(dm.PostConversation(env)),
+		),
+	)
+
+	mux.Handle("GET /api/conversations",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(dm.GetConversations(env)),
+		),
+	)
+
+	mux.Handle("GET /api/conversations/{conversationID}/messages",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(dm.GetMessages(env)),
+		),
+	)
+
+	mux.Handle("POST /api/conversations/{conversationID}/messages",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(dm.PostMessage(env, messages)),
+		),
+	)
+
+	mux.Handle("POST /api/conversations/{conversationID}/read",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(dm.PostConversationRead(env)),
+		),
+	)
+
+	mux.Handle("GET /api/messages/poll",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(dm.PollMessages(env, messages)),
+		),
+	)
+
+	mux.Handle("GET /api/messages/settings",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(dm.GetSettings(env)),
+		),
+	)
+
+	mux.Handle("PUT /api/messages/settings",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(dm.PutSettings(env)),
+		),
+	)
+
+	mux.Handle("GET /api/trash",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.GetTrash(env)),
+		),
+	)
+
+	mux.Handle("POST /api/trash/{chirpID}/restore",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PostRestoreChirp(env)),
+		),
+	)
+
+	mux.Handle("DELETE /api/users/me",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.DeleteUser(env)),
+		),
+	)
+
+	mux.Handle("POST /api/users/me/export",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PostDataExport(env)),
+		),
+	)
+
+	mux.Handle("GET /api/exports/{token}", api.GetDataExportDownload(env))
+
+	mux.Handle("GET /api/moderation/chirps/{chirpID}",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.WithPrivileges(auth.IsModerator),
+			middleware.New(api.GetModerationChirp(env)),
+		),
+	)
+
+	mux.Handle("GET /api/moderation/users/{userID}/chirps",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.WithPrivileges(auth.IsModerator),
+			middleware.New(api.GetModerationUserChirps(env)),
+		),
+	)
+
+	mux.Handle("POST /api/chirps/{chirpID}/poll/votes",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PostPollVote(env)),
+		),
+	)
+
+	mux.Handle("GET /api/chirps",
+		middleware.Chain(env,
+			middleware.OptionalAuthenticate,
+			middleware.New(api.GetAllChirps(env)),
+		),
+	)
+
+	mux.Handle("GET /api/chirps/{chirpID}",
+		middleware.Chain(env,
+			middleware.OptionalAuthenticate,
+			middleware.New(api.GetOneChirpByID(env)),
+		),
+	)
+
+	mux.Handle("GET /api/trends", api.GetTrends(env, windows))
+
+	mux.Handle("GET /api/hashtags/{tag}/chirps",
+		middleware.Chain(env,
+			middleware.OptionalAuthenticate,
+			middleware.New(api.GetHashtagChirps(env)),
+		),
+	)
+
+	mux.Handle("GET /api/users/{userID}/mentions",
+		middleware.Chain(env,
+			middleware.OptionalAuthenticate,
+			middleware.New(api.GetUserMentions(env)),
+		),
+	)
+
+	mux.Handle("POST /api/login",
+		middleware.Chain(env,
+			middleware.RateLimit(limits, loginLimit),
+			middleware.New(api.Login(env)),
+		),
+	)
+
+	mux.Handle("POST /api/refresh", api.Refresh(env))
+	mux.Handle("POST /api/revoke", api.Revoke(env))
+
+	mux.Handle("POST /api/users",
+		middleware.Chain(env,
+			middleware.RateLimit(limits, signupLimit),
+			middleware.New(api.Signup(env)),
+		),
+	)
+
+	// Restoring checks a password too, so it shares the login buckets.
+	mux.Handle("POST /api/users/restore",
+		middleware.Chain(env,
+			middleware.RateLimit(limits, loginLimit),
+			middleware.New(api.PostRestoreUser(env)),
+		),
+	)
+
+	mux.Handle(
+		"PUT /api/users",
+		middleware.Chain(
+			env,
+			middleware.Authenticate,
+			middleware.New(api.PutUser(env)),
+		),
+	)
+
+	mux.Handle("GET /api/notifications",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.GetNotifications(env)),
+		),
+	)
+
+	mux.Handle("GET /api/notifications/unread_count",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.GetUnreadNotificationCount(env)),
+		),
+	)
+
+	mux.Handle("POST /api/notifications/read",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PostAllNotificationsRead(env)),
+		),
+	)
+
+	mux.Handle("POST /api/notifications/{notificationID}/read",
+		middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(api.PostNotificationRead(env)),
+		),
+	)
+
+	mux.Handle("POST /api/polka/webhooks", api.PostPolkaUpradeUser(env))
+
+	mux.Handle("GET /metrics", env.Metrics.Handler())
+
+	// ADMIN
+	mux.Handle("GET /admin/metrics", api.GetAdminMetrics(env))
+	mux.Handle("POST /admin/reset", api.PostAdminReset(env))
+
+	return mux
+}
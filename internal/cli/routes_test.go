@@ -0,0 +1,126 @@
+package cli_test
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/zyrterviews/chirpy/internal/openapi"
+)
+
+// undocumented lists the routes deliberately left out of the OpenAPI
+// document.
+var undocumented = []string{
+	"GET /app/",               // the web app's static files
+	"GET /app/media/{key...}", // uploaded media, linked from chirps
+}
+
+// registeredRoutes returns the pattern of every mux.Handle call in
+// routes.go.
+func registeredRoutes(t *testing.T) []string {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "routes.go", nil, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var patterns []string
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Handle" {
+			return true
+		}
+
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			t.Fatalf("mux.Handle pattern is not a string literal: %#v", call.Args[0])
+		}
+
+		pattern, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		patterns = append(patterns, pattern)
+
+		return true
+	})
+
+	return patterns
+}
+
+// documentedRoutes returns every operation of the OpenAPI document as a
+// "METHOD /path" pattern.
+func documentedRoutes(t *testing.T) []string {
+	t.Helper()
+
+	spec, err := openapi.Spec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var patterns []string
+
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			patterns = append(patterns, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	return patterns
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	t.Parallel()
+
+	registered := registeredRoutes(t)
+	documented := documentedRoutes(t)
+
+	if len(registered) == 0 {
+		t.Fatal("expected routes.go to register routes, found none")
+	}
+
+	t.Run("should document every registered route", func(t *testing.T) {
+		t.Parallel()
+
+		for _, pattern := range registered {
+			if slices.Contains(undocumented, pattern) {
+				continue
+			}
+
+			if !slices.Contains(documented, pattern) {
+				t.Errorf("%q is registered but missing from openapi.yaml", pattern)
+			}
+		}
+	})
+
+	t.Run("should only document registered routes", func(t *testing.T) {
+		t.Parallel()
+
+		for _, pattern := range documented {
+			if !slices.Contains(registered, pattern) {
+				t.Errorf("%q is in openapi.yaml but not registered", pattern)
+			}
+		}
+	})
+}
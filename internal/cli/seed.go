@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/zyrterviews/chirpy/internal/api"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+)
+
+const (
+	seedPassword = "password"
+	// seedHistory is how far back seeded chirps are spread.
+	seedHistory = 30 * 24 * time.Hour
+)
+
+var errSeedPlatform = errors.New("seed only runs with PLATFORM=dev")
+
+var seedBodies = []string{
+	"Just shipped a new feature #golang",
+	"Coffee first, code later #mondays",
+	"Anyone else watching the eclipse today? #space",
+	"Hello @%s, welcome aboard!",
+	"Reading about Postgres advisory locks #postgres",
+	"@%s that talk was great #conference",
+	"Rainy day, perfect for refactoring #golang",
+	"Who wants to pair on the trends job? @%s",
+}
+
+func newSeedCommand() *cobra.Command {
+	var users, chirps int
+
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Fill a development database with sample users and chirps",
+		Long: fmt.Sprintf(
+			"Create users seed1@example.com, seed2@example.com, ... with "+
+				"the password %q, and give each of them sample chirps "+
+				"spread over the last 30 days. Existing seed users are "+
+				"reused. Refuses to run unless PLATFORM=dev.",
+			seedPassword,
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return withEnv(cmd.Context(),
+				func(ctx context.Context, env *appenv.Env) error {
+					if env.Config.Platform != "dev" {
+						return errSeedPlatform
+					}
+
+					return seed(ctx, env, users, chirps)
+				},
+			)
+		},
+	}
+
+	cmd.Flags().IntVar(&users, "users", 10, "users to create")  //nolint:mnd
+	cmd.Flags().IntVar(&chirps, "chirps", 5, "chirps per user") //nolint:mnd
+
+	return cmd
+}
+
+func seed(ctx context.Context, env *appenv.Env, users, chirps int) error {
+	if users < 1 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, users)
+	usernames := make([]string, 0, users)
+
+	for i := 1; i <= users; i++ {
+		email := fmt.Sprintf("seed%d@example.com", i)
+		username := fmt.Sprintf("seed_%d", i)
+
+		user, err := env.DB.GetUserByEmail(ctx, email)
+		if errors.Is(err, sql.ErrNoRows) {
+			user, err = createUser(ctx, env, email, seedPassword, username)
+		}
+
+		if err != nil {
+			return fmt.Errorf("seed user %s: %w", email, err)
+		}
+
+		ids = append(ids, user.ID)
+		usernames = append(usernames, username)
+	}
+
+	now := time.Now()
+
+	for _, userID := range ids {
+		for range chirps {
+			//nolint:gosec
+			body := seedBodies[rand.IntN(len(seedBodies))]
+			//nolint:gosec
+			mentioned := usernames[rand.IntN(len(usernames))]
+			//nolint:gosec
+			createdAt := now.Add(-rand.N(seedHistory))
+
+			if strings.Contains(body, "%s") {
+				body = fmt.Sprintf(body, mentioned)
+			}
+
+			_, err := api.ImportChirp(ctx, env, userID, body, createdAt)
+			if err != nil {
+				return fmt.Errorf("seed chirp: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
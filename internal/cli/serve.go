@@ -0,0 +1,237 @@
+//nolint:exhaustruct
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zyrterviews/chirpy/internal/config"
+	"github.com/zyrterviews/chirpy/internal/dm"
+	"github.com/zyrterviews/chirpy/internal/export"
+	"github.com/zyrterviews/chirpy/internal/health"
+	"github.com/zyrterviews/chirpy/internal/purge"
+	"github.com/zyrterviews/chirpy/internal/ratelimit"
+	"github.com/zyrterviews/chirpy/internal/scheduler"
+	"github.com/zyrterviews/chirpy/internal/trends"
+	"github.com/zyrterviews/chirpy/internal/worker"
+)
+
+func newServeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Run the API server and background workers",
+		Long: "Run the API server and background workers until SIGINT or " +
+			"SIGTERM. Then /readyz fails for SHUTDOWN_DELAY, after which " +
+			"the server stops accepting requests and gives in-flight " +
+			"requests and workers up to SHUTDOWN_TIMEOUT to finish.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return serve(cmd.Context())
+		},
+	}
+}
+
+func serve(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	env, closeEnv, err := loadEnv(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeEnv()
+
+	migrator, err := newMigrator(env.SQL)
+	if err != nil {
+		return err
+	}
+
+	// AUTO_MIGRATE applies pending migrations before serving. The runner
+	// holds an advisory lock, so replicas starting together apply them once.
+	if env.Config.AutoMigrate {
+		if err := migrator.Up(ctx); err != nil {
+			return err
+		}
+	}
+
+	checker := &health.Checker{
+		Checks: []health.Check{
+			health.Database(env.SQL),
+			health.Migrations(migrator),
+		},
+	}
+
+	trendsWindows := env.Config.TrendsWindows
+	if trendsWindows == "" {
+		trendsWindows = trends.DefaultWindows
+	}
+
+	windows, err := trends.ParseWindows(trendsWindows)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", env.Config.Addr)
+	if err != nil {
+		return err
+	}
+
+	// Workers get their own context: they keep running while the server
+	// drains and are only stopped once it is done.
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var workers sync.WaitGroup
+
+	run := func(periodic *worker.Periodic) {
+		checker.Checks = append(checker.Checks, health.Worker(periodic))
+
+		workers.Add(1)
+
+		go func() {
+			defer workers.Done()
+
+			periodic.Run(workerCtx)
+		}()
+	}
+
+	aggregator := &trends.Aggregator{Env: env, Windows: windows}
+
+	run(&worker.Periodic{
+		Name:     "trends",
+		Interval: trends.RefreshInterval,
+		Job:      aggregator.Aggregate,
+	})
+
+	publisher := &scheduler.Publisher{Env: env}
+
+	run(&worker.Periodic{
+		Name:     "scheduler",
+		Interval: scheduler.PollInterval,
+		Job:      publisher.PublishDue,
+	})
+
+	purger := &purge.Purger{Env: env}
+
+	run(&worker.Periodic{
+		Name:     "purge",
+		Interval: purge.Interval,
+		Job:      purger.Purge,
+	})
+
+	exporter := &export.Builder{Env: env}
+
+	run(&worker.Periodic{
+		Name:     "export",
+		Interval: export.PollInterval,
+		Job:      exporter.BuildPending,
+	})
+
+	var limits ratelimit.Store = ratelimit.NewMemoryStore()
+
+	if env.Config.RateLimit.Store == config.RateLimitPostgres {
+		shared := &ratelimit.PostgresStore{Env: env}
+		limits = shared
+
+		run(&worker.Periodic{
+			Name:     "rate_limits",
+			Interval: ratelimit.SweepInterval,
+			Job:      shared.Sweep,
+		})
+	}
+
+	messages := dm.NewHub()
+	env.Metrics.TrackLongPolls(messages.Waiting)
+
+	server := &http.Server{
+		Handler:           Handler(env, messages, checker, limits, windows),
+		ReadHeaderTimeout: env.Config.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       env.Config.HTTP.ReadTimeout,
+		WriteTimeout:      env.Config.HTTP.WriteTimeout,
+		IdleTimeout:       env.Config.HTTP.IdleTimeout,
+	}
+
+	server.RegisterOnShutdown(messages.Close)
+
+	served := make(chan error, 1)
+
+	go func() {
+		served <- server.Serve(listener)
+	}()
+
+	env.Logger.Info("serve: listening", "addr", listener.Addr().String())
+
+	select {
+	case err := <-served:
+		stopWorkers()
+		workers.Wait()
+
+		return fmt.Errorf("serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	// Fail readiness first and give load balancers SHUTDOWN_DELAY to stop
+	// sending traffic, while requests are still being accepted.
+	checker.ShutDown()
+
+	if delay := env.Config.HTTP.ShutdownDelay; delay > 0 {
+		env.Logger.Info(
+			"serve: not ready, waiting before shutting down",
+			"delay_ms", delay.Milliseconds(),
+		)
+		time.Sleep(delay)
+	}
+
+	env.Logger.Info(
+		"serve: shutting down, waiting for requests and workers",
+		"timeout_ms", env.Config.HTTP.ShutdownTimeout.Milliseconds(),
+	)
+
+	shutdownCtx, cancel := context.WithTimeout(
+		context.Background(),
+		env.Config.HTTP.ShutdownTimeout,
+	)
+	defer cancel()
+
+	return drain(shutdownCtx, server, stopWorkers, &workers)
+}
+
+// drain stops the server, then the workers, within the deadline of ctx.
+// It returns ErrUncleanShutdown if either is still busy when time runs out.
+func drain(
+	ctx context.Context,
+	server *http.Server,
+	stopWorkers context.CancelFunc,
+	workers *sync.WaitGroup,
+) error {
+	serverErr := server.Shutdown(ctx)
+
+	stopWorkers()
+
+	if errors.Is(serverErr, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: requests still in flight", ErrUncleanShutdown)
+	}
+
+	stopped := make(chan struct{})
+
+	go func() {
+		workers.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return serverErr
+	case <-ctx.Done():
+		return fmt.Errorf("%w: workers still running", ErrUncleanShutdown)
+	}
+}
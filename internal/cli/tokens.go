@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+)
+
+var errRevokeUsage = errors.New("give exactly one of TOKEN or --user")
+
+func newTokensCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tokens",
+		Short: "Manage refresh tokens",
+	}
+
+	cmd.AddCommand(newTokensRevokeCommand())
+
+	return cmd
+}
+
+func newTokensRevokeCommand() *cobra.Command {
+	var email string
+
+	cmd := &cobra.Command{
+		Use:   "revoke [TOKEN]",
+		Short: "Revoke one refresh token, or every token of a user",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (len(args) == 1) == (email != "") {
+				return errRevokeUsage
+			}
+
+			return withEnv(cmd.Context(),
+				func(ctx context.Context, env *appenv.Env) error {
+					if email == "" {
+						return env.DB.RevokeRefreshToken(ctx, args[0])
+					}
+
+					user, err := env.DB.GetUserByEmail(ctx, email)
+					if err != nil {
+						return userLookupError(email, err)
+					}
+
+					return env.DB.RevokeRefreshTokensForUser(ctx, user.ID)
+				},
+			)
+		},
+	}
+
+	cmd.Flags().StringVar(&email, "user", "", "revoke every token of the user with this email")
+
+	return cmd
+}
@@ -0,0 +1,299 @@
+//nolint:exhaustruct
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/chirptext"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/pagination"
+)
+
+// generatedPasswordBytes is the entropy of passwords made up by
+// `users reset-password` when none is given.
+const generatedPasswordBytes = 12
+
+var errInvalidUsername = errors.New(
+	"username must be 3 to 30 letters, digits or underscores",
+)
+
+func newUsersCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "users",
+		Short: "Manage user accounts",
+	}
+
+	cmd.AddCommand(
+		newUsersCreateCommand(),
+		newUsersListCommand(),
+		newUsersPromoteCommand(),
+		newUsersResetPasswordCommand(),
+	)
+
+	return cmd
+}
+
+func newUsersCreateCommand() *cobra.Command {
+	var (
+		email     string
+		password  string
+		username  string
+		moderator bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a user account",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return withEnv(cmd.Context(),
+				func(ctx context.Context, env *appenv.Env) error {
+					user, err := createUser(ctx, env, email, password, username)
+					if err != nil {
+						return err
+					}
+
+					if moderator {
+						user, err = env.DB.SetUserModerator(ctx,
+							database.SetUserModeratorParams{
+								IsModerator: true,
+								ID:          user.ID,
+							},
+						)
+						if err != nil {
+							return err
+						}
+					}
+
+					fmt.Fprintln(cmd.OutOrStdout(), user.ID)
+
+					return nil
+				},
+			)
+		},
+	}
+
+	cmd.Flags().StringVar(&email, "email", "", "email address (required)")
+	cmd.Flags().StringVar(&password, "password", "", "password (required)")
+	cmd.Flags().StringVar(&username, "username", "", "optional @username")
+	cmd.Flags().BoolVar(&moderator, "moderator", false, "grant moderator rights")
+	_ = cmd.MarkFlagRequired("email")
+	_ = cmd.MarkFlagRequired("password")
+
+	return cmd
+}
+
+// createUser registers a user the same way POST /api/users and
+// PUT /api/users do, so that accounts made from the command line are
+// indistinguishable from the ones made through the API.
+func createUser(
+	ctx context.Context,
+	env *appenv.Env,
+	email, password, username string,
+) (database.User, error) {
+	if username != "" && !chirptext.IsValidUsername(username) {
+		return database.User{}, errInvalidUsername
+	}
+
+	hashedPwd, err := auth.HashPassword(password)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	var user database.User
+
+	err = env.InTx(ctx, func(qtx *database.Queries) error {
+		var err error
+
+		user, err = qtx.CreateUser(ctx, database.CreateUserParams{
+			Email:          email,
+			HashedPassword: hashedPwd,
+		})
+		if err != nil || username == "" {
+			return err
+		}
+
+		user, err = qtx.UpdateUser(ctx, database.UpdateUserParams{
+			ID:             user.ID,
+			Email:          user.Email,
+			HashedPassword: user.HashedPassword,
+			Username: sql.NullString{
+				String: chirptext.NormalizeUsername(username),
+				Valid:  true,
+			},
+		})
+
+		return err
+	})
+
+	return user, err
+}
+
+func newUsersListCommand() *cobra.Command {
+	var limit, offset int32
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List user accounts, oldest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return withEnv(cmd.Context(),
+				func(ctx context.Context, env *appenv.Env) error {
+					users, err := env.DB.ListUsers(ctx, database.ListUsersParams{
+						RowLimit:  limit,
+						RowOffset: offset,
+					})
+					if err != nil {
+						return err
+					}
+
+					out := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0) //nolint:mnd
+					fmt.Fprintln(out, "ID\tEMAIL\tUSERNAME\tMODERATOR\tRED\tCREATED")
+
+					for _, user := range users {
+						fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\t%s\n",
+							user.ID,
+							user.Email,
+							user.Username.String,
+							strconv.FormatBool(user.IsModerator),
+							strconv.FormatBool(user.IsChirpyRed),
+							user.CreatedAt.Format(time.DateTime),
+						)
+					}
+
+					return out.Flush()
+				},
+			)
+		},
+	}
+
+	cmd.Flags().Int32Var(&limit, "limit", pagination.DefaultLimit, "users to list")
+	cmd.Flags().Int32Var(&offset, "offset", 0, "users to skip")
+
+	return cmd
+}
+
+func newUsersPromoteCommand() *cobra.Command {
+	var demote bool
+
+	cmd := &cobra.Command{
+		Use:   "promote EMAIL",
+		Short: "Grant a user moderator rights",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEnv(cmd.Context(),
+				func(ctx context.Context, env *appenv.Env) error {
+					user, err := env.DB.GetUserByEmail(ctx, args[0])
+					if err != nil {
+						return userLookupError(args[0], err)
+					}
+
+					_, err = env.DB.SetUserModerator(ctx,
+						database.SetUserModeratorParams{
+							IsModerator: !demote,
+							ID:          user.ID,
+						},
+					)
+
+					return err
+				},
+			)
+		},
+	}
+
+	cmd.Flags().BoolVar(&demote, "demote", false, "revoke moderator rights instead")
+
+	return cmd
+}
+
+func newUsersResetPasswordCommand() *cobra.Command {
+	var password string
+
+	cmd := &cobra.Command{
+		Use:   "reset-password EMAIL",
+		Short: "Set a new password and sign the user out everywhere",
+		Long: "Set a new password and revoke every refresh token of the " +
+			"user. A random password is generated and printed unless " +
+			"--password is given.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withEnv(cmd.Context(),
+				func(ctx context.Context, env *appenv.Env) error {
+					user, err := env.DB.GetUserByEmail(ctx, args[0])
+					if err != nil {
+						return userLookupError(args[0], err)
+					}
+
+					newPassword := password
+					if newPassword == "" {
+						newPassword, err = generatePassword()
+						if err != nil {
+							return err
+						}
+					}
+
+					hashedPwd, err := auth.HashPassword(newPassword)
+					if err != nil {
+						return err
+					}
+
+					err = env.InTx(ctx, func(qtx *database.Queries) error {
+						err := qtx.UpdateUserPassword(ctx,
+							database.UpdateUserPasswordParams{
+								HashedPassword: hashedPwd,
+								ID:             user.ID,
+							},
+						)
+						if err != nil {
+							return err
+						}
+
+						return qtx.RevokeRefreshTokensForUser(ctx, user.ID)
+					})
+					if err != nil {
+						return err
+					}
+
+					if password == "" {
+						fmt.Fprintln(cmd.OutOrStdout(), newPassword)
+					}
+
+					return nil
+				},
+			)
+		},
+	}
+
+	cmd.Flags().StringVar(&password, "password", "", "new password")
+
+	return cmd
+}
+
+func generatePassword() (string, error) {
+	buf := make([]byte, generatedPasswordBytes)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func userLookupError(email string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no user with email %q", email) //nolint:err113
+	}
+
+	return err
+}
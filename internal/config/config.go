@@ -0,0 +1,619 @@
+// Package config loads chirpy's settings from defaults, an optional YAML file,
+// a .env file and environment variables into one typed struct, and checks
+// them before anything starts.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/zyrterviews/chirpy/internal/blob"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	StoreLocal = "local"
+	StoreS3    = "s3"
+
+	TracingNone   = "none"
+	TracingStdout = "stdout"
+	TracingOTLP   = "otlp"
+
+	RateLimitMemory   = "memory"
+	RateLimitPostgres = "postgres"
+
+	redacted = "[redacted]"
+)
+
+// Secret is a string that never prints its value. Use Reveal where the real
+// value is needed.
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+func (s Secret) MarshalYAML() (any, error) {
+	return s.String(), nil
+}
+
+type Config struct {
+	// Platform is "dev" on development machines, which unlocks
+	// POST /admin/reset and `chirpy seed`.
+	Platform string `yaml:"platform"`
+	Addr     string `yaml:"addr"`
+	// LogLevel is debug, info, warn or error.
+	LogLevel string `yaml:"log_level"`
+	// DatabaseURL is a secret because it usually embeds a password.
+	DatabaseURL     Secret        `yaml:"database_url"`
+	JWTSecret       Secret        `yaml:"jwt_secret"`
+	PolkaKey        Secret        `yaml:"polka_key"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+	AutoMigrate     bool          `yaml:"auto_migrate"`
+	// TrendsWindows overrides trends.DefaultWindows when set.
+	TrendsWindows string    `yaml:"trends_windows"`
+	Media         Store     `yaml:"media"`
+	Exports       Store     `yaml:"exports"`
+	S3            S3        `yaml:"s3"`
+	HTTP          HTTP      `yaml:"http"`
+	DB            DB        `yaml:"db"`
+	Tracing       Tracing   `yaml:"tracing"`
+	RateLimit     RateLimit `yaml:"rate_limit"`
+	CORS          CORS      `yaml:"cors"`
+}
+
+// HTTP holds the server timeouts.
+type HTTP struct {
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	// WriteTimeout must stay above the 25s direct message long poll.
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+	// ShutdownTimeout bounds how long in-flight requests and background
+	// workers get to finish after SIGINT or SIGTERM.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// ShutdownDelay is how long /readyz fails before the server stops
+	// accepting connections, giving load balancers time to notice.
+	ShutdownDelay time.Duration `yaml:"shutdown_delay"`
+	// TrustedProxies lists, comma-separated, the IPs or CIDR ranges of the
+	// reverse proxies in front of chirpy. Their X-Forwarded-For header is
+	// believed when working out the client IP.
+	TrustedProxies string `yaml:"trusted_proxies"`
+	// HSTSMaxAge is how long browsers should only reach chirpy over HTTPS
+	// once they have seen it there. Zero leaves the header out.
+	HSTSMaxAge time.Duration `yaml:"hsts_max_age"`
+}
+
+// DB tunes the connection pool.
+type DB struct {
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+	// ConnectAttempts is how many times the database is pinged on startup
+	// before giving up.
+	ConnectAttempts int `yaml:"connect_attempts"`
+}
+
+// Tracing says where OpenTelemetry spans are sent.
+type Tracing struct {
+	// Exporter is TracingNone, TracingStdout or TracingOTLP.
+	Exporter string `yaml:"exporter"`
+	// OTLPEndpoint is the OTLP/HTTP collector URL, such as
+	// http://localhost:4318. The exporter's default is used when empty.
+	OTLPEndpoint string `yaml:"otlp_endpoint"`
+}
+
+// RateLimit says where rate limit buckets are kept.
+type RateLimit struct {
+	// Store is RateLimitMemory, which gives each replica its own limits, or
+	// RateLimitPostgres, which shares them between replicas.
+	Store string `yaml:"store"`
+}
+
+// CORS says which web pages served from other origins may call the API.
+type CORS struct {
+	// AllowedOrigins lists, comma-separated, the origins allowed to call the
+	// API, such as https://chirpy.example.com, or * for any. None by
+	// default, which keeps the API same-origin.
+	AllowedOrigins string `yaml:"allowed_origins"`
+	// AllowCredentials lets those pages send cookies along. It cannot be
+	// combined with *.
+	AllowCredentials bool `yaml:"allow_credentials"`
+	// MaxAge is how long browsers may cache the answer to a preflight
+	// request.
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+// Store says where one kind of blob is kept.
+type Store struct {
+	// Kind is StoreLocal or StoreS3.
+	Kind      string `yaml:"store"`
+	Dir       string `yaml:"dir"`
+	Bucket    string `yaml:"bucket"`
+	PublicURL string `yaml:"public_url"`
+}
+
+// S3 holds the connection settings shared by every S3-backed Store.
+type S3 struct {
+	Endpoint        string `yaml:"endpoint"`
+	Region          string `yaml:"region"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey Secret `yaml:"secret_access_key"`
+}
+
+func Default() *Config {
+	return &Config{
+		Addr:            ":8080",
+		LogLevel:        "info",
+		AccessTokenTTL:  1 * time.Hour,
+		RefreshTokenTTL: 60 * 24 * time.Hour, // 60 days
+		Media: Store{
+			Kind: StoreLocal,
+			Dir:  blob.DefaultLocalRoot,
+		},
+		// Archives hold personal data, so the local default lives outside
+		// the directory served under /app/.
+		Exports: Store{
+			Kind: StoreLocal,
+			Dir:  filepath.Join(os.TempDir(), "chirpy-exports"),
+		},
+		HTTP: HTTP{
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+			HSTSMaxAge:        365 * 24 * time.Hour,
+		},
+		DB: DB{
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			ConnectAttempts: 10,
+		},
+		Tracing: Tracing{
+			Exporter: TracingNone,
+		},
+		RateLimit: RateLimit{
+			Store: RateLimitMemory,
+		},
+		CORS: CORS{
+			MaxAge: 10 * time.Minute,
+		},
+	}
+}
+
+// Load reads .env into the environment, then builds the configuration with
+// LoadWith. An empty path falls back to CONFIG_FILE, and no file is read if
+// that is empty too.
+func Load(path string) (*Config, error) {
+	_ = godotenv.Load()
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+
+	return LoadWith(path, os.Getenv)
+}
+
+// LoadWith builds the configuration from the defaults, the YAML file at path
+// when it is not empty, and the variables returned by getenv, each
+// overriding the previous one. Every problem found is reported at once.
+func LoadWith(path string, getenv func(string) string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := errors.Join(cfg.readEnv(getenv), cfg.Validate()); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (cfg *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (cfg *Config) readEnv(getenv func(string) string) error {
+	env := envReader{getenv: getenv}
+
+	env.str(&cfg.Platform, "PLATFORM")
+	env.str(&cfg.Addr, "ADDR")
+	env.str(&cfg.LogLevel, "LOG_LEVEL")
+	env.secret(&cfg.DatabaseURL, "DB_URL")
+	env.secret(&cfg.JWTSecret, "JWT_SECRET")
+	env.secret(&cfg.PolkaKey, "POLKA_KEY")
+	env.duration(&cfg.AccessTokenTTL, "ACCESS_TOKEN_TTL")
+	env.duration(&cfg.RefreshTokenTTL, "REFRESH_TOKEN_TTL")
+	env.boolean(&cfg.AutoMigrate, "AUTO_MIGRATE")
+	env.str(&cfg.TrendsWindows, "TRENDS_WINDOWS")
+
+	env.str(&cfg.Media.Kind, "MEDIA_STORE")
+	env.str(&cfg.Media.Dir, "MEDIA_DIR")
+	env.str(&cfg.Media.Bucket, "S3_BUCKET")
+	env.str(&cfg.Media.PublicURL, "S3_PUBLIC_URL")
+
+	env.str(&cfg.Exports.Kind, "EXPORT_STORE")
+	env.str(&cfg.Exports.Dir, "EXPORT_DIR")
+	env.str(&cfg.Exports.Bucket, "S3_EXPORT_BUCKET")
+
+	env.str(&cfg.S3.Endpoint, "S3_ENDPOINT")
+	env.str(&cfg.S3.Region, "S3_REGION")
+	env.str(&cfg.S3.AccessKeyID, "S3_ACCESS_KEY_ID")
+	env.secret(&cfg.S3.SecretAccessKey, "S3_SECRET_ACCESS_KEY")
+
+	env.duration(&cfg.HTTP.ReadHeaderTimeout, "READ_HEADER_TIMEOUT")
+	env.duration(&cfg.HTTP.ReadTimeout, "READ_TIMEOUT")
+	env.duration(&cfg.HTTP.WriteTimeout, "WRITE_TIMEOUT")
+	env.duration(&cfg.HTTP.IdleTimeout, "IDLE_TIMEOUT")
+	env.duration(&cfg.HTTP.ShutdownTimeout, "SHUTDOWN_TIMEOUT")
+	env.duration(&cfg.HTTP.ShutdownDelay, "SHUTDOWN_DELAY")
+	env.str(&cfg.HTTP.TrustedProxies, "TRUSTED_PROXIES")
+	env.duration(&cfg.HTTP.HSTSMaxAge, "HSTS_MAX_AGE")
+
+	env.integer(&cfg.DB.MaxOpenConns, "DB_MAX_OPEN_CONNS")
+	env.integer(&cfg.DB.MaxIdleConns, "DB_MAX_IDLE_CONNS")
+	env.duration(&cfg.DB.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME")
+	env.duration(&cfg.DB.ConnMaxIdleTime, "DB_CONN_MAX_IDLE_TIME")
+	env.integer(&cfg.DB.ConnectAttempts, "DB_CONNECT_ATTEMPTS")
+
+	env.str(&cfg.Tracing.Exporter, "TRACING_EXPORTER")
+	env.str(&cfg.Tracing.OTLPEndpoint, "OTEL_EXPORTER_OTLP_ENDPOINT")
+
+	env.str(&cfg.RateLimit.Store, "RATE_LIMIT_STORE")
+
+	env.str(&cfg.CORS.AllowedOrigins, "CORS_ALLOWED_ORIGINS")
+	env.boolean(&cfg.CORS.AllowCredentials, "CORS_ALLOW_CREDENTIALS")
+	env.duration(&cfg.CORS.MaxAge, "CORS_MAX_AGE")
+
+	return errors.Join(env.errs...)
+}
+
+// Validate checks that required settings are present and well formed.
+func (cfg *Config) Validate() error {
+	var errs []error
+
+	if cfg.DatabaseURL == "" {
+		errs = append(errs, errors.New("DB_URL is required"))
+	} else if dbURL, err := url.Parse(cfg.DatabaseURL.Reveal()); err != nil ||
+		(dbURL.Scheme != "postgres" && dbURL.Scheme != "postgresql") {
+		errs = append(errs, errors.New(
+			"DB_URL must be a postgres:// or postgresql:// URL",
+		))
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		errs = append(errs, fmt.Errorf(
+			"LOG_LEVEL %q must be debug, info, warn or error", cfg.LogLevel,
+		))
+	}
+
+	if cfg.JWTSecret == "" {
+		errs = append(errs, errors.New("JWT_SECRET is required"))
+	}
+
+	if _, _, err := net.SplitHostPort(cfg.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("ADDR %q must be host:port", cfg.Addr))
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"ACCESS_TOKEN_TTL", cfg.AccessTokenTTL},
+		{"REFRESH_TOKEN_TTL", cfg.RefreshTokenTTL},
+		{"READ_HEADER_TIMEOUT", cfg.HTTP.ReadHeaderTimeout},
+		{"READ_TIMEOUT", cfg.HTTP.ReadTimeout},
+		{"WRITE_TIMEOUT", cfg.HTTP.WriteTimeout},
+		{"IDLE_TIMEOUT", cfg.HTTP.IdleTimeout},
+		{"SHUTDOWN_TIMEOUT", cfg.HTTP.ShutdownTimeout},
+		{"DB_CONN_MAX_LIFETIME", cfg.DB.ConnMaxLifetime},
+		{"DB_CONN_MAX_IDLE_TIME", cfg.DB.ConnMaxIdleTime},
+	} {
+		if setting.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", setting.name))
+		}
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"SHUTDOWN_DELAY", cfg.HTTP.ShutdownDelay},
+		{"HSTS_MAX_AGE", cfg.HTTP.HSTSMaxAge},
+		{"CORS_MAX_AGE", cfg.CORS.MaxAge},
+	} {
+		if setting.value < 0 {
+			errs = append(errs, fmt.Errorf(
+				"%s must not be negative", setting.name,
+			))
+		}
+	}
+
+	if _, err := ParseProxies(cfg.HTTP.TrustedProxies); err != nil {
+		errs = append(errs, fmt.Errorf("TRUSTED_PROXIES: %w", err))
+	}
+
+	if origins, err := ParseOrigins(cfg.CORS.AllowedOrigins); err != nil {
+		errs = append(errs, fmt.Errorf("CORS_ALLOWED_ORIGINS: %w", err))
+	} else if cfg.CORS.AllowCredentials && slices.Contains(origins, "*") {
+		errs = append(errs, errors.New(
+			"CORS_ALLOW_CREDENTIALS cannot be combined with * in "+
+				"CORS_ALLOWED_ORIGINS",
+		))
+	}
+
+	if cfg.DB.MaxOpenConns < 1 {
+		errs = append(errs, errors.New("DB_MAX_OPEN_CONNS must be at least 1"))
+	}
+
+	if cfg.DB.MaxIdleConns < 0 || cfg.DB.MaxIdleConns > cfg.DB.MaxOpenConns {
+		errs = append(errs, errors.New(
+			"DB_MAX_IDLE_CONNS must be between 0 and DB_MAX_OPEN_CONNS",
+		))
+	}
+
+	if cfg.DB.ConnectAttempts < 1 {
+		errs = append(errs, errors.New("DB_CONNECT_ATTEMPTS must be at least 1"))
+	}
+
+	switch cfg.Tracing.Exporter {
+	case TracingNone, TracingStdout, TracingOTLP:
+	default:
+		errs = append(errs, fmt.Errorf(
+			"TRACING_EXPORTER %q must be %q, %q or %q",
+			cfg.Tracing.Exporter,
+			TracingNone,
+			TracingStdout,
+			TracingOTLP,
+		))
+	}
+
+	switch cfg.RateLimit.Store {
+	case RateLimitMemory, RateLimitPostgres:
+	default:
+		errs = append(errs, fmt.Errorf(
+			"RATE_LIMIT_STORE %q must be %q or %q",
+			cfg.RateLimit.Store,
+			RateLimitMemory,
+			RateLimitPostgres,
+		))
+	}
+
+	errs = append(errs, cfg.validateStore("MEDIA_STORE", "S3_BUCKET", cfg.Media))
+	errs = append(errs,
+		cfg.validateStore("EXPORT_STORE", "S3_EXPORT_BUCKET", cfg.Exports),
+	)
+
+	return errors.Join(errs...)
+}
+
+func (cfg *Config) validateStore(kindVar, bucketVar string, store Store) error {
+	switch store.Kind {
+	case StoreLocal:
+		return nil
+	case StoreS3:
+		var errs []error
+
+		required := []struct{ name, value string }{
+			{"S3_ENDPOINT", cfg.S3.Endpoint},
+			{"S3_REGION", cfg.S3.Region},
+			{"S3_ACCESS_KEY_ID", cfg.S3.AccessKeyID},
+			{"S3_SECRET_ACCESS_KEY", cfg.S3.SecretAccessKey.Reveal()},
+			{bucketVar, store.Bucket},
+		}
+
+		for _, setting := range required {
+			if setting.value == "" {
+				errs = append(errs, fmt.Errorf(
+					"%s is required when %s is s3",
+					setting.name,
+					kindVar,
+				))
+			}
+		}
+
+		return errors.Join(errs...)
+	default:
+		return fmt.Errorf(
+			"%s must be %q or %q, not %q",
+			kindVar,
+			StoreLocal,
+			StoreS3,
+			store.Kind,
+		)
+	}
+}
+
+// ParseProxies parses a comma-separated list of IPs and CIDR ranges, such
+// as "10.0.0.0/8, 192.0.2.1". Single IPs become one-address ranges.
+func ParseProxies(list string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		if prefix, err := netip.ParsePrefix(item); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+
+			continue
+		}
+
+		addr, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP or CIDR range", item)
+		}
+
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return prefixes, nil
+}
+
+// ParseOrigins parses a comma-separated list of origins, such as
+// "https://chirpy.example.com, http://localhost:3000", into the lowercase
+// form browsers send in the Origin header. "*" stands for any origin.
+func ParseOrigins(list string) ([]string, error) {
+	var origins []string
+
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		if item == "*" {
+			origins = append(origins, item)
+
+			continue
+		}
+
+		origin, err := url.Parse(item)
+		if err != nil ||
+			(origin.Scheme != "http" && origin.Scheme != "https") ||
+			origin.Host == "" ||
+			origin.User != nil ||
+			(origin.Path != "" && origin.Path != "/") ||
+			origin.RawQuery != "" ||
+			origin.Fragment != "" {
+			return nil, fmt.Errorf(
+				"%q is not an origin like https://chirpy.example.com", item,
+			)
+		}
+
+		origins = append(
+			origins,
+			strings.ToLower(origin.Scheme+"://"+origin.Host),
+		)
+	}
+
+	return origins, nil
+}
+
+// String renders the configuration as YAML with every secret redacted.
+func (cfg *Config) String() string {
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err.Error()
+	}
+
+	return string(out)
+}
+
+// envReader copies set environment variables over config fields and
+// collects parse errors instead of stopping at the first one.
+type envReader struct {
+	getenv func(string) string
+	errs   []error
+}
+
+func (r *envReader) str(dst *string, name string) {
+	if value := r.getenv(name); value != "" {
+		*dst = value
+	}
+}
+
+func (r *envReader) secret(dst *Secret, name string) {
+	if value := r.getenv(name); value != "" {
+		*dst = Secret(value)
+	}
+}
+
+func (r *envReader) duration(dst *time.Duration, name string) {
+	value := r.getenv(name)
+	if value == "" {
+		return
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf(
+			"%s %q is not a duration like 1h or 720h", name, value,
+		))
+
+		return
+	}
+
+	*dst = parsed
+}
+
+func (r *envReader) integer(dst *int, name string) {
+	value := r.getenv(name)
+	if value == "" {
+		return
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf(
+			"%s %q must be a whole number", name, value,
+		))
+
+		return
+	}
+
+	*dst = parsed
+}
+
+func (r *envReader) boolean(dst *bool, name string) {
+	value := r.getenv(name)
+	if value == "" {
+		return
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf(
+			"%s %q must be true or false", name, value,
+		))
+
+		return
+	}
+
+	*dst = parsed
+}
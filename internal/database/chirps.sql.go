@@ -7,26 +7,37 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 const createChirp = `-- name: CreateChirp :one
 INSERT INTO
-    chirps (body, user_id)
+    chirps (body, user_id, status, publish_at, reply_to_id)
 VALUES
-    ($1, $2)
+    ($1, $2, $3, $4, $5)
 RETURNING
-    id, created_at, updated_at, body, user_id
+    id, created_at, updated_at, body, user_id, status, publish_at, deleted_at, reply_to_id
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+	Body      string
+	UserID    uuid.UUID
+	Status    string
+	PublishAt sql.NullTime
+	ReplyToID uuid.NullUUID
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.Status,
+		arg.PublishAt,
+		arg.ReplyToID,
+	)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -34,27 +45,47 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.Status,
+		&i.PublishAt,
+		&i.DeletedAt,
+		&i.ReplyToID,
 	)
 	return i, err
 }
 
-const deleteChirpByID = `-- name: DeleteChirpByID :exec
-DELETE FROM
+const deleteUnpublishedChirp = `-- name: DeleteUnpublishedChirp :execrows
+UPDATE
     chirps
+SET
+    deleted_at = (NOW() AT TIME ZONE 'utc')
 WHERE
     id = $1
+    AND user_id = $2
+    AND status <> 'published'
+    AND deleted_at IS NULL
 `
 
-func (q *Queries) DeleteChirpByID(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteChirpByID, id)
-	return err
+type DeleteUnpublishedChirpParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteUnpublishedChirp(ctx context.Context, arg DeleteUnpublishedChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUnpublishedChirp, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const getAllChirps = `-- name: GetAllChirps :many
 SELECT
-    id, created_at, updated_at, body, user_id
+    id, created_at, updated_at, body, user_id, status, publish_at, deleted_at, reply_to_id
 FROM
     chirps
+WHERE
+    status = 'published'
+    AND deleted_at IS NULL
 ORDER BY
     CASE
         WHEN $1 LIKE 'asc' THEN created_at
@@ -83,6 +114,10 @@ func (q *Queries) GetAllChirps(ctx context.Context, dollar_1 interface{}) ([]Chi
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.Status,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ReplyToID,
 		); err != nil {
 			return nil, err
 		}
@@ -99,11 +134,13 @@ func (q *Queries) GetAllChirps(ctx context.Context, dollar_1 interface{}) ([]Chi
 
 const getAllChirpsForUser = `-- name: GetAllChirpsForUser :many
 SELECT
-    id, created_at, updated_at, body, user_id
+    id, created_at, updated_at, body, user_id, status, publish_at, deleted_at, reply_to_id
 FROM
     chirps
 WHERE
     user_id = $1
+    AND status = 'published'
+    AND deleted_at IS NULL
 ORDER BY
     CASE
         WHEN $2 LIKE 'asc' THEN created_at
@@ -137,6 +174,64 @@ func (q *Queries) GetAllChirpsForUser(ctx context.Context, arg GetAllChirpsForUs
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.Status,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ReplyToID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllChirpsForUserIncludingDeleted = `-- name: GetAllChirpsForUserIncludingDeleted :many
+SELECT
+    id, created_at, updated_at, body, user_id, status, publish_at, deleted_at, reply_to_id
+FROM
+    chirps
+WHERE
+    user_id = $1
+ORDER BY
+    created_at DESC
+LIMIT
+    $2
+OFFSET
+    $3
+`
+
+type GetAllChirpsForUserIncludingDeletedParams struct {
+	UserID    uuid.UUID
+	RowLimit  int32
+	RowOffset int32
+}
+
+func (q *Queries) GetAllChirpsForUserIncludingDeleted(ctx context.Context, arg GetAllChirpsForUserIncludingDeletedParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getAllChirpsForUserIncludingDeleted, arg.UserID, arg.RowLimit, arg.RowOffset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.Status,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ReplyToID,
 		); err != nil {
 			return nil, err
 		}
@@ -153,11 +248,12 @@ func (q *Queries) GetAllChirpsForUser(ctx context.Context, arg GetAllChirpsForUs
 
 const getChirpByID = `-- name: GetChirpByID :one
 SELECT
-    id, created_at, updated_at, body, user_id
+    id, created_at, updated_at, body, user_id, status, publish_at, deleted_at, reply_to_id
 FROM
     chirps
 WHERE
     id = $1
+    AND deleted_at IS NULL
 `
 
 func (q *Queries) GetChirpByID(ctx context.Context, id uuid.UUID) (Chirp, error) {
@@ -169,6 +265,440 @@ func (q *Queries) GetChirpByID(ctx context.Context, id uuid.UUID) (Chirp, error)
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.Status,
+		&i.PublishAt,
+		&i.DeletedAt,
+		&i.ReplyToID,
+	)
+	return i, err
+}
+
+const getChirpByIDIncludingDeleted = `-- name: GetChirpByIDIncludingDeleted :one
+SELECT
+    id, created_at, updated_at, body, user_id, status, publish_at, deleted_at, reply_to_id
+FROM
+    chirps
+WHERE
+    id = $1
+`
+
+func (q *Queries) GetChirpByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpByIDIncludingDeleted, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.Status,
+		&i.PublishAt,
+		&i.DeletedAt,
+		&i.ReplyToID,
+	)
+	return i, err
+}
+
+const getDeletedChirpsForUser = `-- name: GetDeletedChirpsForUser :many
+SELECT
+    id, created_at, updated_at, body, user_id, status, publish_at, deleted_at, reply_to_id
+FROM
+    chirps
+WHERE
+    user_id = $1
+    AND deleted_at >= $2::TIMESTAMPTZ
+ORDER BY
+    deleted_at DESC
+LIMIT
+    $3
+OFFSET
+    $4
+`
+
+type GetDeletedChirpsForUserParams struct {
+	UserID       uuid.UUID
+	DeletedSince time.Time
+	RowLimit     int32
+	RowOffset    int32
+}
+
+func (q *Queries) GetDeletedChirpsForUser(ctx context.Context, arg GetDeletedChirpsForUserParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getDeletedChirpsForUser,
+		arg.UserID,
+		arg.DeletedSince,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.Status,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ReplyToID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnpublishedChirpsForUser = `-- name: GetUnpublishedChirpsForUser :many
+SELECT
+    id, created_at, updated_at, body, user_id, status, publish_at, deleted_at, reply_to_id
+FROM
+    chirps
+WHERE
+    user_id = $1
+    AND status <> 'published'
+    AND deleted_at IS NULL
+ORDER BY
+    publish_at ASC NULLS LAST,
+    created_at ASC
+`
+
+func (q *Queries) GetUnpublishedChirpsForUser(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getUnpublishedChirpsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.Status,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ReplyToID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const importChirp = `-- name: ImportChirp :one
+INSERT INTO
+    chirps (body, user_id, created_at, updated_at)
+VALUES
+    ($1, $2, $3, $3)
+RETURNING
+    id, created_at, updated_at, body, user_id, status, publish_at, deleted_at, reply_to_id
+`
+
+type ImportChirpParams struct {
+	Body      string
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+func (q *Queries) ImportChirp(ctx context.Context, arg ImportChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, importChirp, arg.Body, arg.UserID, arg.CreatedAt)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.Status,
+		&i.PublishAt,
+		&i.DeletedAt,
+		&i.ReplyToID,
+	)
+	return i, err
+}
+
+const publishChirp = `-- name: PublishChirp :one
+UPDATE
+    chirps
+SET
+    created_at = (NOW() AT TIME ZONE 'utc'),
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    status = 'published',
+    publish_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    id = $1
+    AND user_id = $2
+    AND status <> 'published'
+    AND deleted_at IS NULL
+RETURNING
+    id, created_at, updated_at, body, user_id, status, publish_at, deleted_at, reply_to_id
+`
+
+type PublishChirpParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) PublishChirp(ctx context.Context, arg PublishChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, publishChirp, arg.ID, arg.UserID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.Status,
+		&i.PublishAt,
+		&i.DeletedAt,
+		&i.ReplyToID,
+	)
+	return i, err
+}
+
+const publishDueChirps = `-- name: PublishDueChirps :many
+UPDATE
+    chirps
+SET
+    created_at = (NOW() AT TIME ZONE 'utc'),
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    status = 'published',
+    publish_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    id IN (
+        SELECT
+            due.id
+        FROM
+            chirps AS due
+        WHERE
+            due.status = 'scheduled'
+            AND due.publish_at <= NOW()
+            AND due.deleted_at IS NULL
+        ORDER BY
+            due.publish_at ASC
+        LIMIT
+            $1
+        FOR UPDATE
+            SKIP LOCKED
+    )
+RETURNING
+    id, created_at, updated_at, body, user_id, status, publish_at, deleted_at, reply_to_id
+`
+
+func (q *Queries) PublishDueChirps(ctx context.Context, limit int32) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, publishDueChirps, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.Status,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ReplyToID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedChirps = `-- name: PurgeDeletedChirps :execrows
+DELETE FROM
+    chirps
+WHERE
+    deleted_at < $1::TIMESTAMPTZ
+`
+
+func (q *Queries) PurgeDeletedChirps(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedChirps, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreChirp = `-- name: RestoreChirp :one
+UPDATE
+    chirps
+SET
+    deleted_at = NULL
+WHERE
+    id = $1
+    AND user_id = $2
+    AND deleted_at >= $3::TIMESTAMPTZ
+RETURNING
+    id, created_at, updated_at, body, user_id, status, publish_at, deleted_at, reply_to_id
+`
+
+type RestoreChirpParams struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	DeletedSince time.Time
+}
+
+func (q *Queries) RestoreChirp(ctx context.Context, arg RestoreChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, restoreChirp, arg.ID, arg.UserID, arg.DeletedSince)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.Status,
+		&i.PublishAt,
+		&i.DeletedAt,
+		&i.ReplyToID,
+	)
+	return i, err
+}
+
+const restoreChirpsForUser = `-- name: RestoreChirpsForUser :exec
+UPDATE
+    chirps
+SET
+    deleted_at = NULL
+WHERE
+    user_id = $1
+    AND deleted_at = $2
+`
+
+type RestoreChirpsForUserParams struct {
+	UserID    uuid.UUID
+	DeletedAt sql.NullTime
+}
+
+func (q *Queries) RestoreChirpsForUser(ctx context.Context, arg RestoreChirpsForUserParams) error {
+	_, err := q.db.ExecContext(ctx, restoreChirpsForUser, arg.UserID, arg.DeletedAt)
+	return err
+}
+
+const softDeleteChirp = `-- name: SoftDeleteChirp :execrows
+UPDATE
+    chirps
+SET
+    deleted_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    id = $1
+    AND user_id = $2
+    AND deleted_at IS NULL
+`
+
+type SoftDeleteChirpParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) SoftDeleteChirp(ctx context.Context, arg SoftDeleteChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, softDeleteChirp, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const softDeleteChirpsForUser = `-- name: SoftDeleteChirpsForUser :exec
+UPDATE
+    chirps
+SET
+    deleted_at = $1
+WHERE
+    user_id = $2
+    AND deleted_at IS NULL
+`
+
+type SoftDeleteChirpsForUserParams struct {
+	DeletedAt sql.NullTime
+	UserID    uuid.UUID
+}
+
+func (q *Queries) SoftDeleteChirpsForUser(ctx context.Context, arg SoftDeleteChirpsForUserParams) error {
+	_, err := q.db.ExecContext(ctx, softDeleteChirpsForUser, arg.DeletedAt, arg.UserID)
+	return err
+}
+
+const updateUnpublishedChirp = `-- name: UpdateUnpublishedChirp :one
+UPDATE
+    chirps
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    body = $1,
+    status = $2,
+    publish_at = $3
+WHERE
+    id = $4
+    AND user_id = $5
+    AND status <> 'published'
+    AND deleted_at IS NULL
+RETURNING
+    id, created_at, updated_at, body, user_id, status, publish_at, deleted_at, reply_to_id
+`
+
+type UpdateUnpublishedChirpParams struct {
+	Body      string
+	Status    string
+	PublishAt sql.NullTime
+	ID        uuid.UUID
+	UserID    uuid.UUID
+}
+
+func (q *Queries) UpdateUnpublishedChirp(ctx context.Context, arg UpdateUnpublishedChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateUnpublishedChirp,
+		arg.Body,
+		arg.Status,
+		arg.PublishAt,
+		arg.ID,
+		arg.UserID,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.Status,
+		&i.PublishAt,
+		&i.DeletedAt,
+		&i.ReplyToID,
 	)
 	return i, err
 }
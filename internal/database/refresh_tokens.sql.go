@@ -65,14 +65,56 @@ func (q *Queries) GetRefreshToken(ctx context.Context, token string) (RefreshTok
 	return i, err
 }
 
+const getRefreshTokensForUser = `-- name: GetRefreshTokensForUser :many
+SELECT
+    token, created_at, updated_at, user_id, expires_at, revoked_at
+FROM
+    refresh_tokens
+WHERE
+    user_id = $1
+ORDER BY
+    created_at ASC
+`
+
+func (q *Queries) GetRefreshTokensForUser(ctx context.Context, userID uuid.UUID) ([]RefreshToken, error) {
+	rows, err := q.db.QueryContext(ctx, getRefreshTokensForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RefreshToken
+	for rows.Next() {
+		var i RefreshToken
+		if err := rows.Scan(
+			&i.Token,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserFromRefreshToken = `-- name: GetUserFromRefreshToken :one
 SELECT
-    id, users.created_at, users.updated_at, email, hashed_password, is_chirpy_red, token, refresh_tokens.created_at, refresh_tokens.updated_at, user_id, expires_at, revoked_at
+    id, users.created_at, users.updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator, token, refresh_tokens.created_at, refresh_tokens.updated_at, user_id, expires_at, revoked_at
 FROM
     users
     INNER JOIN refresh_tokens ON users.id = refresh_tokens.user_id
 WHERE
     refresh_tokens.token = $1
+    AND users.deleted_at IS NULL
 `
 
 type GetUserFromRefreshTokenRow struct {
@@ -82,6 +124,13 @@ type GetUserFromRefreshTokenRow struct {
 	Email          string
 	HashedPassword string
 	IsChirpyRed    bool
+	Username       sql.NullString
+	DisplayName    string
+	Bio            string
+	AvatarMediaID  uuid.NullUUID
+	PinnedChirpID  uuid.NullUUID
+	DeletedAt      sql.NullTime
+	IsModerator    bool
 	Token          string
 	CreatedAt_2    time.Time
 	UpdatedAt_2    time.Time
@@ -100,6 +149,13 @@ func (q *Queries) GetUserFromRefreshToken(ctx context.Context, token string) (Ge
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+		&i.PinnedChirpID,
+		&i.DeletedAt,
+		&i.IsModerator,
 		&i.Token,
 		&i.CreatedAt_2,
 		&i.UpdatedAt_2,
@@ -124,3 +180,19 @@ func (q *Queries) RevokeRefreshToken(ctx context.Context, token string) error {
 	_, err := q.db.ExecContext(ctx, revokeRefreshToken, token)
 	return err
 }
+
+const revokeRefreshTokensForUser = `-- name: RevokeRefreshTokensForUser :exec
+UPDATE
+    refresh_tokens
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    revoked_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    user_id = $1
+    AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeRefreshTokensForUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, revokeRefreshTokensForUser, userID)
+	return err
+}
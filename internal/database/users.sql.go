@@ -7,17 +7,38 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countPublishedChirpsForUser = `-- name: CountPublishedChirpsForUser :one
+SELECT
+    COUNT(*)
+FROM
+    chirps
+WHERE
+    user_id = $1
+    AND status = 'published'
+    AND deleted_at IS NULL
+`
+
+func (q *Queries) CountPublishedChirpsForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPublishedChirpsForUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO
     users (email, hashed_password)
 VALUES
     ($1, $2)
 RETURNING
-    id, created_at, updated_at, email, hashed_password, is_chirpy_red
+    id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator
 `
 
 type CreateUserParams struct {
@@ -35,6 +56,13 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+		&i.PinnedChirpID,
+		&i.DeletedAt,
+		&i.IsModerator,
 	)
 	return i, err
 }
@@ -49,13 +77,115 @@ func (q *Queries) DeleteAllUsers(ctx context.Context) error {
 	return err
 }
 
+const deleteUserByID = `-- name: DeleteUserByID :exec
+DELETE FROM
+    users
+WHERE
+    id = $1
+`
+
+func (q *Queries) DeleteUserByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUserByID, id)
+	return err
+}
+
+const getDeletedUserByEmail = `-- name: GetDeletedUserByEmail :one
+SELECT
+    id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator
+FROM
+    users
+WHERE
+    email = $1
+    AND deleted_at >= $2::TIMESTAMPTZ
+`
+
+type GetDeletedUserByEmailParams struct {
+	Email        string
+	DeletedSince time.Time
+}
+
+func (q *Queries) GetDeletedUserByEmail(ctx context.Context, arg GetDeletedUserByEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getDeletedUserByEmail, arg.Email, arg.DeletedSince)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+		&i.PinnedChirpID,
+		&i.DeletedAt,
+		&i.IsModerator,
+	)
+	return i, err
+}
+
+const getPurgeableUsers = `-- name: GetPurgeableUsers :many
+SELECT
+    id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator
+FROM
+    users
+WHERE
+    deleted_at < $1::TIMESTAMPTZ
+LIMIT
+    $2
+`
+
+type GetPurgeableUsersParams struct {
+	DeletedBefore time.Time
+	RowLimit      int32
+}
+
+func (q *Queries) GetPurgeableUsers(ctx context.Context, arg GetPurgeableUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getPurgeableUsers, arg.DeletedBefore, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.Username,
+			&i.DisplayName,
+			&i.Bio,
+			&i.AvatarMediaID,
+			&i.PinnedChirpID,
+			&i.DeletedAt,
+			&i.IsModerator,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
 SELECT
-    id, created_at, updated_at, email, hashed_password, is_chirpy_red
+    id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator
 FROM
     users
 WHERE
     email = $1
+    AND deleted_at IS NULL
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -68,17 +198,25 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+		&i.PinnedChirpID,
+		&i.DeletedAt,
+		&i.IsModerator,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
 SELECT
-    id, created_at, updated_at, email, hashed_password, is_chirpy_red
+    id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator
 FROM
     users
 WHERE
     id = $1
+    AND deleted_at IS NULL
 `
 
 func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
@@ -91,6 +229,216 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+		&i.PinnedChirpID,
+		&i.DeletedAt,
+		&i.IsModerator,
+	)
+	return i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT
+    id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator
+FROM
+    users
+WHERE
+    username = $1
+    AND deleted_at IS NULL
+`
+
+func (q *Queries) GetUserByUsername(ctx context.Context, username sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByUsername, username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+		&i.PinnedChirpID,
+		&i.DeletedAt,
+		&i.IsModerator,
+	)
+	return i, err
+}
+
+const getUsersByUsernames = `-- name: GetUsersByUsernames :many
+SELECT
+    id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator
+FROM
+    users
+WHERE
+    username = ANY($1::TEXT [])
+    AND deleted_at IS NULL
+`
+
+func (q *Queries) GetUsersByUsernames(ctx context.Context, usernames []string) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByUsernames, pq.Array(usernames))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.Username,
+			&i.DisplayName,
+			&i.Bio,
+			&i.AvatarMediaID,
+			&i.PinnedChirpID,
+			&i.DeletedAt,
+			&i.IsModerator,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT
+    id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator
+FROM
+    users
+WHERE
+    deleted_at IS NULL
+ORDER BY
+    created_at ASC
+LIMIT
+    $1 OFFSET $2
+`
+
+type ListUsersParams struct {
+	RowLimit  int32
+	RowOffset int32
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers, arg.RowLimit, arg.RowOffset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.Username,
+			&i.DisplayName,
+			&i.Bio,
+			&i.AvatarMediaID,
+			&i.PinnedChirpID,
+			&i.DeletedAt,
+			&i.IsModerator,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreUser = `-- name: RestoreUser :one
+UPDATE
+    users
+SET
+    deleted_at = NULL
+WHERE
+    id = $1
+RETURNING
+    id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator
+`
+
+func (q *Queries) RestoreUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, restoreUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+		&i.PinnedChirpID,
+		&i.DeletedAt,
+		&i.IsModerator,
+	)
+	return i, err
+}
+
+const setPinnedChirp = `-- name: SetPinnedChirp :one
+UPDATE
+    users
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    pinned_chirp_id = $1
+WHERE
+    id = $2
+RETURNING
+    id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator
+`
+
+type SetPinnedChirpParams struct {
+	PinnedChirpID uuid.NullUUID
+	ID            uuid.UUID
+}
+
+func (q *Queries) SetPinnedChirp(ctx context.Context, arg SetPinnedChirpParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setPinnedChirp, arg.PinnedChirpID, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+		&i.PinnedChirpID,
+		&i.DeletedAt,
+		&i.IsModerator,
 	)
 	return i, err
 }
@@ -103,8 +451,9 @@ SET
     is_chirpy_red = TRUE
 WHERE
     id = $1
+    AND deleted_at IS NULL
 RETURNING
-    id, created_at, updated_at, email, hashed_password, is_chirpy_red
+    id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator
 `
 
 func (q *Queries) SetUserAsChirpyRed(ctx context.Context, id uuid.UUID) (User, error) {
@@ -117,6 +466,84 @@ func (q *Queries) SetUserAsChirpyRed(ctx context.Context, id uuid.UUID) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+		&i.PinnedChirpID,
+		&i.DeletedAt,
+		&i.IsModerator,
+	)
+	return i, err
+}
+
+const setUserModerator = `-- name: SetUserModerator :one
+UPDATE
+    users
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    is_moderator = $1
+WHERE
+    id = $2
+RETURNING
+    id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator
+`
+
+type SetUserModeratorParams struct {
+	IsModerator bool
+	ID          uuid.UUID
+}
+
+func (q *Queries) SetUserModerator(ctx context.Context, arg SetUserModeratorParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserModerator, arg.IsModerator, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+		&i.PinnedChirpID,
+		&i.DeletedAt,
+		&i.IsModerator,
+	)
+	return i, err
+}
+
+const softDeleteUser = `-- name: SoftDeleteUser :one
+UPDATE
+    users
+SET
+    deleted_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    id = $1
+    AND deleted_at IS NULL
+RETURNING
+    id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator
+`
+
+func (q *Queries) SoftDeleteUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, softDeleteUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+		&i.PinnedChirpID,
+		&i.DeletedAt,
+		&i.IsModerator,
 	)
 	return i, err
 }
@@ -127,21 +554,96 @@ UPDATE
 SET
     updated_at = (NOW() AT TIME ZONE 'utc'),
     email = $1,
-    hashed_password = $2
+    hashed_password = $2,
+    username = COALESCE($3, username)
 WHERE
-    id = $3
+    id = $4
+    AND deleted_at IS NULL
 RETURNING
-    id, created_at, updated_at, email, hashed_password, is_chirpy_red
+    id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator
 `
 
 type UpdateUserParams struct {
 	Email          string
 	HashedPassword string
+	Username       sql.NullString
 	ID             uuid.UUID
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUser, arg.Email, arg.HashedPassword, arg.ID)
+	row := q.db.QueryRowContext(ctx, updateUser,
+		arg.Email,
+		arg.HashedPassword,
+		arg.Username,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+		&i.PinnedChirpID,
+		&i.DeletedAt,
+		&i.IsModerator,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE
+    users
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    hashed_password = $1
+WHERE
+    id = $2
+`
+
+type UpdateUserPasswordParams struct {
+	HashedPassword string
+	ID             uuid.UUID
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserPassword, arg.HashedPassword, arg.ID)
+	return err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE
+    users
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    display_name = $1,
+    bio = $2,
+    avatar_media_id = $3
+WHERE
+    id = $4
+RETURNING
+    id, created_at, updated_at, email, hashed_password, is_chirpy_red, username, display_name, bio, avatar_media_id, pinned_chirp_id, deleted_at, is_moderator
+`
+
+type UpdateUserProfileParams struct {
+	DisplayName   string
+	Bio           string
+	AvatarMediaID uuid.NullUUID
+	ID            uuid.UUID
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserProfile,
+		arg.DisplayName,
+		arg.Bio,
+		arg.AvatarMediaID,
+		arg.ID,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -150,6 +652,13 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+		&i.PinnedChirpID,
+		&i.DeletedAt,
+		&i.IsModerator,
 	)
 	return i, err
 }
@@ -0,0 +1,441 @@
+package dm
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/pagination"
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/validate"
+)
+
+// MaxGroupMembers is the largest conversation, including its creator.
+const MaxGroupMembers int = 8
+
+// uniqueViolation is the Postgres error code raised when a UNIQUE constraint
+// is violated.
+const uniqueViolation pq.ErrorCode = "23505"
+
+var (
+	errInvalidMembers = fmt.Errorf(
+		"must name 1 to %d other existing users",
+		MaxGroupMembers-1,
+	)
+	errUnknownConversation = errors.New("conversation not found")
+)
+
+type Conversation struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Group       bool      `json:"group"`
+	Members     []Member  `json:"members"`
+	UnreadCount int64     `json:"unread_count"`
+}
+
+// Member is a conversation participant. LastReadAt is their read receipt:
+// every message sent up to then has been read.
+type Member struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	LastReadAt *time.Time `json:"last_read_at"`
+}
+
+// POST /api/conversations
+//
+// Starts a conversation with the given users. Asking for a one-to-one
+// conversation that already exists returns it instead of creating another.
+func PostConversation(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			type input struct {
+				UserIDs []uuid.UUID `json:"user_ids"`
+			}
+
+			var data input
+
+			if !validate.Decode(writer, req, &data) {
+				return
+			}
+
+			others := make([]uuid.UUID, 0, len(data.UserIDs))
+
+			for _, id := range data.UserIDs {
+				if id != userID && !slices.Contains(others, id) {
+					others = append(others, id)
+				}
+			}
+
+			if len(others) == 0 || len(others) >= MaxGroupMembers {
+				problem.InvalidField(
+					writer,
+					"user_ids",
+					errInvalidMembers.Error(),
+				)
+
+				return
+			}
+
+			for _, id := range others {
+				_, err := env.DB.GetUserByID(req.Context(), id)
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.InvalidField(
+						writer,
+						"user_ids",
+						errInvalidMembers.Error(),
+					)
+
+					return
+				}
+
+				if err != nil {
+					problem.Internal(writer, req, err)
+
+					return
+				}
+			}
+
+			conversation, created, err := startConversation(
+				req.Context(),
+				env,
+				userID,
+				others,
+			)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			status := http.StatusOK
+			if created {
+				status = http.StatusCreated
+			}
+
+			resData, err := toConversations(
+				req.Context(),
+				env,
+				[]database.ListConversationsForUserRow{{
+					ID:        conversation.ID,
+					CreatedAt: conversation.CreatedAt,
+					UpdatedAt: conversation.UpdatedAt,
+					DirectKey: conversation.DirectKey,
+				}},
+			)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeJSON(writer, req, resData[0], status)
+		},
+	)
+}
+
+// GET /api/conversations
+//
+// Returns the caller's conversations, most recently active first.
+func GetConversations(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			limit, offset, err := pagination.Parse(req)
+			if err != nil {
+				problem.BadRequest(writer, err.Error())
+
+				return
+			}
+
+			opts := database.ListConversationsForUserParams{
+				UserID:    userID,
+				RowLimit:  limit,
+				RowOffset: offset,
+			}
+
+			rows, err := env.DB.ListConversationsForUser(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			resData, err := toConversations(req.Context(), env, rows)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeJSON(writer, req, resData, http.StatusOK)
+		},
+	)
+}
+
+// POST /api/conversations/{conversationID}/read
+//
+// Marks every message in the conversation as read by the caller.
+func PostConversationRead(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("conversationID"))
+			if err != nil {
+				problem.BadRequest(writer, "conversationID must be a UUID")
+
+				return
+			}
+
+			opts := database.MarkConversationReadParams{
+				ConversationID: id,
+				UserID:         userID,
+			}
+
+			updated, err := env.DB.MarkConversationRead(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if updated == 0 {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
+
+// startConversation finds or creates the conversation between userID and
+// others. One-to-one conversations are unique per pair of users; a racing
+// request creating the same one is resolved by reading back the winner.
+func startConversation(
+	ctx context.Context,
+	env *appenv.Env,
+	userID uuid.UUID,
+	others []uuid.UUID,
+) (database.Conversation, bool, error) {
+	var directKey sql.NullString
+
+	if len(others) == 1 {
+		directKey = sql.NullString{
+			String: pairKey(userID, others[0]),
+			Valid:  true,
+		}
+
+		existing, err := env.DB.GetConversationByDirectKey(ctx, directKey)
+		if err == nil {
+			return existing, false, nil
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			return database.Conversation{}, false, fmt.Errorf(
+				"find conversation: %w",
+				err,
+			)
+		}
+	}
+
+	var conversation database.Conversation
+
+	err := env.InTx(ctx, func(qtx *database.Queries) error {
+		var err error
+
+		conversation, err = qtx.CreateConversation(ctx, directKey)
+		if err != nil {
+			return err
+		}
+
+		for _, memberID := range append([]uuid.UUID{userID}, others...) {
+			err := qtx.AddConversationMember(
+				ctx,
+				database.AddConversationMemberParams{
+					ConversationID: conversation.ID,
+					UserID:         memberID,
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation &&
+		directKey.Valid {
+		existing, err := env.DB.GetConversationByDirectKey(ctx, directKey)
+		if err != nil {
+			return database.Conversation{}, false, fmt.Errorf(
+				"find conversation: %w",
+				err,
+			)
+		}
+
+		return existing, false, nil
+	}
+
+	if err != nil {
+		return database.Conversation{}, false, fmt.Errorf(
+			"create conversation: %w",
+			err,
+		)
+	}
+
+	return conversation, true, nil
+}
+
+// memberConversation loads a conversation the user takes part in, or returns
+// errUnknownConversation.
+func memberConversation(
+	ctx context.Context,
+	env *appenv.Env,
+	id uuid.UUID,
+	userID uuid.UUID,
+) (database.Conversation, error) {
+	conversation, err := env.DB.GetConversationForMember(
+		ctx,
+		database.GetConversationForMemberParams{ID: id, UserID: userID},
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Conversation{}, errUnknownConversation
+	}
+
+	if err != nil {
+		return database.Conversation{}, fmt.Errorf(
+			"load conversation: %w",
+			err,
+		)
+	}
+
+	return conversation, nil
+}
+
+// conversationMembers returns the IDs of everyone taking part in a
+// conversation.
+func conversationMembers(
+	ctx context.Context,
+	env *appenv.Env,
+	id uuid.UUID,
+) ([]uuid.UUID, error) {
+	members, err := env.DB.GetConversationMembers(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, fmt.Errorf("load members: %w", err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.UserID)
+	}
+
+	return ids, nil
+}
+
+func toConversations(
+	ctx context.Context,
+	env *appenv.Env,
+	rows []database.ListConversationsForUserRow,
+) ([]Conversation, error) {
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+
+	members := make(map[uuid.UUID][]Member, len(rows))
+
+	if len(ids) > 0 {
+		memberRows, err := env.DB.GetConversationMembers(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("load members: %w", err)
+		}
+
+		for _, row := range memberRows {
+			member := Member{UserID: row.UserID}
+			if row.LastReadAt.Valid {
+				member.LastReadAt = &row.LastReadAt.Time
+			}
+
+			members[row.ConversationID] = append(
+				members[row.ConversationID],
+				member,
+			)
+		}
+	}
+
+	resData := make([]Conversation, 0, len(rows))
+
+	for _, row := range rows {
+		resData = append(resData, Conversation{
+			ID:          row.ID,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+			Group:       !row.DirectKey.Valid,
+			Members:     members[row.ID],
+			UnreadCount: row.UnreadCount,
+		})
+	}
+
+	return resData, nil
+}
+
+// pairKey identifies the one-to-one conversation between two users no matter
+// who started it.
+func pairKey(a, b uuid.UUID) string {
+	keys := []string{a.String(), b.String()}
+	slices.Sort(keys)
+
+	return strings.Join(keys, ":")
+}
+
+func writeJSON(
+	writer http.ResponseWriter,
+	req *http.Request,
+	data any,
+	status int,
+) {
+	res, err := json.Marshal(data)
+	if err != nil {
+		problem.Internal(writer, req, err)
+
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+
+	_, _ = writer.Write(res)
+}
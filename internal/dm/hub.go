@@ -0,0 +1,94 @@
+// Package dm implements direct messages: one-to-one and small group
+// conversations, their history and read receipts, and long polling for new
+// messages.
+package dm
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Hub wakes up long-polling requests when a message arrives for their user.
+// It only knows about requests served by this process, so pollers also
+// re-check the database periodically to pick up messages sent through other
+// replicas.
+type Hub struct {
+	mu        sync.Mutex
+	waiters   map[uuid.UUID]map[chan struct{}]struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		waiters: make(map[uuid.UUID]map[chan struct{}]struct{}),
+		closed:  make(chan struct{}),
+	}
+}
+
+// Close releases every long poll, current and future, so that a shutting
+// down server does not wait out PollTimeout for each of them.
+func (h *Hub) Close() {
+	h.closeOnce.Do(func() { close(h.closed) })
+}
+
+// Done is closed once Close has been called.
+func (h *Hub) Done() <-chan struct{} {
+	return h.closed
+}
+
+// Subscribe registers a waiter for userID. The returned channel receives a
+// value whenever Notify is called for that user; cancel must be called once
+// the waiter is done.
+func (h *Hub) Subscribe(userID uuid.UUID) (<-chan struct{}, func()) {
+	wake := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	if h.waiters[userID] == nil {
+		h.waiters[userID] = make(map[chan struct{}]struct{})
+	}
+	h.waiters[userID][wake] = struct{}{}
+	h.mu.Unlock()
+
+	cancel := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		delete(h.waiters[userID], wake)
+
+		if len(h.waiters[userID]) == 0 {
+			delete(h.waiters, userID)
+		}
+	}
+
+	return wake, cancel
+}
+
+// Notify wakes every waiter of the given users without blocking.
+func (h *Hub) Notify(userIDs ...uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, userID := range userIDs {
+		for wake := range h.waiters[userID] {
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// Waiting returns the number of requests currently waiting for messages.
+func (h *Hub) Waiting() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := 0
+	for _, waiters := range h.waiters {
+		n += len(waiters)
+	}
+
+	return n
+}
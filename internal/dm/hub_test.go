@@ -0,0 +1,95 @@
+package dm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/dm"
+)
+
+func TestHub(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should wake only the notified user", func(t *testing.T) {
+		t.Parallel()
+
+		hub := dm.NewHub()
+		alice, bob := uuid.New(), uuid.New()
+
+		aliceWake, cancelAlice := hub.Subscribe(alice)
+		defer cancelAlice()
+
+		bobWake, cancelBob := hub.Subscribe(bob)
+		defer cancelBob()
+
+		hub.Notify(alice)
+
+		select {
+		case <-aliceWake:
+		case <-time.After(time.Second):
+			t.Fatal("expected alice to be woken up")
+		}
+
+		select {
+		case <-bobWake:
+			t.Fatal("did not expect bob to be woken up")
+		default:
+		}
+	})
+
+	t.Run("should not block when a waiter is already woken", func(t *testing.T) {
+		t.Parallel()
+
+		hub := dm.NewHub()
+		user := uuid.New()
+
+		_, cancel := hub.Subscribe(user)
+		defer cancel()
+
+		hub.Notify(user)
+		hub.Notify(user)
+	})
+
+	t.Run("should count waiters until they cancel", func(t *testing.T) {
+		t.Parallel()
+
+		hub := dm.NewHub()
+		user := uuid.New()
+
+		_, cancelFirst := hub.Subscribe(user)
+		_, cancelSecond := hub.Subscribe(user)
+
+		if got := hub.Waiting(); got != 2 {
+			t.Fatalf("expected 2 waiters, got %d", got)
+		}
+
+		cancelFirst()
+		cancelSecond()
+
+		if got := hub.Waiting(); got != 0 {
+			t.Fatalf("expected no waiters, got %d", got)
+		}
+	})
+
+	t.Run("should release waiters once closed", func(t *testing.T) {
+		t.Parallel()
+
+		hub := dm.NewHub()
+
+		select {
+		case <-hub.Done():
+			t.Fatal("did not expect the hub to be closed")
+		default:
+		}
+
+		hub.Close()
+		hub.Close()
+
+		select {
+		case <-hub.Done():
+		default:
+			t.Fatal("expected the hub to be closed")
+		}
+	})
+}
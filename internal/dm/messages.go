@@ -0,0 +1,366 @@
+package dm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/pagination"
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/validate"
+)
+
+const (
+	maxMessageLength int = 1000
+
+	// PollTimeout is how long a long-polling request waits for new messages
+	// before returning an empty batch.
+	PollTimeout = 25 * time.Second
+	// pollRecheck is how often a waiting request looks at the database, to
+	// catch messages sent through another replica.
+	pollRecheck           = 2 * time.Second
+	maxPollMessages int32 = 100
+)
+
+var errInvalidMessage = fmt.Errorf(
+	"must be between 1 and %d characters",
+	maxMessageLength,
+)
+
+type Message struct {
+	ID             uuid.UUID `json:"id"`
+	Seq            int64     `json:"seq"`
+	CreatedAt      time.Time `json:"created_at"`
+	ConversationID uuid.UUID `json:"conversation_id"`
+	SenderID       uuid.UUID `json:"sender_id"`
+	Body           string    `json:"body"`
+}
+
+// GET /api/conversations/{conversationID}/messages
+//
+// Returns the conversation history, newest first.
+func GetMessages(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("conversationID"))
+			if err != nil {
+				problem.BadRequest(writer, "conversationID must be a UUID")
+
+				return
+			}
+
+			limit, offset, err := pagination.Parse(req)
+			if err != nil {
+				problem.BadRequest(writer, err.Error())
+
+				return
+			}
+
+			_, err = memberConversation(req.Context(), env, id, userID)
+			if errors.Is(err, errUnknownConversation) {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			opts := database.GetDirectMessagesParams{
+				ConversationID: id,
+				RowLimit:       limit,
+				RowOffset:      offset,
+			}
+
+			messages, err := env.DB.GetDirectMessages(req.Context(), opts)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeJSON(writer, req, toMessages(messages), http.StatusOK)
+		},
+	)
+}
+
+// POST /api/conversations/{conversationID}/messages
+func PostMessage(env *appenv.Env, hub *Hub) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			id, err := uuid.Parse(req.PathValue("conversationID"))
+			if err != nil {
+				problem.BadRequest(writer, "conversationID must be a UUID")
+
+				return
+			}
+
+			type input struct {
+				Body string `json:"body"`
+			}
+
+			var data input
+
+			if !validate.Decode(writer, req, &data) {
+				return
+			}
+
+			body := strings.TrimSpace(data.Body)
+			if body == "" || utf8.RuneCountInString(body) > maxMessageLength {
+				problem.InvalidField(
+					writer,
+					"body",
+					errInvalidMessage.Error(),
+				)
+
+				return
+			}
+
+			_, err = memberConversation(req.Context(), env, id, userID)
+			if errors.Is(err, errUnknownConversation) {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			refused, err := refusesMessages(req.Context(), env, id, userID)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			if refused {
+				problem.Write(
+					writer,
+					http.StatusForbidden,
+					problem.CodeMessagesRefused,
+					"a member of this conversation does not accept messages from you",
+				)
+
+				return
+			}
+
+			var message database.DirectMessage
+
+			err = env.InTx(req.Context(), func(qtx *database.Queries) error {
+				if err := qtx.TouchConversation(req.Context(), id); err != nil {
+					return err
+				}
+
+				var err error
+
+				message, err = qtx.CreateDirectMessage(
+					req.Context(),
+					database.CreateDirectMessageParams{
+						ConversationID: id,
+						SenderID:       userID,
+						Body:           body,
+					},
+				)
+
+				return err
+			})
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			members, err := conversationMembers(req.Context(), env, id)
+			if err == nil {
+				hub.Notify(members...)
+			}
+
+			writeJSON(
+				writer,
+				req,
+				toMessages([]database.DirectMessage{message})[0],
+				http.StatusCreated,
+			)
+		},
+	)
+}
+
+// Batch is the answer to a long poll. Clients pass Cursor as `after` on
+// their next poll.
+type Batch struct {
+	Messages []Message `json:"messages"`
+	Cursor   int64     `json:"cursor"`
+}
+
+// GET /api/messages/poll?after={cursor}
+//
+// Long polls for messages in any of the caller's conversations sent since
+// the poll that returned `after`. It answers as soon as there is at least
+// one, or with an empty batch after PollTimeout.
+//
+// Messages are paged by the id of the transaction that sent them, and only
+// those below the fence, the oldest transaction still running, are
+// returned: every one of them has ended, so no message can later commit
+// behind the cursor. Unlike a counter locked until commit, this does not
+// make senders wait for each other.
+func PollMessages(env *appenv.Env, hub *Hub) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			var after int64
+
+			if raw := req.URL.Query().Get("after"); raw != "" {
+				n, err := strconv.ParseInt(raw, 10, 64)
+				if err != nil || n < 0 {
+					problem.BadRequest(
+						writer,
+						"after must be a non-negative integer",
+					)
+
+					return
+				}
+
+				after = n
+			}
+
+			// Subscribe before the first look at the database so a message
+			// sent in between still wakes us up.
+			wake, cancel := hub.Subscribe(userID)
+			defer cancel()
+
+			timeout := time.NewTimer(PollTimeout)
+			defer timeout.Stop()
+
+			recheck := time.NewTicker(pollRecheck)
+			defer recheck.Stop()
+
+			for {
+				batch, err := nextBatch(req.Context(), env, userID, after)
+				if err != nil {
+					problem.Internal(writer, req, err)
+
+					return
+				}
+
+				if len(batch.Messages) > 0 {
+					writeJSON(writer, req, batch, http.StatusOK)
+
+					return
+				}
+
+				// Nothing was sent below the fence: the next look can start
+				// from there.
+				after = batch.Cursor
+
+				select {
+				case <-wake:
+				case <-recheck.C:
+				case <-timeout.C:
+					writeJSON(writer, req, batch, http.StatusOK)
+
+					return
+				case <-hub.Done():
+					// The server is shutting down: answer like a timeout
+					// so the client polls again, reaching another replica.
+					writeJSON(writer, req, batch, http.StatusOK)
+
+					return
+				case <-req.Context().Done():
+					return
+				}
+			}
+		},
+	)
+}
+
+// nextBatch returns the messages of userID's conversations sent from the
+// transaction after onwards and below the current fence.
+func nextBatch(
+	ctx context.Context,
+	env *appenv.Env,
+	userID uuid.UUID,
+	after int64,
+) (Batch, error) {
+	// The fence is taken first: every transaction below it has ended by the
+	// time the messages are read.
+	fence, err := env.DB.GetDirectMessageFence(ctx)
+	if err != nil {
+		return Batch{}, fmt.Errorf("load message fence: %w", err)
+	}
+
+	messages, err := env.DB.GetDirectMessagesAfter(
+		ctx,
+		database.GetDirectMessagesAfterParams{
+			UserID:   userID,
+			FromXid:  after,
+			Fence:    max(fence, after),
+			RowLimit: maxPollMessages,
+		},
+	)
+	if err != nil {
+		return Batch{}, fmt.Errorf("load messages: %w", err)
+	}
+
+	cursor := max(fence, after)
+
+	// A full batch may have left messages below the fence behind. Each
+	// message is sent in its own transaction, so the next batch starts
+	// right after the last one returned.
+	if len(messages) == int(maxPollMessages) {
+		cursor = messages[len(messages)-1].Xid + 1
+	}
+
+	return Batch{Messages: toMessages(messages), Cursor: cursor}, nil
+}
+
+func toMessages(messages []database.DirectMessage) []Message {
+	resData := make([]Message, 0, len(messages))
+
+	for _, message := range messages {
+		resData = append(resData, Message{
+			ID:             message.ID,
+			Seq:            message.Seq,
+			CreatedAt:      message.CreatedAt,
+			ConversationID: message.ConversationID,
+			SenderID:       message.SenderID,
+			Body:           message.Body,
+		})
+	}
+
+	return resData
+}
@@ -0,0 +1,114 @@
+package dm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/validate"
+)
+
+// Settings says who may send a user direct messages.
+type Settings struct {
+	// FollowedOnly accepts messages only from people the user follows.
+	FollowedOnly bool `json:"followed_only"`
+}
+
+// GET /api/messages/settings
+func GetSettings(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			settings, err := env.DB.GetDMSettings(req.Context(), userID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeJSON(
+				writer,
+				req,
+				Settings{FollowedOnly: settings.FollowedOnly},
+				http.StatusOK,
+			)
+		},
+	)
+}
+
+// PUT /api/messages/settings
+func PutSettings(env *appenv.Env) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			userID := auth.UserID(req.Context())
+			if userID == uuid.Nil {
+				problem.Unauthorized(writer)
+
+				return
+			}
+
+			var data Settings
+
+			if !validate.Decode(writer, req, &data) {
+				return
+			}
+
+			settings, err := env.DB.UpsertDMSettings(
+				req.Context(),
+				database.UpsertDMSettingsParams{
+					UserID:       userID,
+					FollowedOnly: data.FollowedOnly,
+				},
+			)
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writeJSON(
+				writer,
+				req,
+				Settings{FollowedOnly: settings.FollowedOnly},
+				http.StatusOK,
+			)
+		},
+	)
+}
+
+// refusesMessages reports whether another member of the conversation does
+// not accept messages from sender: one of them blocked the other, or the
+// member accepts messages only from people they follow and does not follow
+// sender.
+func refusesMessages(
+	ctx context.Context,
+	env *appenv.Env,
+	id uuid.UUID,
+	sender uuid.UUID,
+) (bool, error) {
+	recipients, err := env.DB.GetRefusingRecipients(
+		ctx,
+		database.GetRefusingRecipientsParams{
+			ConversationID: id,
+			SenderID:       sender,
+		},
+	)
+	if err != nil {
+		return false, fmt.Errorf("load message settings: %w", err)
+	}
+
+	return len(recipients) > 0, nil
+}
@@ -0,0 +1,148 @@
+// Package events is the single dispatch point through which the API handlers
+// turn what happens to a user (likes, replies, mentions, ...) into
+// notifications.
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/logging"
+)
+
+type Kind string
+
+const (
+	KindFollow    Kind = "follow"
+	KindReply     Kind = "reply"
+	KindMention   Kind = "mention"
+	KindLike      Kind = "like"
+	KindChirpyRed Kind = "chirpy_red"
+	// KindExportReady tells a user their data export can be downloaded.
+	KindExportReady Kind = "export_ready"
+	// KindExportFailed tells a user their data export could not be built.
+	KindExportFailed Kind = "export_failed"
+)
+
+type Event struct {
+	Kind      Kind
+	Recipient uuid.UUID
+	// Actor is the user that caused the event, uuid.Nil for system events.
+	Actor uuid.UUID
+	// Subject is what the event is about, usually a chirp, uuid.Nil if there
+	// is none.
+	Subject uuid.UUID
+}
+
+// GroupKey returns the key under which similar events collapse into a single
+// unread notification, e.g. every like on the same chirp.
+func (e Event) GroupKey() string {
+	if e.Subject == uuid.Nil {
+		return string(e.Kind)
+	}
+
+	return fmt.Sprintf("%s:%s", e.Kind, e.Subject)
+}
+
+// Summary renders a collapsed notification, e.g. "X and 4 others liked your
+// chirp".
+func Summary(kind Kind, lead string, actorCount int64) string {
+	var action string
+
+	switch kind {
+	case KindFollow:
+		action = "followed you"
+	case KindReply:
+		action = "replied to your chirp"
+	case KindMention:
+		action = "mentioned you"
+	case KindLike:
+		action = "liked your chirp"
+	case KindChirpyRed:
+		return "Your Chirpy Red membership is now active"
+	case KindExportReady:
+		return "Your data export is ready to download"
+	case KindExportFailed:
+		return "Your data export could not be built, please request a new one"
+	default:
+		action = string(kind)
+	}
+
+	switch actorCount {
+	case 0, 1:
+		return fmt.Sprintf("%s %s", lead, action)
+	case 2: //nolint:mnd
+		return fmt.Sprintf("%s and 1 other %s", lead, action)
+	default:
+		return fmt.Sprintf("%s and %d others %s", lead, actorCount-1, action)
+	}
+}
+
+// Dispatch records the event as a notification for its recipient. Failures
+// are logged rather than returned: a notification that could not be stored
+// must never fail the request that triggered it.
+func Dispatch(ctx context.Context, env *appenv.Env, event Event) {
+	if err := Record(ctx, env.DB, event); err != nil {
+		logging.FromContext(ctx).Error(
+			"events: could not dispatch event",
+			"kind", event.Kind,
+			"error", err,
+		)
+	}
+}
+
+// Record stores the event as a notification for its recipient with q. Unlike
+// Dispatch, it returns failures, for callers whose notification must be
+// committed along with the change it reports. Events between users that
+// block each other are dropped.
+func Record(ctx context.Context, q *database.Queries, event Event) error {
+	if event.Recipient == uuid.Nil || event.Recipient == event.Actor {
+		return nil
+	}
+
+	if event.Actor != uuid.Nil {
+		blocked, err := q.IsBlockedBetween(ctx, database.IsBlockedBetweenParams{
+			UserID:  event.Recipient,
+			OtherID: event.Actor,
+		})
+		if err != nil {
+			return fmt.Errorf("check blocks: %w", err)
+		}
+
+		if blocked {
+			return nil
+		}
+	}
+
+	opts := database.UpsertNotificationParams{
+		UserID: event.Recipient,
+		Kind:   string(event.Kind),
+		SubjectID: uuid.NullUUID{
+			UUID:  event.Subject,
+			Valid: event.Subject != uuid.Nil,
+		},
+		GroupKey: event.GroupKey(),
+	}
+
+	notification, err := q.UpsertNotification(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("store notification: %w", err)
+	}
+
+	if event.Actor == uuid.Nil {
+		return nil
+	}
+
+	err = q.AddNotificationActor(ctx, database.AddNotificationActorParams{
+		NotificationID: notification.ID,
+		ActorID:        event.Actor,
+	})
+	if err != nil {
+		return fmt.Errorf("add actor to notification: %w", err)
+	}
+
+	return nil
+}
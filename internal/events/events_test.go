@@ -0,0 +1,118 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/events"
+)
+
+func TestGroupKey(t *testing.T) {
+	t.Parallel()
+
+	t.Run(
+		"should collapse events of the same kind on the same subject",
+		func(t *testing.T) {
+			t.Parallel()
+
+			chirp := uuid.New()
+
+			first := events.Event{
+				Kind:      events.KindLike,
+				Recipient: uuid.New(),
+				Actor:     uuid.New(),
+				Subject:   chirp,
+			}
+			second := first
+			second.Actor = uuid.New()
+
+			if first.GroupKey() != second.GroupKey() {
+				t.Fatalf(
+					"expected matching group keys, got %q and %q",
+					first.GroupKey(),
+					second.GroupKey(),
+				)
+			}
+		},
+	)
+
+	t.Run(
+		"should not collapse events on different subjects",
+		func(t *testing.T) {
+			t.Parallel()
+
+			first := events.Event{Kind: events.KindLike, Subject: uuid.New()}
+			second := events.Event{Kind: events.KindLike, Subject: uuid.New()}
+
+			if first.GroupKey() == second.GroupKey() {
+				t.Fatalf("expected different group keys, got %q", first.GroupKey())
+			}
+		},
+	)
+
+	t.Run(
+		"should group by kind alone when there is no subject",
+		func(t *testing.T) {
+			t.Parallel()
+
+			event := events.Event{Kind: events.KindFollow}
+
+			if event.GroupKey() != "follow" {
+				t.Fatalf("unexpected group key: got %q, want %q", event.GroupKey(), "follow")
+			}
+		},
+	)
+}
+
+func TestSummary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		kind       events.Kind
+		actorCount int64
+		want       string
+	}{
+		{
+			name:       "should render a single actor",
+			kind:       events.KindLike,
+			actorCount: 1,
+			want:       "X liked your chirp",
+		},
+		{
+			name:       "should render one other actor in the singular",
+			kind:       events.KindFollow,
+			actorCount: 2,
+			want:       "X and 1 other followed you",
+		},
+		{
+			name:       "should collapse several actors",
+			kind:       events.KindLike,
+			actorCount: 5,
+			want:       "X and 4 others liked your chirp",
+		},
+		{
+			name:       "should not name an actor for system events",
+			kind:       events.KindExportReady,
+			actorCount: 0,
+			want:       "Your data export is ready to download",
+		},
+		{
+			name:       "should ask for a new export when one failed",
+			kind:       events.KindExportFailed,
+			actorCount: 0,
+			want:       "Your data export could not be built, please request a new one",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := events.Summary(test.kind, "X", test.actorCount)
+			if got != test.want {
+				t.Fatalf("unexpected summary: got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+// Package export builds the data export archives users request under the
+// GDPR: a ZIP of JSON files holding everything we keep about them.
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/events"
+	"github.com/zyrterviews/chirpy/internal/logging"
+	"github.com/zyrterviews/chirpy/internal/pagination"
+)
+
+const (
+	// Lifetime is how long a download link stays valid after the export is
+	// requested.
+	Lifetime = 7 * 24 * time.Hour
+	// PollInterval is how often pending exports are looked for.
+	PollInterval = 30 * time.Second
+	// BuildTimeout is how long a claimed export may take to build before
+	// another builder takes it over.
+	BuildTimeout = 10 * time.Minute
+
+	tokenBytes = 32
+)
+
+// Builder turns pending export requests into archives kept in Env.Exports.
+type Builder struct {
+	Env *appenv.Env
+}
+
+// NewToken returns a random download token and the hash under which it is
+// stored.
+func NewToken() (string, string, error) {
+	raw := make([]byte, tokenBytes)
+
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("generate export token: %w", err)
+	}
+
+	token := hex.EncodeToString(raw)
+
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the stored form of a download token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// BlobKey is where the archive of an export is kept.
+func BlobKey(id uuid.UUID) string {
+	return id.String() + ".zip"
+}
+
+// Archive writes each file as indented JSON into a ZIP, in name order so the
+// output is stable.
+func Archive(files map[string]any) ([]byte, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	var buf bytes.Buffer
+
+	archive := zip.NewWriter(&buf)
+
+	for _, name := range names {
+		data, err := json.MarshalIndent(files[name], "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("encode %s: %w", name, err)
+		}
+
+		file, err := archive.Create(name)
+		if err != nil {
+			return nil, fmt.Errorf("add %s: %w", name, err)
+		}
+
+		if _, err := file.Write(data); err != nil {
+			return nil, fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("close archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// BuildPending removes stale archives, then builds every pending export and
+// notifies its owner of the outcome.
+func (b *Builder) BuildPending(ctx context.Context) error {
+	if err := b.cleanup(ctx); err != nil {
+		return err
+	}
+
+	for {
+		exp, err := b.claim(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := b.buildOne(ctx, exp); err != nil {
+			return err
+		}
+	}
+}
+
+// claim marks the oldest pending export as being built and commits right
+// away, so that no transaction stays open while the archive is uploaded.
+// Exports are picked with FOR UPDATE SKIP LOCKED, so replicas never claim
+// the same one, and a claim older than BuildTimeout is taken over.
+func (b *Builder) claim(ctx context.Context) (database.DataExport, error) {
+	claimedBefore := time.Now().UTC().Add(-BuildTimeout)
+
+	exp, err := b.Env.DB.ClaimPendingDataExport(
+		ctx,
+		sql.NullTime{Time: claimedBefore, Valid: true},
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return database.DataExport{}, fmt.Errorf("claim export: %w", err)
+	}
+
+	return exp, err
+}
+
+// buildOne builds and uploads the archive of a claimed export, then marks
+// the export ready or failed and notifies its owner in one transaction. An
+// archive whose export could not be marked ready is deleted again; the
+// claim then lapses and the export is retried.
+func (b *Builder) buildOne(ctx context.Context, exp database.DataExport) error {
+	key := BlobKey(exp.ID)
+
+	data, err := b.build(ctx, b.Env.DB, exp.UserID)
+	if err == nil {
+		err = b.Env.Exports.Put(ctx, key, "application/zip", data)
+	}
+
+	if err != nil {
+		logging.FromContext(ctx).Error(
+			"export: could not build export",
+			"export_id", exp.ID,
+			"error", err,
+		)
+
+		err = b.Env.InTx(ctx, func(qtx *database.Queries) error {
+			if err := qtx.MarkDataExportFailed(ctx, exp.ID); err != nil {
+				return err
+			}
+
+			return events.Record(ctx, qtx, events.Event{
+				Kind:      events.KindExportFailed,
+				Recipient: exp.UserID,
+				Subject:   exp.ID,
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("mark export failed: %w", err)
+		}
+
+		return nil
+	}
+
+	err = b.Env.InTx(ctx, func(qtx *database.Queries) error {
+		err := qtx.MarkDataExportReady(ctx, database.MarkDataExportReadyParams{
+			BlobKey: sql.NullString{String: key, Valid: true},
+			ID:      exp.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return events.Record(ctx, qtx, events.Event{
+			Kind:      events.KindExportReady,
+			Recipient: exp.UserID,
+			Subject:   exp.ID,
+		})
+	})
+	if err != nil {
+		if err := b.Env.Exports.Delete(ctx, key); err != nil {
+			logging.FromContext(ctx).Warn(
+				"export: could not delete archive",
+				"key", key,
+				"error", err,
+			)
+		}
+
+		return fmt.Errorf("mark export ready: %w", err)
+	}
+
+	return nil
+}
+
+// cleanup deletes expired and already downloaded exports with their
+// archives. A download cannot outlive the server's write timeout, so exports
+// claimed more recently are left alone until it has passed.
+func (b *Builder) cleanup(ctx context.Context) error {
+	downloadedBefore := time.Now().UTC().Add(-b.Env.Config.HTTP.WriteTimeout)
+
+	stale, err := b.Env.DB.DeleteStaleDataExports(
+		ctx,
+		sql.NullTime{Time: downloadedBefore, Valid: true},
+	)
+	if err != nil {
+		return fmt.Errorf("delete stale exports: %w", err)
+	}
+
+	for _, exp := range stale {
+		if !exp.BlobKey.Valid {
+			continue
+		}
+
+		if err := b.Env.Exports.Delete(ctx, exp.BlobKey.String); err != nil {
+			logging.FromContext(ctx).Warn(
+				"export: could not delete archive",
+				"key", exp.BlobKey.String,
+				"error", err,
+			)
+		}
+	}
+
+	return nil
+}
+
+type profile struct {
+	ID            uuid.UUID  `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Email         string     `json:"email"`
+	Username      string     `json:"username"`
+	DisplayName   string     `json:"display_name"`
+	Bio           string     `json:"bio"`
+	AvatarMediaID *uuid.UUID `json:"avatar_media_id"`
+	PinnedChirpID *uuid.UUID `json:"pinned_chirp_id"`
+	IsChirpyRed   bool       `json:"is_chirpy_red"`
+}
+
+type chirp struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Body      string     `json:"body"`
+	Status    string     `json:"status"`
+	PublishAt *time.Time `json:"publish_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+// session is a refresh token without the token itself.
+type session struct {
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+type subscription struct {
+	IsChirpyRed bool                `json:"is_chirpy_red"`
+	Events      []subscriptionEvent `json:"events"`
+}
+
+type subscriptionEvent struct {
+	CreatedAt time.Time `json:"created_at"`
+	Event     string    `json:"event"`
+}
+
+// build gathers everything kept about a user into an archive.
+func (b *Builder) build(
+	ctx context.Context,
+	q *database.Queries,
+	userID uuid.UUID,
+) ([]byte, error) {
+	user, err := q.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("load user: %w", err)
+	}
+
+	chirps, err := allChirps(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := q.GetRefreshTokensForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("load sessions: %w", err)
+	}
+
+	sessions := make([]session, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, session{
+			CreatedAt: token.CreatedAt,
+			ExpiresAt: token.ExpiresAt,
+			RevokedAt: nullTime(token.RevokedAt),
+		})
+	}
+
+	subscriptionEvents, err := q.GetSubscriptionEventsForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("load subscription history: %w", err)
+	}
+
+	history := subscription{
+		IsChirpyRed: user.IsChirpyRed,
+		Events:      make([]subscriptionEvent, 0, len(subscriptionEvents)),
+	}
+
+	for _, event := range subscriptionEvents {
+		history.Events = append(history.Events, subscriptionEvent{
+			CreatedAt: event.CreatedAt,
+			Event:     event.Event,
+		})
+	}
+
+	return Archive(map[string]any{
+		"profile.json": profile{
+			ID:            user.ID,
+			CreatedAt:     user.CreatedAt,
+			UpdatedAt:     user.UpdatedAt,
+			Email:         user.Email,
+			Username:      user.Username.String,
+			DisplayName:   user.DisplayName,
+			Bio:           user.Bio,
+			AvatarMediaID: nullUUID(user.AvatarMediaID),
+			PinnedChirpID: nullUUID(user.PinnedChirpID),
+			IsChirpyRed:   user.IsChirpyRed,
+		},
+		"chirps.json":       chirps,
+		"sessions.json":     sessions,
+		"subscription.json": history,
+	})
+}
+
+// allChirps loads every chirp of a user, including drafts and deleted ones.
+func allChirps(
+	ctx context.Context,
+	q *database.Queries,
+	userID uuid.UUID,
+) ([]chirp, error) {
+	chirps := []chirp{}
+
+	for offset := int32(0); ; offset += pagination.MaxLimit {
+		page, err := q.GetAllChirpsForUserIncludingDeleted(
+			ctx,
+			database.GetAllChirpsForUserIncludingDeletedParams{
+				UserID:    userID,
+				RowLimit:  pagination.MaxLimit,
+				RowOffset: offset,
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("load chirps: %w", err)
+		}
+
+		for _, c := range page {
+			chirps = append(chirps, chirp{
+				ID:        c.ID,
+				CreatedAt: c.CreatedAt,
+				UpdatedAt: c.UpdatedAt,
+				Body:      c.Body,
+				Status:    c.Status,
+				PublishAt: nullTime(c.PublishAt),
+				DeletedAt: nullTime(c.DeletedAt),
+			})
+		}
+
+		if int32(len(page)) < pagination.MaxLimit { //nolint:gosec
+			return chirps, nil
+		}
+	}
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+
+	return &t.Time
+}
+
+func nullUUID(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+
+	return &id.UUID
+}
@@ -0,0 +1,81 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/zyrterviews/chirpy/internal/export"
+)
+
+func TestArchive(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should write every file as JSON in name order", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := export.Archive(map[string]any{
+			"profile.json": map[string]string{"email": "a@example.com"},
+			"chirps.json":  []string{"hello"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(archive.File) != 2 {
+			t.Fatalf("unexpected file count: got %d, want 2", len(archive.File))
+		}
+
+		if archive.File[0].Name != "chirps.json" {
+			t.Fatalf("unexpected first file: got %q", archive.File[0].Name)
+		}
+
+		file, err := archive.File[1].Open()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer file.Close()
+
+		raw, err := io.ReadAll(file)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var profile map[string]string
+		if err := json.Unmarshal(raw, &profile); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if profile["email"] != "a@example.com" {
+			t.Fatalf("unexpected profile: %v", profile)
+		}
+	})
+}
+
+func TestNewToken(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should return the hash of the token", func(t *testing.T) {
+		t.Parallel()
+
+		token, hash, err := export.NewToken()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if export.HashToken(token) != hash {
+			t.Fatalf("hash does not match token")
+		}
+
+		if hash == token {
+			t.Fatalf("hash must differ from the token")
+		}
+	})
+}
@@ -0,0 +1,215 @@
+// Package health answers liveness and readiness probes. Liveness only says
+// that the process serves HTTP; readiness also checks the database,
+// migrations and background workers, and fails while the server shuts down
+// so that load balancers stop routing to it first.
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zyrterviews/chirpy/internal/logging"
+	"github.com/zyrterviews/chirpy/internal/migrate"
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/worker"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// DefaultTimeout bounds each readiness check when Checker.Timeout is zero.
+const DefaultTimeout = 2 * time.Second
+
+// ShutdownCheck names the entry reported in place of every other check once
+// the server is shutting down.
+const ShutdownCheck = "shutdown"
+
+// Check is one dependency readiness depends on.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Result is what /readyz shows of a check. Probes are public, so why a
+// check failed is only logged.
+type Result struct {
+	Status string `json:"status"`
+}
+
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Checker runs every Check concurrently, each within Timeout.
+type Checker struct {
+	Timeout time.Duration
+	Checks  []Check
+
+	shuttingDown atomic.Bool
+}
+
+// ShutDown makes every later readiness report fail.
+func (c *Checker) ShutDown() {
+	c.shuttingDown.Store(true)
+}
+
+func (c *Checker) Ready(ctx context.Context) Report {
+	if c.shuttingDown.Load() {
+		return Report{
+			Status: StatusFail,
+			Checks: map[string]Result{
+				ShutdownCheck: {Status: StatusFail},
+			},
+		}
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	results := make([]Result, len(c.Checks))
+
+	var wg sync.WaitGroup
+
+	for i, check := range c.Checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i] = run(ctx, check, timeout)
+		}()
+	}
+
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result)}
+
+	for i, check := range c.Checks {
+		report.Checks[check.Name] = results[i]
+
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	return report
+}
+
+func run(ctx context.Context, check Check, timeout time.Duration) Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+
+	// A check that ignores its context still cannot hold up the probe.
+	go func() {
+		done <- check.Run(ctx)
+	}()
+
+	var err error
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+
+	if err != nil {
+		logging.FromContext(ctx).Warn(
+			"health: check failed",
+			"check", check.Name,
+			"error", err,
+			"duration_ms", float64(time.Since(start).Microseconds())/1000, //nolint:mnd
+		)
+
+		return Result{Status: StatusFail}
+	}
+
+	return Result{Status: StatusOK}
+}
+
+// Database checks that db answers a ping.
+func Database(db *sql.DB) Check {
+	return Check{Name: "database", Run: db.PingContext}
+}
+
+// Migrations checks that every migration known to runner has been applied.
+func Migrations(runner *migrate.Runner) Check {
+	return Check{
+		Name: "migrations",
+		Run: func(ctx context.Context) error {
+			pending, err := runner.Pending(ctx)
+			if err != nil {
+				return err
+			}
+
+			if len(pending) > 0 {
+				return fmt.Errorf(
+					"%d pending, expected version %d",
+					len(pending),
+					pending[len(pending)-1].Version,
+				)
+			}
+
+			return nil
+		},
+	}
+}
+
+// Worker checks that a background worker is running and not stalled.
+func Worker(periodic *worker.Periodic) Check {
+	return Check{Name: "worker:" + periodic.Name, Run: periodic.Check}
+}
+
+// GET /livez
+func GetLivez() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		writeJSON(writer, req, Report{Status: StatusOK}, http.StatusOK)
+	})
+}
+
+// GET /readyz
+func GetReadyz(checker *Checker) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		report := checker.Ready(req.Context())
+
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+
+		writeJSON(writer, req, report, status)
+	})
+}
+
+func writeJSON(
+	writer http.ResponseWriter,
+	req *http.Request,
+	data any,
+	status int,
+) {
+	res, err := json.Marshal(data)
+	if err != nil {
+		problem.Internal(writer, req, err)
+
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Cache-Control", "no-store")
+	writer.WriteHeader(status)
+
+	_, _ = writer.Write(res)
+}
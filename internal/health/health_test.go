@@ -0,0 +1,134 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zyrterviews/chirpy/internal/health"
+)
+
+var errDown = errors.New("connection refused")
+
+func check(name string, err error) health.Check {
+	return health.Check{
+		Name: name,
+		Run:  func(context.Context) error { return err },
+	}
+}
+
+func readyz(t *testing.T, checker *health.Checker) (int, health.Report) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	health.GetReadyz(checker).ServeHTTP(
+		rec,
+		httptest.NewRequest(http.MethodGet, "/readyz", nil),
+	)
+
+	var report health.Report
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return rec.Code, report
+}
+
+func TestReadyz(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should be ready when every check passes", func(t *testing.T) {
+		t.Parallel()
+
+		status, report := readyz(t, &health.Checker{
+			Checks: []health.Check{check("database", nil), check("migrations", nil)},
+		})
+
+		if status != http.StatusOK || report.Status != health.StatusOK {
+			t.Fatalf("unexpected readiness: %d %+v", status, report)
+		}
+
+		if len(report.Checks) != 2 {
+			t.Fatalf("expected 2 checks, got %+v", report.Checks)
+		}
+	})
+
+	t.Run("should report which check failed", func(t *testing.T) {
+		t.Parallel()
+
+		status, report := readyz(t, &health.Checker{
+			Checks: []health.Check{check("database", errDown), check("migrations", nil)},
+		})
+
+		if status != http.StatusServiceUnavailable || report.Status != health.StatusFail {
+			t.Fatalf("unexpected readiness: %d %+v", status, report)
+		}
+
+		if got := report.Checks["database"]; got.Status != health.StatusFail {
+			t.Fatalf("unexpected database result: %+v", got)
+		}
+
+		if got := report.Checks["migrations"]; got.Status != health.StatusOK {
+			t.Fatalf("unexpected migrations result: %+v", got)
+		}
+	})
+
+	t.Run("should keep why a check failed private", func(t *testing.T) {
+		t.Parallel()
+
+		rec := httptest.NewRecorder()
+		health.GetReadyz(&health.Checker{
+			Checks: []health.Check{check("database", errDown)},
+		}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		if strings.Contains(rec.Body.String(), errDown.Error()) {
+			t.Fatalf("unexpected error in body: %s", rec.Body.String())
+		}
+	})
+
+	t.Run("should time out slow checks", func(t *testing.T) {
+		t.Parallel()
+
+		blocked := make(chan struct{})
+		defer close(blocked)
+
+		status, report := readyz(t, &health.Checker{
+			Timeout: 10 * time.Millisecond,
+			Checks: []health.Check{{
+				Name: "database",
+				Run: func(context.Context) error {
+					<-blocked
+
+					return nil
+				},
+			}},
+		})
+
+		if status != http.StatusServiceUnavailable || report.Checks["database"].Status != health.StatusFail {
+			t.Fatalf("unexpected readiness: %d %+v", status, report)
+		}
+	})
+
+	t.Run("should not be ready while shutting down", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &health.Checker{Checks: []health.Check{check("database", nil)}}
+		checker.ShutDown()
+
+		status, report := readyz(t, checker)
+
+		if status != http.StatusServiceUnavailable {
+			t.Fatalf("unexpected status: %d", status)
+		}
+
+		if _, ok := report.Checks[health.ShutdownCheck]; !ok || len(report.Checks) != 1 {
+			t.Fatalf("expected only the shutdown check, got %+v", report.Checks)
+		}
+	})
+}
@@ -0,0 +1,140 @@
+// Package logging sets up chirpy's structured JSON logs and carries
+// per-request details, such as the request ID, through request contexts.
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is read from incoming requests and set on every response.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs accepted from clients.
+const maxRequestIDLength = 128
+
+type contextKey struct{}
+
+// Request holds what is known about the request being served. The user ID
+// is filled in by authentication, after the request has been logged as
+// started.
+type Request struct {
+	ID     string
+	UserID uuid.UUID
+	Logger *slog.Logger
+}
+
+// New returns a JSON logger writing to out at the given level: debug, info,
+// warn or error.
+func New(out io.Writer, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level) //nolint:err113
+	}
+
+	return slog.New(slog.NewJSONHandler(out, &slog.HandlerOptions{
+		Level: lvl,
+	})), nil
+}
+
+// WithRequest returns a context carrying req.
+func WithRequest(ctx context.Context, req *Request) context.Context {
+	return context.WithValue(ctx, contextKey{}, req)
+}
+
+// RequestFromContext returns the request carried by ctx, or nil outside of
+// a request.
+func RequestFromContext(ctx context.Context) *Request {
+	req, _ := ctx.Value(contextKey{}).(*Request)
+
+	return req
+}
+
+// FromContext returns the logger of the request carried by ctx, tagged with
+// its request ID, or the default logger outside of a request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if req := RequestFromContext(ctx); req != nil && req.Logger != nil {
+		return req.Logger
+	}
+
+	return slog.Default()
+}
+
+// SetUserID records who the request carried by ctx was authenticated as.
+func SetUserID(ctx context.Context, userID uuid.UUID) {
+	if req := RequestFromContext(ctx); req != nil {
+		req.UserID = userID
+	}
+}
+
+// RequestID returns the ID a client sent, if it is safe to log and echo
+// back, or a new random one.
+func RequestID(incoming string) string {
+	if incoming == "" || len(incoming) > maxRequestIDLength {
+		return uuid.NewString()
+	}
+
+	for _, r := range incoming {
+		if !isRequestIDRune(r) {
+			return uuid.NewString()
+		}
+	}
+
+	return incoming
+}
+
+func isRequestIDRune(r rune) bool {
+	return r >= 'a' && r <= 'z' ||
+		r >= 'A' && r <= 'Z' ||
+		r >= '0' && r <= '9' ||
+		strings.ContainsRune("-_.:", r)
+}
+
+// AnnotateErrorBody adds the request ID to an error response body: as a
+// request_id field of a JSON object, including problem details, or as a last
+// line of plain text. Other bodies are returned unchanged.
+func AnnotateErrorBody(body []byte, contentType, requestID string) []byte {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch mediaType {
+	case "application/json", "application/problem+json":
+		var fields map[string]json.RawMessage
+
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return body
+		}
+
+		id, _ := json.Marshal(requestID)
+		fields["request_id"] = id
+
+		annotated, err := json.Marshal(fields)
+		if err != nil {
+			return body
+		}
+
+		return append(annotated, '\n')
+	case "text/plain":
+		var buf bytes.Buffer
+
+		buf.Write(body)
+
+		if len(body) > 0 && body[len(body)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+
+		buf.WriteString("request_id: " + requestID + "\n")
+
+		return buf.Bytes()
+	default:
+		return body
+	}
+}
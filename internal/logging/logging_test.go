@@ -0,0 +1,161 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/logging"
+)
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should keep a well-formed incoming ID", func(t *testing.T) {
+		t.Parallel()
+
+		if got := logging.RequestID("edge-1:abc_DEF.42"); got != "edge-1:abc_DEF.42" {
+			t.Fatalf("unexpected request ID: %q", got)
+		}
+	})
+
+	t.Run("should replace missing or unsafe IDs", func(t *testing.T) {
+		t.Parallel()
+
+		for _, incoming := range []string{
+			"",
+			"two words",
+			"line\nbreak",
+			strings.Repeat("a", 129),
+		} {
+			got := logging.RequestID(incoming)
+
+			if _, err := uuid.Parse(got); err != nil {
+				t.Fatalf("expected a generated ID for %q, got %q", incoming, got)
+			}
+		}
+	})
+}
+
+func TestAnnotateErrorBody(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should add a field to a JSON object", func(t *testing.T) {
+		t.Parallel()
+
+		body := logging.AnnotateErrorBody(
+			[]byte(`{"error":"Chirp is too long"}`),
+			"application/json",
+			"req-1",
+		)
+
+		var got map[string]string
+
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got["error"] != "Chirp is too long" || got["request_id"] != "req-1" {
+			t.Fatalf("unexpected body: %s", body)
+		}
+	})
+
+	t.Run("should add a field to problem details", func(t *testing.T) {
+		t.Parallel()
+
+		body := logging.AnnotateErrorBody(
+			[]byte(`{"status":404,"code":"not_found"}`),
+			"application/problem+json",
+			"req-1",
+		)
+
+		if !strings.Contains(string(body), `"request_id":"req-1"`) {
+			t.Fatalf("unexpected body: %s", body)
+		}
+	})
+
+	t.Run("should append a line to plain text", func(t *testing.T) {
+		t.Parallel()
+
+		body := logging.AnnotateErrorBody(
+			[]byte("UNAUTHORIZED\n"),
+			"text/plain; charset=utf-8",
+			"req-1",
+		)
+
+		if string(body) != "UNAUTHORIZED\nrequest_id: req-1\n" {
+			t.Fatalf("unexpected body: %q", body)
+		}
+	})
+
+	t.Run("should leave other bodies alone", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tc := range []struct{ body, contentType string }{
+			{`["not", "an", "object"]`, "application/json"},
+			{"<h1>Not Found</h1>", "text/html"},
+		} {
+			body := logging.AnnotateErrorBody([]byte(tc.body), tc.contentType, "req-1")
+
+			if string(body) != tc.body {
+				t.Fatalf("unexpected body: %q", body)
+			}
+		}
+	})
+}
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should fall back to the default logger", func(t *testing.T) {
+		t.Parallel()
+
+		if logging.FromContext(context.Background()) != slog.Default() {
+			t.Fatal("expected the default logger")
+		}
+	})
+
+	t.Run("should return the request logger", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+
+		logger, err := logging.New(&out, "info")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		req := &logging.Request{ID: "req-1"}
+		req.Logger = logger.With("request_id", req.ID)
+
+		ctx := logging.WithRequest(context.Background(), req)
+		logging.FromContext(ctx).Info("hello")
+
+		if !strings.Contains(out.String(), `"request_id":"req-1"`) {
+			t.Fatalf("expected the request ID in %q", out.String())
+		}
+
+		userID := uuid.New()
+		logging.SetUserID(ctx, userID)
+
+		if req.UserID != userID {
+			t.Fatalf("unexpected user ID: %v", req.UserID)
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should reject an unknown level", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := logging.New(&bytes.Buffer{}, "loud"); err == nil {
+			t.Fatal("expected an error, but got none")
+		}
+	})
+}
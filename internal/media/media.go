@@ -0,0 +1,146 @@
+// Package media validates uploaded images and prepares them for storage:
+// metadata such as EXIF is stripped by re-encoding, and a thumbnail is
+// generated alongside the original.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// MaxUploadSize is the largest image accepted, in bytes.
+	MaxUploadSize int64 = 5 << 20
+	// MaxAttachments is how many images a single chirp can reference.
+	MaxAttachments int = 4
+	// ThumbnailSize bounds both sides of generated thumbnails, in pixels.
+	ThumbnailSize int = 320
+	// maxDimension and maxPixels guard against decompression bombs: small
+	// files that decode into huge images.
+	maxDimension int = 8192
+	maxPixels    int = 40_000_000
+	jpegQuality  int = 90
+)
+
+var (
+	ErrUnsupportedType = errors.New("only JPEG and PNG images are supported")
+	ErrTooLarge        = fmt.Errorf(
+		"image must be at most %d bytes, %dx%d and %d megapixels",
+		MaxUploadSize,
+		maxDimension,
+		maxDimension,
+		maxPixels/1_000_000,
+	)
+)
+
+type Image struct {
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+	Thumbnail   []byte
+}
+
+// Process validates data as a supported image and returns a clean copy of it
+// along with its thumbnail. The content type is sniffed from the bytes
+// themselves; whatever the client claimed is ignored.
+func Process(data []byte) (*Image, error) {
+	if int64(len(data)) > MaxUploadSize {
+		return nil, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return nil, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+
+	if config.Width > maxDimension ||
+		config.Height > maxDimension ||
+		config.Width*config.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+
+	// Re-encoding keeps only the pixels, which drops EXIF, XMP and any other
+	// metadata the original file carried. The orientation is applied first
+	// so that photos taken sideways are not stored that way.
+	img = orient(img, orientation(data))
+
+	clean, err := encode(img, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnail, err := encode(thumbnail(img), contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Image{
+		ContentType: contentType,
+		Data:        clean,
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		Thumbnail:   thumbnail,
+	}, nil
+}
+
+func encode(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var err error
+
+	switch contentType {
+	case "image/png":
+		err = png.Encode(&buf, img)
+	default:
+		//nolint:exhaustruct
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("encode image: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// thumbnail scales img down to fit in a ThumbnailSize square, keeping its
+// aspect ratio. Images that already fit are returned as is.
+func thumbnail(img image.Image) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width <= ThumbnailSize && height <= ThumbnailSize {
+		return img
+	}
+
+	if width >= height {
+		height = max(1, height*ThumbnailSize/width)
+		width = ThumbnailSize
+	} else {
+		width = max(1, width*ThumbnailSize/height)
+		height = ThumbnailSize
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+	return dst
+}
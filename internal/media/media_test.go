@@ -0,0 +1,211 @@
+package media_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/zyrterviews/chirpy/internal/media"
+)
+
+func newJPEGWithEXIF(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := range width {
+		for y := range height {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 0x80, A: 0xff}) //nolint:gosec
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Splice an APP1 EXIF segment right after the SOI marker.
+	payload := append([]byte("Exif\x00\x00"), []byte("GPS 48.8584 N 2.2945 E")...)
+	segment := []byte{0xff, 0xe1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}
+	segment = append(segment, payload...)
+
+	raw := buf.Bytes()
+
+	return append(append([]byte{}, raw[:2]...), append(segment, raw[2:]...)...)
+}
+
+// newRotatedJPEG returns a 40x20 JPEG, red on the left and blue on the
+// right, whose EXIF says to rotate it 90 degrees clockwise.
+func newRotatedJPEG(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for x := range 40 {
+		for y := range 20 {
+			c := color.RGBA{R: 0xff, A: 0xff}
+			if x >= 20 {
+				c = color.RGBA{B: 0xff, A: 0xff}
+			}
+
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A little-endian TIFF header, then an IFD holding only the
+	// orientation, a SHORT set to 6.
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x01\x00")
+	tiff = append(tiff, 0x12, 0x01, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, 0x06, 0x00, 0x00, 0x00)
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xff, 0xe1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}
+	segment = append(segment, payload...)
+
+	raw := buf.Bytes()
+
+	return append(append([]byte{}, raw[:2]...), append(segment, raw[2:]...)...)
+}
+
+// pngHeader returns the start of a PNG claiming the given size, which is
+// all that is read before its size is checked.
+func pngHeader(width, height uint32) []byte {
+	ihdr := []byte("IHDR")
+	ihdr = binary.BigEndian.AppendUint32(ihdr, width)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, height)
+	ihdr = append(ihdr, 8, 2, 0, 0, 0) // 8-bit RGB.
+
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, uint32(len(ihdr)-4)) //nolint:gosec
+	data = append(data, ihdr...)
+
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
+
+func TestProcess(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should strip EXIF metadata", func(t *testing.T) {
+		t.Parallel()
+
+		data := newJPEGWithEXIF(t, 16, 16)
+		if !bytes.Contains(data, []byte("Exif")) {
+			t.Fatal("test image should contain EXIF data")
+		}
+
+		img, err := media.Process(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if bytes.Contains(img.Data, []byte("Exif")) || bytes.Contains(img.Data, []byte("GPS")) {
+			t.Fatal("expected EXIF data to be stripped")
+		}
+
+		if img.ContentType != "image/jpeg" {
+			t.Fatalf("unexpected content type: %q", img.ContentType)
+		}
+	})
+
+	t.Run("should generate a thumbnail that keeps the aspect ratio", func(t *testing.T) {
+		t.Parallel()
+
+		img, err := media.Process(newJPEGWithEXIF(t, 640, 480))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		thumb, err := jpeg.DecodeConfig(bytes.NewReader(img.Thumbnail))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if thumb.Width != media.ThumbnailSize || thumb.Height != 240 {
+			t.Fatalf("unexpected thumbnail size: %dx%d", thumb.Width, thumb.Height)
+		}
+
+		if img.Width != 640 || img.Height != 480 {
+			t.Fatalf("unexpected image size: %dx%d", img.Width, img.Height)
+		}
+	})
+
+	t.Run("should keep PNG images as PNG", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		img, err := media.Process(buf.Bytes())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if img.ContentType != "image/png" {
+			t.Fatalf("unexpected content type: %q", img.ContentType)
+		}
+	})
+
+	t.Run("should reject files that are not images", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := media.Process([]byte("<html><body>hi</body></html>"))
+		if !errors.Is(err, media.ErrUnsupportedType) {
+			t.Fatalf("expected ErrUnsupportedType, got: %v", err)
+		}
+	})
+
+	t.Run("should reject files over the size limit", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := media.Process(make([]byte, media.MaxUploadSize+1))
+		if !errors.Is(err, media.ErrTooLarge) {
+			t.Fatalf("expected ErrTooLarge, got: %v", err)
+		}
+	})
+	t.Run("should apply the EXIF orientation", func(t *testing.T) {
+		t.Parallel()
+
+		img, err := media.Process(newRotatedJPEG(t))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if img.Width != 20 || img.Height != 40 {
+			t.Fatalf("unexpected size: %dx%d", img.Width, img.Height)
+		}
+
+		decoded, err := jpeg.Decode(bytes.NewReader(img.Data))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// The left half, turned clockwise, is now on top.
+		if r, _, b, _ := decoded.At(10, 5).RGBA(); r < b {
+			t.Fatalf("expected red on top, got r=%d b=%d", r, b)
+		}
+
+		if r, _, b, _ := decoded.At(10, 35).RGBA(); b < r {
+			t.Fatalf("expected blue at the bottom, got r=%d b=%d", r, b)
+		}
+	})
+
+	t.Run("should reject images over the pixel limit", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := media.Process(pngHeader(8000, 6000))
+		if !errors.Is(err, media.ErrTooLarge) {
+			t.Fatalf("expected ErrTooLarge, got: %v", err)
+		}
+	})
+}
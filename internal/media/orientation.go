@@ -0,0 +1,168 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// EXIF orientations, from the TIFF spec. Each names where the first row and
+// column of the stored pixels are meant to be displayed.
+const (
+	orientationNormal     = 1
+	orientationFlipH      = 2
+	orientationRotate180  = 3
+	orientationFlipV      = 4
+	orientationTranspose  = 5
+	orientationRotate90   = 6
+	orientationTransverse = 7
+	orientationRotate270  = 8
+)
+
+const (
+	jpegSOI         = 0xd8
+	jpegSOS         = 0xda
+	jpegAPP1        = 0xe1
+	tiffTagOrient   = 0x0112
+	tiffEntrySize   = 12
+	tiffHeaderSize  = 8
+	exifHeaderBytes = "Exif\x00\x00"
+)
+
+// orientation returns the EXIF orientation of a JPEG, or orientationNormal
+// when it has none or its EXIF cannot be read.
+func orientation(data []byte) int {
+	if len(data) < 2 || data[0] != 0xff || data[1] != jpegSOI {
+		return orientationNormal
+	}
+
+	// Walk the segments up to the image data, looking for the APP1 one
+	// holding EXIF.
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xff {
+			return orientationNormal
+		}
+
+		marker := data[pos+1]
+		if marker == jpegSOS {
+			return orientationNormal
+		}
+
+		size := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if size < 2 || pos+2+size > len(data) {
+			return orientationNormal
+		}
+
+		segment := data[pos+4 : pos+2+size]
+		if marker == jpegAPP1 && bytes.HasPrefix(segment, []byte(exifHeaderBytes)) {
+			return tiffOrientation(segment[len(exifHeaderBytes):])
+		}
+
+		pos += 2 + size
+	}
+
+	return orientationNormal
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of a TIFF
+// structure, as embedded in EXIF.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < tiffHeaderSize {
+		return orientationNormal
+	}
+
+	var order binary.ByteOrder
+
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return orientationNormal
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < tiffHeaderSize || ifd+2 > len(tiff) {
+		return orientationNormal
+	}
+
+	count := int(order.Uint16(tiff[ifd:]))
+
+	for i := range count {
+		entry := ifd + 2 + i*tiffEntrySize
+		if entry+tiffEntrySize > len(tiff) {
+			break
+		}
+
+		if order.Uint16(tiff[entry:]) != tiffTagOrient {
+			continue
+		}
+
+		// A SHORT value sits in the first bytes of the value field.
+		value := int(order.Uint16(tiff[entry+8:]))
+		if value < orientationNormal || value > orientationRotate270 {
+			return orientationNormal
+		}
+
+		return value
+	}
+
+	return orientationNormal
+}
+
+// orient returns img turned the way its EXIF orientation says it should be
+// displayed, as re-encoding drops the tag.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation == orientationNormal {
+		return img
+	}
+
+	bounds := img.Bounds()
+
+	src, ok := img.(*image.RGBA)
+	if !ok {
+		src = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	}
+
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+
+	dstWidth, dstHeight := width, height
+	if orientation >= orientationTranspose {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := range height {
+		for x := range width {
+			var dx, dy int
+
+			switch orientation {
+			case orientationFlipH:
+				dx, dy = width-1-x, y
+			case orientationRotate180:
+				dx, dy = width-1-x, height-1-y
+			case orientationFlipV:
+				dx, dy = x, height-1-y
+			case orientationTranspose:
+				dx, dy = y, x
+			case orientationRotate90:
+				dx, dy = height-1-y, x
+			case orientationTransverse:
+				dx, dy = height-1-y, width-1-x
+			case orientationRotate270:
+				dx, dy = y, width-1-x
+			}
+
+			from := src.PixOffset(src.Rect.Min.X+x, src.Rect.Min.Y+y)
+			to := dst.PixOffset(dx, dy)
+			copy(dst.Pix[to:to+4], src.Pix[from:from+4])
+		}
+	}
+
+	return dst
+}
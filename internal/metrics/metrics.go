@@ -0,0 +1,194 @@
+// Package metrics collects chirpy's Prometheus metrics and serves them in the
+// text exposition format.
+package metrics
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/expfmt"
+)
+
+const namespace = "chirpy"
+
+// UnmatchedRoute labels requests that matched no route, so that scanners
+// probing random paths cannot blow up the label set.
+const UnmatchedRoute = "unmatched"
+
+// Webhook outcomes.
+const (
+	WebhookUpgraded     = "upgraded"
+	WebhookIgnored      = "ignored"
+	WebhookUnauthorized = "unauthorized"
+	WebhookInvalid      = "invalid"
+	WebhookUnknownUser  = "unknown_user"
+	WebhookError        = "error"
+)
+
+// requestBuckets extends the default buckets past the 25s long poll.
+var requestBuckets = []float64{
+	.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30,
+}
+
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requests       *prometheus.CounterVec
+	duration       *prometheus.HistogramVec
+	chirpsCreated  *prometheus.CounterVec
+	logins         *prometheus.CounterVec
+	webhooks       *prometheus.CounterVec
+	rateLimited    *prometheus.CounterVec
+	fileserverHits atomic.Int64
+}
+
+// New registers chirpy's metrics, along with Go runtime, process and db
+// connection pool statistics, on a fresh registry.
+func New(db *sql.DB) *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by route pattern and status.",
+		}, []string{"route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency, by route pattern and status.",
+			Buckets:   requestBuckets,
+		}, []string{"route", "status"}),
+		chirpsCreated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "chirps_created_total",
+			Help:      "Chirps created, by initial status.",
+		}, []string{"status"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_total",
+			Help:      "Login attempts, by result.",
+		}, []string{"result"}),
+		webhooks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "webhooks_total",
+			Help:      "Polka webhook deliveries, by outcome.",
+		}, []string{"outcome"}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limited_requests_total",
+			Help:      "Requests refused by a rate limit, by policy.",
+		}, []string{"policy"}),
+	}
+
+	m.Registry.MustRegister(
+		m.requests,
+		m.duration,
+		m.chirpsCreated,
+		m.logins,
+		m.webhooks,
+		m.rateLimited,
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "fileserver_hits_total",
+			Help:      "Requests served under /app/.",
+		}, func() float64 {
+			return float64(m.fileserverHits.Load())
+		}),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	if db != nil {
+		m.Registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+	}
+
+	return m
+}
+
+// TrackLongPolls exposes the number of direct message long polls currently
+// waiting, as reported by waiting.
+func (m *Metrics) TrackLongPolls(waiting func() int) {
+	m.Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "long_polls_active",
+		Help:      "Direct message long polls currently waiting.",
+	}, func() float64 {
+		return float64(waiting())
+	}))
+}
+
+func (m *Metrics) ObserveRequest(route string, status int, elapsed time.Duration) {
+	if route == "" {
+		route = UnmatchedRoute
+	}
+
+	code := strconv.Itoa(status)
+
+	m.requests.WithLabelValues(route, code).Inc()
+	m.duration.WithLabelValues(route, code).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) ChirpCreated(status string) {
+	m.chirpsCreated.WithLabelValues(status).Inc()
+}
+
+func (m *Metrics) Login(succeeded bool) {
+	result := "failed"
+	if succeeded {
+		result = "succeeded"
+	}
+
+	m.logins.WithLabelValues(result).Inc()
+}
+
+func (m *Metrics) Webhook(outcome string) {
+	m.webhooks.WithLabelValues(outcome).Inc()
+}
+
+func (m *Metrics) RateLimited(policy string) {
+	m.rateLimited.WithLabelValues(policy).Inc()
+}
+
+func (m *Metrics) FileserverHit() {
+	m.fileserverHits.Add(1)
+}
+
+func (m *Metrics) FileserverHits() int64 {
+	return m.fileserverHits.Load()
+}
+
+// ResetFileserverHits zeroes the hit counter for POST /admin/reset.
+// Prometheus treats it like a process restart.
+func (m *Metrics) ResetFileserverHits() {
+	m.fileserverHits.Store(0)
+}
+
+// Handler serves the registry at /metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+}
+
+// WriteText writes every metric in the text exposition format.
+func (m *Metrics) WriteText(out io.Writer) error {
+	families, err := m.Registry.Gather()
+	if err != nil {
+		return err
+	}
+
+	encoder := expfmt.NewEncoder(out, expfmt.NewFormat(expfmt.TypeTextPlain))
+
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zyrterviews/chirpy/internal/metrics"
+)
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+
+	return rec.Body.String()
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should count requests per route and status", func(t *testing.T) {
+		t.Parallel()
+
+		m := metrics.New(nil)
+		m.ObserveRequest("GET /api/chirps", http.StatusOK, 30*time.Millisecond)
+		m.ObserveRequest("GET /api/chirps", http.StatusOK, 60*time.Millisecond)
+		m.ObserveRequest("", http.StatusNotFound, time.Millisecond)
+
+		out := scrape(t, m)
+
+		for _, want := range []string{
+			`chirpy_http_requests_total{route="GET /api/chirps",status="200"} 2`,
+			`chirpy_http_requests_total{route="unmatched",status="404"} 1`,
+			`chirpy_http_request_duration_seconds_count{route="GET /api/chirps",status="200"} 2`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Fatalf("expected %q in:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("should expose application counters", func(t *testing.T) {
+		t.Parallel()
+
+		m := metrics.New(nil)
+		m.ChirpCreated("published")
+		m.Login(true)
+		m.Login(false)
+		m.Login(false)
+		m.Webhook(metrics.WebhookUpgraded)
+		m.RateLimited("login")
+		m.TrackLongPolls(func() int { return 3 })
+
+		out := scrape(t, m)
+
+		for _, want := range []string{
+			`chirpy_chirps_created_total{status="published"} 1`,
+			`chirpy_logins_total{result="succeeded"} 1`,
+			`chirpy_logins_total{result="failed"} 2`,
+			`chirpy_webhooks_total{outcome="upgraded"} 1`,
+			`chirpy_rate_limited_requests_total{policy="login"} 1`,
+			`chirpy_long_polls_active 3`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Fatalf("expected %q in:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("should reset fileserver hits", func(t *testing.T) {
+		t.Parallel()
+
+		m := metrics.New(nil)
+		m.FileserverHit()
+		m.FileserverHit()
+
+		if got := m.FileserverHits(); got != 2 {
+			t.Fatalf("expected 2 hits, got %d", got)
+		}
+
+		m.ResetFileserverHits()
+
+		var out strings.Builder
+
+		if err := m.WriteText(&out); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !strings.Contains(out.String(), "chirpy_fileserver_hits_total 0") {
+			t.Fatalf("expected no hits in:\n%s", out.String())
+		}
+	})
+}
@@ -0,0 +1,277 @@
+package middleware_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/config"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/middleware"
+	"github.com/zyrterviews/chirpy/internal/tracing"
+)
+
+const jwtSecret = "test-secret"
+
+// users is an in-memory stand-in for the users table. It answers
+// GetUserByID for the users it holds, which is all authentication asks of
+// the database; deleted users are simply absent.
+type users struct {
+	mu  sync.Mutex
+	ids map[string]bool
+}
+
+func newUsers(ids ...uuid.UUID) *users {
+	u := &users{ids: map[string]bool{}}
+	for _, id := range ids {
+		u.add(id)
+	}
+
+	return u
+}
+
+func (u *users) add(id uuid.UUID) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.ids[id.String()] = true
+}
+
+func (u *users) Connect(context.Context) (driver.Conn, error) {
+	return usersConn{users: u}, nil
+}
+
+func (u *users) Driver() driver.Driver {
+	return nil
+}
+
+type usersConn struct {
+	users *users
+}
+
+func (c usersConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c usersConn) Close() error {
+	return nil
+}
+
+func (c usersConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+// CheckNamedValue converts UUIDs to the strings Postgres would receive.
+func (c usersConn) CheckNamedValue(value *driver.NamedValue) error {
+	if valuer, ok := value.Value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return err
+		}
+
+		value.Value = v
+	}
+
+	return nil
+}
+
+func (c usersConn) QueryContext(
+	_ context.Context,
+	query string,
+	args []driver.NamedValue,
+) (driver.Rows, error) {
+	if name := tracing.QueryName(query); name != "GetUserByID" {
+		return nil, fmt.Errorf("unexpected query %s", name)
+	}
+
+	id, _ := args[0].Value.(string)
+
+	c.users.mu.Lock()
+	defer c.users.mu.Unlock()
+
+	if !c.users.ids[id] {
+		return &userRows{}, nil
+	}
+
+	now := time.Now()
+
+	return &userRows{row: []driver.Value{
+		id, now, now, "", "", false, nil, "", "", nil, nil, nil, false,
+	}}, nil
+}
+
+// userColumns is how many columns the users table has.
+const userColumns = 13
+
+type userRows struct {
+	row []driver.Value
+}
+
+func (r *userRows) Columns() []string {
+	columns := make([]string, userColumns)
+	for i := range columns {
+		columns[i] = fmt.Sprintf("column%d", i)
+	}
+
+	return columns
+}
+
+func (r *userRows) Close() error {
+	return nil
+}
+
+func (r *userRows) Next(dest []driver.Value) error {
+	if r.row == nil {
+		return io.EOF
+	}
+
+	copy(dest, r.row)
+	r.row = nil
+
+	return nil
+}
+
+// authEnv returns an environment whose database holds data.
+func authEnv(t *testing.T, data *users) *appenv.Env {
+	t.Helper()
+
+	db := sql.OpenDB(data)
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	return &appenv.Env{
+		DB:     database.New(db),
+		SQL:    db,
+		Config: &config.Config{JWTSecret: jwtSecret},
+	}
+}
+
+// bearer returns an Authorization header value for userID.
+func bearer(t *testing.T, userID uuid.UUID) string {
+	t.Helper()
+
+	token, err := auth.MakeJWT(userID, jwtSecret, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return "Bearer " + token
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	handler := func(env *appenv.Env) http.Handler {
+		return middleware.Chain(env,
+			middleware.Authenticate,
+			middleware.New(http.HandlerFunc(
+				func(writer http.ResponseWriter, _ *http.Request) {
+					writer.WriteHeader(http.StatusNoContent)
+				},
+			)),
+		)
+	}
+
+	t.Run("should let an existing user through", func(t *testing.T) {
+		t.Parallel()
+
+		userID := uuid.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		req.Header.Set("Authorization", bearer(t, userID))
+
+		rec := httptest.NewRecorder()
+		handler(authEnv(t, newUsers(userID))).ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("unexpected status: got %d, want %d", rec.Code, http.StatusNoContent)
+		}
+	})
+
+	t.Run("should refuse the token of a deleted user", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		req.Header.Set("Authorization", bearer(t, uuid.New()))
+
+		rec := httptest.NewRecorder()
+		handler(authEnv(t, newUsers())).ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("unexpected status: got %d, want %d", rec.Code, http.StatusUnauthorized)
+		}
+	})
+}
+
+func TestOptionalAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should scope the user to each request", func(t *testing.T) {
+		t.Parallel()
+
+		data := newUsers()
+		env := authEnv(t, data)
+
+		// Every request parks in the handler until all have been
+		// authenticated, so a shared identity would be overwritten.
+		const requests = 8
+
+		var arrived sync.WaitGroup
+
+		arrived.Add(requests)
+
+		handler := middleware.Chain(env,
+			middleware.OptionalAuthenticate,
+			middleware.New(http.HandlerFunc(
+				func(writer http.ResponseWriter, req *http.Request) {
+					arrived.Done()
+					arrived.Wait()
+
+					_, _ = writer.Write([]byte(auth.UserID(req.Context()).String()))
+				},
+			)),
+		)
+
+		var done sync.WaitGroup
+
+		for i := range requests {
+			want := uuid.Nil
+			if i%2 == 0 {
+				want = uuid.New()
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/chirps", nil)
+
+			if want != uuid.Nil {
+				data.add(want)
+				req.Header.Set("Authorization", bearer(t, want))
+			}
+
+			done.Add(1)
+
+			go func() {
+				defer done.Done()
+
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+
+				if got := rec.Body.String(); got != want.String() {
+					t.Errorf("unexpected user: got %s, want %s", got, want)
+				}
+			}()
+		}
+
+		done.Wait()
+	})
+}
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSPolicy says how web pages served from other origins may call a route.
+// The zero policy lets none of them.
+type CORSPolicy struct {
+	// AllowedOrigins are the origins, such as https://chirpy.example.com,
+	// whose pages may call the route, or "*" for any.
+	AllowedOrigins []string
+	AllowedMethods []string
+	// AllowedHeaders are the request headers pages may set beyond those
+	// browsers always allow, such as Authorization.
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers pages may read beyond those
+	// browsers always expose, such as RateLimit-Remaining.
+	ExposedHeaders []string
+	// AllowCredentials lets pages send cookies along. It is ignored for "*",
+	// which browsers refuse to combine with credentials.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache the answer to a preflight.
+	MaxAge time.Duration
+}
+
+// CORS applies Cross-Origin Resource Sharing policies to the requests under
+// next. Policies are keyed by path patterns such as "/api/", and the most
+// specific one matching a request applies, as in http.ServeMux. Requests
+// matching no pattern are passed on untouched.
+//
+// Preflight requests are answered here with a 204, since the routes of a
+// ServeMux only accept their own method. A preflight the policy refuses
+// gets no Access-Control-Allow-* headers, and the browser stops there.
+func CORS(policies map[string]CORSPolicy) func(next http.Handler) http.Handler {
+	// A mux of our own finds the most specific pattern for a path.
+	patterns := http.NewServeMux()
+	for pattern := range policies {
+		patterns.Handle(pattern, http.NotFoundHandler())
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(writer http.ResponseWriter, req *http.Request) {
+				_, pattern := patterns.Handler(req)
+
+				policy, ok := policies[pattern]
+				if !ok {
+					next.ServeHTTP(writer, req)
+
+					return
+				}
+
+				if isPreflight(req) {
+					policy.preflight(writer, req)
+
+					return
+				}
+
+				header := writer.Header()
+				header.Add("Vary", "Origin")
+
+				if origin := req.Header.Get("Origin"); policy.allowsOrigin(origin) {
+					policy.allow(header, origin)
+
+					if len(policy.ExposedHeaders) > 0 {
+						header.Set(
+							"Access-Control-Expose-Headers",
+							strings.Join(policy.ExposedHeaders, ", "),
+						)
+					}
+				}
+
+				next.ServeHTTP(writer, req)
+			},
+		)
+	}
+}
+
+func isPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions &&
+		req.Header.Get("Origin") != "" &&
+		req.Header.Get("Access-Control-Request-Method") != ""
+}
+
+func (p CORSPolicy) preflight(writer http.ResponseWriter, req *http.Request) {
+	header := writer.Header()
+	header.Add("Vary", "Origin")
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+
+	origin := req.Header.Get("Origin")
+	method := req.Header.Get("Access-Control-Request-Method")
+
+	if p.allowsOrigin(origin) &&
+		slices.Contains(p.AllowedMethods, method) &&
+		p.allowsHeaders(req.Header.Values("Access-Control-Request-Headers")) {
+		p.allow(header, origin)
+
+		header.Set(
+			"Access-Control-Allow-Methods",
+			strings.Join(p.AllowedMethods, ", "),
+		)
+
+		if len(p.AllowedHeaders) > 0 {
+			header.Set(
+				"Access-Control-Allow-Headers",
+				strings.Join(p.AllowedHeaders, ", "),
+			)
+		}
+
+		if p.MaxAge > 0 {
+			header.Set(
+				"Access-Control-Max-Age",
+				strconv.Itoa(int(p.MaxAge.Seconds())),
+			)
+		}
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (p CORSPolicy) allowsOrigin(origin string) bool {
+	if origin == "" {
+		return false
+	}
+
+	return slices.Contains(p.AllowedOrigins, "*") ||
+		slices.Contains(p.AllowedOrigins, origin)
+}
+
+// allowsHeaders reports whether every header named in the
+// Access-Control-Request-Headers values is allowed. Header names are
+// case-insensitive.
+func (p CORSPolicy) allowsHeaders(values []string) bool {
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+
+			if !slices.ContainsFunc(p.AllowedHeaders, func(allowed string) bool {
+				return strings.EqualFold(allowed, name)
+			}) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (p CORSPolicy) allow(header http.Header, origin string) {
+	if slices.Contains(p.AllowedOrigins, "*") {
+		header.Set("Access-Control-Allow-Origin", "*")
+
+		return
+	}
+
+	header.Set("Access-Control-Allow-Origin", origin)
+
+	if p.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
@@ -0,0 +1,170 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zyrterviews/chirpy/internal/middleware"
+)
+
+func newCORS() http.Handler {
+	api := middleware.CORSPolicy{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"Retry-After"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+
+	return middleware.CORS(map[string]middleware.CORSPolicy{
+		"/api/":               api,
+		"/api/openapi.json":   {AllowedOrigins: []string{"*"}, AllowedMethods: []string{http.MethodGet}},
+		"/api/polka/webhooks": {},
+	})(http.HandlerFunc(
+		func(writer http.ResponseWriter, _ *http.Request) {
+			writer.WriteHeader(http.StatusTeapot)
+		},
+	))
+}
+
+func preflight(
+	handler http.Handler,
+	path, origin, method, headers string,
+) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodOptions, path, nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", method)
+
+	if headers != "" {
+		req.Header.Set("Access-Control-Request-Headers", headers)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestCORS(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should answer an allowed preflight", func(t *testing.T) {
+		t.Parallel()
+
+		rec := preflight(
+			newCORS(),
+			"/api/chirps",
+			"https://app.example.com",
+			http.MethodPost,
+			"content-type,authorization",
+		)
+
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("unexpected status: %d", rec.Code)
+		}
+
+		for header, want := range map[string]string{
+			"Access-Control-Allow-Origin":      "https://app.example.com",
+			"Access-Control-Allow-Methods":     "GET, POST",
+			"Access-Control-Allow-Headers":     "Authorization, Content-Type",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Max-Age":           "600",
+		} {
+			if got := rec.Header().Get(header); got != want {
+				t.Fatalf("unexpected %s: got %q, want %q", header, got, want)
+			}
+		}
+
+		if !slices.Contains(rec.Header().Values("Vary"), "Origin") {
+			t.Fatalf("expected Vary: Origin, got %v", rec.Header().Values("Vary"))
+		}
+	})
+
+	t.Run("should refuse a preflight outside the policy", func(t *testing.T) {
+		t.Parallel()
+
+		for name, rec := range map[string]*httptest.ResponseRecorder{
+			"origin": preflight(
+				newCORS(), "/api/chirps", "https://evil.example.com", http.MethodPost, "",
+			),
+			"method": preflight(
+				newCORS(), "/api/chirps", "https://app.example.com", http.MethodDelete, "",
+			),
+			"header": preflight(
+				newCORS(), "/api/chirps", "https://app.example.com", http.MethodPost, "X-Debug",
+			),
+			"route": preflight(
+				newCORS(), "/api/polka/webhooks", "https://app.example.com", http.MethodPost, "",
+			),
+		} {
+			if rec.Code != http.StatusNoContent {
+				t.Fatalf("unexpected status for %s: %d", name, rec.Code)
+			}
+
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+				t.Fatalf("unexpected Access-Control-Allow-Origin for %s: %q", name, got)
+			}
+		}
+	})
+
+	t.Run("should apply the most specific policy", func(t *testing.T) {
+		t.Parallel()
+
+		rec := preflight(
+			newCORS(), "/api/openapi.json", "https://anyone.example.com", http.MethodGet, "",
+		)
+
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Fatalf("unexpected Access-Control-Allow-Origin: %q", got)
+		}
+
+		if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+			t.Fatalf("unexpected Access-Control-Allow-Credentials: %q", got)
+		}
+	})
+
+	t.Run("should mark allowed responses", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/chirps", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+
+		rec := httptest.NewRecorder()
+		newCORS().ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusTeapot {
+			t.Fatalf("unexpected status: %d", rec.Code)
+		}
+
+		for header, want := range map[string]string{
+			"Access-Control-Allow-Origin":   "https://app.example.com",
+			"Access-Control-Expose-Headers": "Retry-After",
+			"Vary":                          "Origin",
+		} {
+			if got := rec.Header().Get(header); got != want {
+				t.Fatalf("unexpected %s: got %q, want %q", header, got, want)
+			}
+		}
+	})
+
+	t.Run("should leave other requests alone", func(t *testing.T) {
+		t.Parallel()
+
+		// Without a policy, a preflight falls through to the routes.
+		rec := preflight(
+			newCORS(), "/admin/metrics", "https://app.example.com", http.MethodGet, "",
+		)
+
+		if rec.Code != http.StatusTeapot {
+			t.Fatalf("unexpected status: %d", rec.Code)
+		}
+
+		if len(rec.Header()) != 0 {
+			t.Fatalf("unexpected headers: %v", rec.Header())
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/logging"
+)
+
+// Log gives every request an ID, taken from X-Request-ID when the client
+// sent a usable one, and echoes it in the response header and in error
+// bodies. Once the request is served, one line is logged with its route,
+// status, size, latency and user, and its path with any token masked.
+func Log(env *appenv.Env) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(writer http.ResponseWriter, req *http.Request) {
+				start := time.Now()
+
+				info := &logging.Request{
+					ID: logging.RequestID(
+						req.Header.Get(logging.RequestIDHeader),
+					),
+				}
+				info.Logger = env.Logger.With("request_id", info.ID)
+
+				req = req.WithContext(
+					logging.WithRequest(req.Context(), info),
+				)
+
+				writer.Header().Set(logging.RequestIDHeader, info.ID)
+
+				recorder := &responseRecorder{
+					ResponseWriter: writer,
+					status:         http.StatusOK,
+				}
+
+				next.ServeHTTP(recorder, req)
+				recorder.finish(info.ID)
+
+				level := slog.LevelInfo
+				if recorder.status >= http.StatusInternalServerError {
+					level = slog.LevelError
+				}
+
+				attrs := []slog.Attr{
+					slog.String("method", req.Method),
+					slog.String("route", req.Pattern),
+					slog.String("path", redactedPath(req)),
+					slog.Int("status", recorder.status),
+					slog.Int("bytes", recorder.bytes),
+					slog.Float64(
+						"duration_ms",
+						float64(time.Since(start).Microseconds())/1000, //nolint:mnd
+					),
+				}
+
+				if info.UserID != uuid.Nil {
+					attrs = append(attrs, slog.String("user_id", info.UserID.String()))
+				}
+
+				info.Logger.LogAttrs(req.Context(), level, "request", attrs...)
+			},
+		)
+	}
+}
+
+// secretPathValues names the route wildcards holding credentials, such as
+// data export download tokens, which are kept out of logs and traces.
+var secretPathValues = []string{"token"}
+
+// redactedPath returns the path of req with the values of the wildcards in
+// secretPathValues masked. It relies on req.Pattern, so it must only be
+// called once the mux has served req.
+func redactedPath(req *http.Request) string {
+	pattern := req.Pattern
+	if i := strings.Index(pattern, "/"); i >= 0 {
+		pattern = pattern[i:]
+	}
+
+	segments := strings.Split(req.URL.EscapedPath(), "/")
+	redacted := false
+
+	for i, wildcard := range strings.Split(pattern, "/") {
+		if i >= len(segments) {
+			break
+		}
+
+		name, ok := strings.CutPrefix(wildcard, "{")
+		if !ok {
+			continue
+		}
+
+		name = strings.TrimSuffix(name, "}")
+		name, rest := strings.CutSuffix(name, "...")
+
+		if !slices.Contains(secretPathValues, name) {
+			continue
+		}
+
+		segments[i] = "REDACTED"
+		redacted = true
+
+		if rest {
+			segments = segments[:i+1]
+
+			break
+		}
+	}
+
+	if !redacted {
+		return req.URL.Path
+	}
+
+	path := strings.Join(segments, "/")
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		return unescaped
+	}
+
+	return path
+}
+
+// responseRecorder tracks the status and size of a response. Error
+// responses are held back until the handler returns so that the request ID
+// can be added to their body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+	held        *bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+
+	r.wroteHeader = true
+	r.status = status
+
+	if status >= http.StatusBadRequest {
+		r.held = &bytes.Buffer{}
+
+		return
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if r.held != nil {
+		return r.held.Write(data)
+	}
+
+	n, err := r.ResponseWriter.Write(data)
+	r.bytes += n
+
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// flush long polls.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// finish sends a held error response, annotated with requestID.
+func (r *responseRecorder) finish(requestID string) {
+	if r.held == nil {
+		return
+	}
+
+	body := logging.AnnotateErrorBody(
+		r.held.Bytes(),
+		r.Header().Get("Content-Type"),
+		requestID,
+	)
+
+	r.Header().Del("Content-Length")
+	r.ResponseWriter.WriteHeader(r.status)
+
+	n, _ := r.ResponseWriter.Write(body)
+	r.bytes += n
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/zyrterviews/chirpy/internal/appenv"
+)
+
+// Instrument counts requests and records their latency per route pattern
+// and status. It must wrap the ServeMux itself, which is what fills in
+// req.Pattern.
+func Instrument(env *appenv.Env) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(writer http.ResponseWriter, req *http.Request) {
+				start := time.Now()
+				recorder := &statusRecorder{
+					ResponseWriter: writer,
+					status:         http.StatusOK,
+				}
+
+				next.ServeHTTP(recorder, req)
+
+				env.Metrics.ObserveRequest(
+					req.Pattern,
+					recorder.status,
+					time.Since(start),
+				)
+			},
+		)
+	}
+}
+
+// statusRecorder remembers the status written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = status
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
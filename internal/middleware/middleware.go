@@ -2,10 +2,14 @@ package middleware
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"net/http"
 
 	"github.com/zyrterviews/chirpy/internal/appenv"
 	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/logging"
+	"github.com/zyrterviews/chirpy/internal/problem"
 )
 
 type Middleware func(env *appenv.Env) func(next http.Handler) http.Handler
@@ -32,27 +36,88 @@ func New(handler http.Handler) Middleware {
 	}
 }
 
+// Observe logs, measures and traces every request served by next, which
+// must end in the ServeMux. Log comes first so that Trace can add the trace
+// ID to its logger, and all three read the route pattern the mux fills in.
+func Observe(env *appenv.Env) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return Log(env)(Instrument(env)(Trace(env)(next)))
+	}
+}
+
 func Authenticate(env *appenv.Env) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(
 			func(writer http.ResponseWriter, req *http.Request) {
 				token, err := auth.GetBearerToken(req.Header)
 				if err != nil {
-					http.Error(writer, err.Error(), http.StatusUnauthorized)
+					problem.Write(
+						writer,
+						http.StatusUnauthorized,
+						problem.CodeUnauthorized,
+						err.Error(),
+					)
 
 					return
 				}
 
-				userID, err := auth.ValidateJWT(token, env.JWTSecret)
+				userID, err := auth.ValidateJWT(token, env.Config.JWTSecret.Reveal())
 				if err != nil {
-					http.Error(writer, err.Error(), http.StatusUnauthorized)
+					problem.Write(
+						writer,
+						http.StatusUnauthorized,
+						problem.CodeUnauthorized,
+						err.Error(),
+					)
 
 					return
 				}
 
-				env.UserID = userID
+				// Access tokens outlive the account they were issued for
+				// when it is deleted, so the user must still exist.
+				_, err = env.DB.GetUserByID(req.Context(), userID)
+				if errors.Is(err, sql.ErrNoRows) {
+					problem.Write(
+						writer,
+						http.StatusUnauthorized,
+						problem.CodeUnauthorized,
+						"user no longer exists",
+					)
 
-				next.ServeHTTP(writer, req)
+					return
+				}
+
+				if err != nil {
+					problem.Internal(writer, req, err)
+
+					return
+				}
+
+				logging.SetUserID(req.Context(), userID)
+
+				next.ServeHTTP(
+					writer,
+					req.WithContext(auth.WithUserID(req.Context(), userID)),
+				)
+			},
+		)
+	}
+}
+
+// OptionalAuthenticate authenticates the request when it carries a bearer
+// token and lets anonymous requests through with a nil user ID, for public
+// routes whose response depends on who is asking.
+func OptionalAuthenticate(env *appenv.Env) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(writer http.ResponseWriter, req *http.Request) {
+				if req.Header.Get("Authorization") == "" {
+					next.ServeHTTP(writer, req)
+
+					return
+				}
+
+				Authenticate(env)(next).ServeHTTP(writer, req)
 			},
 		)
 	}
@@ -68,15 +133,20 @@ func WithPrivileges(
 					for _, privilege := range privileges {
 						ok, err := privilege(req.Context(), env)
 						if err != nil {
-							http.Error(writer, err.Error(), err.Status)
+							switch err.Status {
+							case http.StatusUnauthorized:
+								problem.Unauthorized(writer)
+							case http.StatusForbidden:
+								problem.Forbidden(writer)
+							default:
+								problem.Internal(writer, req, err)
+							}
+
+							return
 						}
 
 						if !ok {
-							http.Error(
-								writer,
-								"FORBIDDEN",
-								http.StatusForbidden,
-							)
+							problem.Forbidden(writer)
 
 							return
 						}
@@ -93,7 +163,7 @@ func MetricsInc(env *appenv.Env) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(
 			func(writer http.ResponseWriter, req *http.Request) {
-				env.FileserverHits.Add(1)
+				env.Metrics.FileserverHit()
 				next.ServeHTTP(writer, req)
 			},
 		)
@@ -0,0 +1,146 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/config"
+	"github.com/zyrterviews/chirpy/internal/metrics"
+	"github.com/zyrterviews/chirpy/internal/middleware"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// observe serves target through the same stack as `chirpy serve`, with a
+// single route registered under pattern.
+func observe(env *appenv.Env, pattern, target string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.Handle(pattern,
+		middleware.Chain(env,
+			middleware.OptionalAuthenticate,
+			middleware.New(http.HandlerFunc(
+				func(writer http.ResponseWriter, _ *http.Request) {
+					writer.WriteHeader(http.StatusOK)
+				},
+			)),
+		),
+	)
+
+	handler := middleware.Observe(env)(
+		middleware.SecurityHeaders(env)(
+			middleware.CORS(nil)(middleware.Unmatched(mux)),
+		),
+	)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+	return rec
+}
+
+func TestObserve(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should label requests with their route", func(t *testing.T) {
+		t.Parallel()
+
+		var logs bytes.Buffer
+
+		env := &appenv.Env{
+			Config:  config.Default(),
+			Logger:  slog.New(slog.NewJSONHandler(&logs, nil)),
+			Metrics: metrics.New(nil),
+			Tracing: noop.NewTracerProvider(),
+		}
+
+		rec := observe(env, "GET /api/chirps/{chirpID}", "/api/chirps/42")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unexpected status: %d", rec.Code)
+		}
+
+		var line struct {
+			Route string `json:"route"`
+		}
+
+		if err := json.Unmarshal(logs.Bytes(), &line); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if line.Route != "GET /api/chirps/{chirpID}" {
+			t.Fatalf("unexpected logged route: %q", line.Route)
+		}
+
+		scrape := httptest.NewRecorder()
+		env.Metrics.Handler().ServeHTTP(
+			scrape,
+			httptest.NewRequest(http.MethodGet, "/metrics", nil),
+		)
+
+		want := `chirpy_http_requests_total{route="GET /api/chirps/{chirpID}",status="200"} 1`
+		if !strings.Contains(scrape.Body.String(), want) {
+			t.Fatalf("expected %q in:\n%s", want, scrape.Body.String())
+		}
+	})
+
+	t.Run("should keep tokens out of logs and traces", func(t *testing.T) {
+		t.Parallel()
+
+		var logs bytes.Buffer
+
+		exporter := tracetest.NewInMemoryExporter()
+
+		env := &appenv.Env{
+			Config:  config.Default(),
+			Logger:  slog.New(slog.NewJSONHandler(&logs, nil)),
+			Metrics: metrics.New(nil),
+			Tracing: sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)),
+		}
+
+		const token = "s3cr3t-download-token"
+
+		rec := observe(env, "GET /api/exports/{token}", "/api/exports/"+token)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unexpected status: %d", rec.Code)
+		}
+
+		if strings.Contains(logs.String(), token) {
+			t.Fatalf("token logged:\n%s", logs.String())
+		}
+
+		var line struct {
+			Path string `json:"path"`
+		}
+
+		if err := json.Unmarshal(logs.Bytes(), &line); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if line.Path != "/api/exports/REDACTED" {
+			t.Fatalf("unexpected logged path: %q", line.Path)
+		}
+
+		spans := exporter.GetSpans()
+		if len(spans) != 1 {
+			t.Fatalf("unexpected span count: %d", len(spans))
+		}
+
+		for _, kv := range spans[0].Attributes {
+			if strings.Contains(kv.Value.Emit(), token) {
+				t.Fatalf("token traced in %s", kv.Key)
+			}
+
+			if kv.Key == semconv.URLPathKey &&
+				kv.Value.AsString() != "/api/exports/REDACTED" {
+				t.Fatalf("unexpected traced path: %q", kv.Value.AsString())
+			}
+		}
+	})
+}
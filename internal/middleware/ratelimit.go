@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/config"
+	"github.com/zyrterviews/chirpy/internal/logging"
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/ratelimit"
+)
+
+// RateLimit spends a token of policy on every request. Requests that went
+// through Authenticate draw from their user's bucket, with the policy's
+// RedLimit for Chirpy Red members; others draw from their client IP's.
+// Every response carries the RateLimit-* headers, and refused requests get
+// a 429 rate_limited problem with Retry-After.
+//
+// If the store fails, requests are let through: an outage of the shared
+// store must not take the API down with it.
+func RateLimit(store ratelimit.Store, policy ratelimit.Policy) Middleware {
+	return func(env *appenv.Env) func(next http.Handler) http.Handler {
+		// The configuration has already been validated.
+		proxies, _ := config.ParseProxies(env.Config.HTTP.TrustedProxies)
+
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(
+				func(writer http.ResponseWriter, req *http.Request) {
+					key, limit := rateLimitKey(req, env, policy, proxies)
+
+					result, err := store.Take(req.Context(), key, limit)
+					if err != nil {
+						logging.FromContext(req.Context()).ErrorContext(
+							req.Context(),
+							"rate limit unavailable",
+							"error", err,
+						)
+
+						next.ServeHTTP(writer, req)
+
+						return
+					}
+
+					header := writer.Header()
+					header.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+					header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+					header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+					header.Set("RateLimit-Policy", limit.String())
+
+					if !result.Allowed {
+						env.Metrics.RateLimited(policy.Name)
+
+						retryAfter := ceilSeconds(result.RetryAfter)
+						header.Set("Retry-After", strconv.Itoa(retryAfter))
+
+						problem.Write(
+							writer,
+							http.StatusTooManyRequests,
+							problem.CodeRateLimited,
+							fmt.Sprintf(
+								"too many requests, retry in %d seconds",
+								retryAfter,
+							),
+						)
+
+						return
+					}
+
+					next.ServeHTTP(writer, req)
+				},
+			)
+		}
+	}
+}
+
+// rateLimitKey picks the bucket a request draws from and its limit.
+func rateLimitKey(
+	req *http.Request,
+	env *appenv.Env,
+	policy ratelimit.Policy,
+	proxies []netip.Prefix,
+) (string, ratelimit.Limit) {
+	userID := auth.UserID(req.Context())
+	if userID == uuid.Nil {
+		return policy.Name + ":ip:" + clientIP(req, proxies), policy.Limit
+	}
+
+	key := policy.Name + ":user:" + userID.String()
+
+	if policy.RedLimit.Requests > 0 &&
+		isChirpyRed(req.Context(), env, userID) {
+		return key, policy.RedLimit
+	}
+
+	return key, policy.Limit
+}
+
+func isChirpyRed(ctx context.Context, env *appenv.Env, userID uuid.UUID) bool {
+	user, err := env.DB.GetUserByID(ctx, userID)
+
+	return err == nil && user.IsChirpyRed
+}
+
+// clientIP returns the address of the client that sent req. When the peer
+// is a trusted proxy, X-Forwarded-For is walked from the right, skipping
+// trusted proxies, since only the entries they appended can be believed.
+// IPv6 clients are grouped by /64, the block usually handed to one host.
+func clientIP(req *http.Request, proxies []netip.Prefix) string {
+	peer, err := netip.ParseAddrPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+
+	ip := peer.Addr().Unmap()
+
+	if isTrusted(ip, proxies) {
+		hops := strings.Split(
+			strings.Join(req.Header.Values("X-Forwarded-For"), ","),
+			",",
+		)
+
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+
+			ip = hop.Unmap()
+
+			if !isTrusted(ip, proxies) {
+				break
+			}
+		}
+	}
+
+	if ip.Is6() {
+		block, _ := ip.Prefix(64) //nolint:mnd
+
+		return block.String()
+	}
+
+	return ip.String()
+}
+
+func isTrusted(ip netip.Addr, proxies []netip.Prefix) bool {
+	for _, proxy := range proxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ceilSeconds rounds d up to whole seconds, as rate limit headers require.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,217 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/config"
+	"github.com/zyrterviews/chirpy/internal/metrics"
+	"github.com/zyrterviews/chirpy/internal/middleware"
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/ratelimit"
+)
+
+var twoPerMinute = ratelimit.Policy{
+	Name:  "test",
+	Limit: ratelimit.Limit{Requests: 2, Window: time.Minute},
+}
+
+// failingStore is a store whose backend is down.
+type failingStore struct{}
+
+func (failingStore) Take(
+	context.Context,
+	string,
+	ratelimit.Limit,
+) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func newRateLimited(store ratelimit.Store, trustedProxies string) http.Handler {
+	env := &appenv.Env{
+		Config: &config.Config{
+			HTTP: config.HTTP{TrustedProxies: trustedProxies},
+		},
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Metrics: metrics.New(nil),
+	}
+
+	return middleware.Chain(
+		env,
+		middleware.RateLimit(store, twoPerMinute),
+		middleware.New(http.HandlerFunc(
+			func(writer http.ResponseWriter, _ *http.Request) {
+				writer.WriteHeader(http.StatusNoContent)
+			},
+		)),
+	)
+}
+
+func send(handler http.Handler, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/login", nil)
+	req.RemoteAddr = remoteAddr
+
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should refuse requests over the limit", func(t *testing.T) {
+		t.Parallel()
+
+		handler := newRateLimited(ratelimit.NewMemoryStore(), "")
+
+		for _, remaining := range []string{"1", "0"} {
+			rec := send(handler, "192.0.2.1:1234", "")
+			if rec.Code != http.StatusNoContent {
+				t.Fatalf("unexpected status: %d", rec.Code)
+			}
+
+			for header, want := range map[string]string{
+				"RateLimit-Limit":     "2",
+				"RateLimit-Remaining": remaining,
+				"RateLimit-Policy":    "2;w=60",
+			} {
+				if got := rec.Header().Get(header); got != want {
+					t.Fatalf("unexpected %s: got %q, want %q", header, got, want)
+				}
+			}
+		}
+
+		rec := send(handler, "192.0.2.1:1234", "")
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("unexpected status: %d", rec.Code)
+		}
+
+		if got := rec.Header().Get("Retry-After"); got != "30" {
+			t.Fatalf("unexpected Retry-After: %q", got)
+		}
+
+		if got := rec.Header().Get("RateLimit-Reset"); got != "60" {
+			t.Fatalf("unexpected RateLimit-Reset: %q", got)
+		}
+
+		var body problem.Problem
+
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if body.Code != problem.CodeRateLimited {
+			t.Fatalf("unexpected code: %q", body.Code)
+		}
+	})
+
+	t.Run("should key anonymous requests by client IP", func(t *testing.T) {
+		t.Parallel()
+
+		handler := newRateLimited(ratelimit.NewMemoryStore(), "")
+
+		for range 2 {
+			send(handler, "192.0.2.1:1234", "")
+		}
+
+		if rec := send(handler, "192.0.2.2:1234", ""); rec.Code != http.StatusNoContent {
+			t.Fatalf("unexpected status for another IP: %d", rec.Code)
+		}
+
+		// An untrusted peer cannot pick its own address.
+		if rec := send(handler, "192.0.2.1:1234", "198.51.100.9"); rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("unexpected status with a forged header: %d", rec.Code)
+		}
+	})
+
+	t.Run("should group IPv6 clients by /64", func(t *testing.T) {
+		t.Parallel()
+
+		handler := newRateLimited(ratelimit.NewMemoryStore(), "")
+
+		send(handler, "[2001:db8::1]:1234", "")
+		send(handler, "[2001:db8::2]:1234", "")
+
+		if rec := send(handler, "[2001:db8::3]:1234", ""); rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("unexpected status: %d", rec.Code)
+		}
+	})
+
+	t.Run("should believe trusted proxies", func(t *testing.T) {
+		t.Parallel()
+
+		handler := newRateLimited(ratelimit.NewMemoryStore(), "10.0.0.0/8")
+
+		for range 2 {
+			send(handler, "10.0.0.1:1234", "198.51.100.9, 10.0.0.2")
+		}
+
+		if rec := send(handler, "10.0.0.1:1234", "198.51.100.9"); rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("unexpected status for the same client: %d", rec.Code)
+		}
+
+		// The client may have prepended any address of its choosing.
+		if rec := send(handler, "10.0.0.1:1234", "203.0.113.1, 198.51.100.10"); rec.Code != http.StatusNoContent {
+			t.Fatalf("unexpected status for another client: %d", rec.Code)
+		}
+	})
+
+	t.Run("should key authenticated requests by user", func(t *testing.T) {
+		t.Parallel()
+
+		handler := newRateLimited(ratelimit.NewMemoryStore(), "")
+
+		sendAs := func(userID uuid.UUID) int {
+			req := httptest.NewRequest(http.MethodPost, "/api/chirps", nil)
+			req = req.WithContext(auth.WithUserID(req.Context(), userID))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			return rec.Code
+		}
+
+		jane, john := uuid.New(), uuid.New()
+
+		sendAs(jane)
+		sendAs(jane)
+
+		if code := sendAs(jane); code != http.StatusTooManyRequests {
+			t.Fatalf("unexpected status: %d", code)
+		}
+
+		if code := sendAs(john); code != http.StatusNoContent {
+			t.Fatalf("unexpected status for another user: %d", code)
+		}
+	})
+
+	t.Run("should let requests through when the store fails", func(t *testing.T) {
+		t.Parallel()
+
+		handler := newRateLimited(failingStore{}, "")
+
+		rec := send(handler, "192.0.2.1:1234", "")
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("unexpected status: %d", rec.Code)
+		}
+
+		if rec.Header().Get("RateLimit-Limit") != "" {
+			t.Fatal("did not expect rate limit headers")
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/config"
+	"github.com/zyrterviews/chirpy/internal/middleware"
+)
+
+func secured(hstsMaxAge time.Duration, handler http.Handler) *httptest.ResponseRecorder {
+	env := &appenv.Env{
+		Config: &config.Config{HTTP: config.HTTP{HSTSMaxAge: hstsMaxAge}},
+	}
+
+	rec := httptest.NewRecorder()
+	middleware.SecurityHeaders(env)(handler).ServeHTTP(
+		rec,
+		httptest.NewRequest(http.MethodGet, "/api/chirps", nil),
+	)
+
+	return rec
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	t.Parallel()
+
+	noContent := http.HandlerFunc(
+		func(writer http.ResponseWriter, _ *http.Request) {
+			writer.WriteHeader(http.StatusNoContent)
+		},
+	)
+
+	t.Run("should lock responses down", func(t *testing.T) {
+		t.Parallel()
+
+		rec := secured(365*24*time.Hour, noContent)
+
+		for header, want := range map[string]string{
+			"Content-Security-Policy":   middleware.DefaultCSP,
+			"Strict-Transport-Security": "max-age=31536000",
+			"X-Content-Type-Options":    "nosniff",
+			"X-Frame-Options":           "DENY",
+			"Referrer-Policy":           "strict-origin-when-cross-origin",
+		} {
+			if got := rec.Header().Get(header); got != want {
+				t.Fatalf("unexpected %s: got %q, want %q", header, got, want)
+			}
+		}
+	})
+
+	t.Run("should leave HSTS out when disabled", func(t *testing.T) {
+		t.Parallel()
+
+		rec := secured(0, noContent)
+
+		if got := rec.Header().Get("Strict-Transport-Security"); got != "" {
+			t.Fatalf("unexpected Strict-Transport-Security: %q", got)
+		}
+	})
+
+	t.Run("should let routes relax the CSP", func(t *testing.T) {
+		t.Parallel()
+
+		rec := secured(0, middleware.CSP("default-src 'self'")(noContent))
+
+		if got := rec.Header().Get("Content-Security-Policy"); got != "default-src 'self'" {
+			t.Fatalf("unexpected Content-Security-Policy: %q", got)
+		}
+	})
+}
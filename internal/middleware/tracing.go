@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/logging"
+	"github.com/zyrterviews/chirpy/internal/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace runs every request in a span named after its route pattern,
+// continuing the trace of an incoming traceparent header. Like Instrument,
+// it must wrap the ServeMux itself, which is what fills in req.Pattern.
+func Trace(env *appenv.Env) func(next http.Handler) http.Handler {
+	tracer := tracing.Tracer(env.Tracing)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(writer http.ResponseWriter, req *http.Request) {
+				ctx := tracing.Propagator.Extract(
+					req.Context(),
+					propagation.HeaderCarrier(req.Header),
+				)
+
+				ctx, span := tracer.Start(ctx, req.Method,
+					trace.WithSpanKind(trace.SpanKindServer),
+					trace.WithAttributes(
+						semconv.HTTPRequestMethodKey.String(req.Method),
+					),
+				)
+				defer span.End()
+
+				// The request log line then carries the trace ID too.
+				if info := logging.RequestFromContext(ctx); info != nil &&
+					info.Logger != nil && span.SpanContext().IsValid() {
+					info.Logger = info.Logger.With(
+						"trace_id",
+						span.SpanContext().TraceID().String(),
+					)
+				}
+
+				recorder := &statusRecorder{
+					ResponseWriter: writer,
+					status:         http.StatusOK,
+				}
+
+				traced := req.WithContext(ctx)
+				next.ServeHTTP(recorder, traced)
+
+				// The mux fills in the pattern of the request it is given,
+				// which is this copy. Log and Instrument, around this
+				// middleware, read it from theirs.
+				req.Pattern = traced.Pattern
+
+				if req.Pattern != "" {
+					span.SetName(req.Pattern)
+					span.SetAttributes(semconv.HTTPRoute(req.Pattern))
+				}
+
+				// Set last, as the route tells which segments to redact.
+				span.SetAttributes(semconv.URLPath(redactedPath(req)))
+
+				span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+
+				if recorder.status >= http.StatusInternalServerError {
+					span.SetStatus(codes.Error, http.StatusText(recorder.status))
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/zyrterviews/chirpy/internal/problem"
+)
+
+// Unmatched answers requests that match no route of mux with a problem
+// instead of the plain-text 404 and 405 responses of http.ServeMux. The
+// Allow header of a 405 is kept.
+func Unmatched(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		handler, pattern := mux.Handler(req)
+		if pattern != "" {
+			mux.ServeHTTP(writer, req)
+
+			return
+		}
+
+		// Without a pattern, handler is the mux's own error or redirect
+		// handler. Run it against a scratch response to learn its status.
+		scratch := &scratchResponse{header: http.Header{}, status: http.StatusOK}
+		handler.ServeHTTP(scratch, req)
+
+		switch scratch.status {
+		case http.StatusNotFound:
+			problem.NotFound(writer)
+		case http.StatusMethodNotAllowed:
+			writer.Header().Set("Allow", scratch.header.Get("Allow"))
+			problem.Write(
+				writer,
+				http.StatusMethodNotAllowed,
+				problem.CodeMethodNotAllowed,
+				"",
+			)
+		default:
+			mux.ServeHTTP(writer, req)
+		}
+	})
+}
+
+// scratchResponse records the header and status of a response and drops
+// its body.
+type scratchResponse struct {
+	header http.Header
+	status int
+}
+
+func (r *scratchResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *scratchResponse) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *scratchResponse) Write(data []byte) (int, error) {
+	return len(data), nil
+}
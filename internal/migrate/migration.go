@@ -0,0 +1,158 @@
+// Package migrate applies the goose migrations embedded in the binary. It
+// understands the goose SQL annotations and keeps track of applied versions
+// in goose's own goose_db_version table, so databases migrated with the
+// goose CLI can be taken over as they are.
+package migrate
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const annotationPrefix = "-- +goose"
+
+var (
+	errNoVersion         = errors.New("migration file name must start with a version number")
+	errNoUpSection       = errors.New("migration has no -- +goose Up section")
+	errStatementOutside  = errors.New("statement outside of an Up or Down section")
+	errUnknownAnnotation = errors.New("unknown goose annotation")
+	errDuplicateVersion  = errors.New("duplicate migration version")
+)
+
+type Migration struct {
+	Version int64
+	// Name is the file name, e.g. 00001_add_users_table.sql.
+	Name string
+	Up   string
+	Down string
+	// NoTx is set by -- +goose NO TRANSACTION, for statements such as
+	// CREATE INDEX CONCURRENTLY that cannot run inside a transaction.
+	NoTx bool
+}
+
+// Parse reads a goose SQL migration. StatementBegin/StatementEnd annotations
+// are accepted but not needed: each section is sent to Postgres as a whole.
+func Parse(name string, src []byte) (Migration, error) {
+	base := path.Base(name)
+
+	prefix, _, ok := strings.Cut(base, "_")
+	if !ok {
+		return Migration{}, fmt.Errorf("%s: %w", name, errNoVersion)
+	}
+
+	version, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil || version < 1 {
+		return Migration{}, fmt.Errorf("%s: %w", name, errNoVersion)
+	}
+
+	migration := Migration{Version: version, Name: base}
+
+	var (
+		section   *strings.Builder
+		up, down  strings.Builder
+		sawUp     bool
+		lineIndex int
+	)
+
+	for _, line := range strings.Split(string(src), "\n") {
+		lineIndex++
+
+		trimmed := strings.TrimSpace(line)
+
+		if annotation, ok := strings.CutPrefix(trimmed, annotationPrefix); ok {
+			switch strings.TrimSpace(annotation) {
+			case "Up":
+				section = &up
+				sawUp = true
+			case "Down":
+				section = &down
+			case "NO TRANSACTION":
+				migration.NoTx = true
+			case "StatementBegin", "StatementEnd":
+			default:
+				return Migration{}, fmt.Errorf(
+					"%s:%d: %w: %s",
+					name,
+					lineIndex,
+					errUnknownAnnotation,
+					trimmed,
+				)
+			}
+
+			continue
+		}
+
+		if section == nil {
+			if trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+				return Migration{}, fmt.Errorf(
+					"%s:%d: %w",
+					name,
+					lineIndex,
+					errStatementOutside,
+				)
+			}
+
+			continue
+		}
+
+		section.WriteString(line)
+		section.WriteString("\n")
+	}
+
+	if !sawUp {
+		return Migration{}, fmt.Errorf("%s: %w", name, errNoUpSection)
+	}
+
+	migration.Up = strings.TrimSpace(up.String())
+	migration.Down = strings.TrimSpace(down.String())
+
+	return migration, nil
+}
+
+// Load parses every .sql file at the root of fsys, ordered by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("list migrations: %w", err)
+	}
+
+	migrations := make([]Migration, 0, len(names))
+
+	for _, name := range names {
+		src, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+
+		migration, err := Parse(name, src)
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, migration)
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf(
+				"%w %d: %s and %s",
+				errDuplicateVersion,
+				migrations[i].Version,
+				migrations[i-1].Name,
+				migrations[i].Name,
+			)
+		}
+	}
+
+	return migrations, nil
+}
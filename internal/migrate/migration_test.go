@@ -0,0 +1,148 @@
+package migrate_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/zyrterviews/chirpy/internal/migrate"
+	"github.com/zyrterviews/chirpy/sql/schema"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should split the Up and Down sections", func(t *testing.T) {
+		t.Parallel()
+
+		src := `-- +goose Up
+CREATE TABLE users (id UUID);
+
+-- +goose Down
+DROP TABLE users;
+`
+
+		migration, err := migrate.Parse("00001_add_users_table.sql", []byte(src))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if migration.Version != 1 {
+			t.Fatalf("unexpected version: got %d, want 1", migration.Version)
+		}
+
+		if migration.Up != "CREATE TABLE users (id UUID);" {
+			t.Fatalf("unexpected up section: %q", migration.Up)
+		}
+
+		if migration.Down != "DROP TABLE users;" {
+			t.Fatalf("unexpected down section: %q", migration.Down)
+		}
+
+		if migration.NoTx {
+			t.Fatal("did not expect NO TRANSACTION")
+		}
+	})
+
+	t.Run("should honour statement blocks and NO TRANSACTION", func(t *testing.T) {
+		t.Parallel()
+
+		src := `-- +goose NO TRANSACTION
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX CONCURRENTLY idx ON users (id);
+-- +goose StatementEnd
+`
+
+		migration, err := migrate.Parse("00002_index.sql", []byte(src))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !migration.NoTx {
+			t.Fatal("expected NO TRANSACTION")
+		}
+
+		if migration.Up != "CREATE INDEX CONCURRENTLY idx ON users (id);" {
+			t.Fatalf("unexpected up section: %q", migration.Up)
+		}
+
+		if migration.Down != "" {
+			t.Fatalf("unexpected down section: %q", migration.Down)
+		}
+	})
+
+	t.Run("should reject files without a version", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := migrate.Parse("add_users.sql", []byte("-- +goose Up\n"))
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+
+	t.Run("should reject statements outside a section", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := migrate.Parse("00001_x.sql", []byte("DROP TABLE users;\n"))
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should order migrations by version", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := fstest.MapFS{
+			"00010_b.sql": {Data: []byte("-- +goose Up\nSELECT 2;\n")},
+			"00002_a.sql": {Data: []byte("-- +goose Up\nSELECT 1;\n")},
+			"README.md":   {Data: []byte("not a migration")},
+		}
+
+		migrations, err := migrate.Load(fsys)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(migrations) != 2 ||
+			migrations[0].Version != 2 ||
+			migrations[1].Version != 10 {
+			t.Fatalf("unexpected migrations: %+v", migrations)
+		}
+	})
+
+	t.Run("should reject duplicate versions", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := fstest.MapFS{
+			"00001_a.sql": {Data: []byte("-- +goose Up\nSELECT 1;\n")},
+			"1_b.sql":     {Data: []byte("-- +goose Up\nSELECT 2;\n")},
+		}
+
+		if _, err := migrate.Load(fsys); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+
+	t.Run("should load the embedded schema", func(t *testing.T) {
+		t.Parallel()
+
+		migrations, err := migrate.Load(schema.FS)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for i, migration := range migrations {
+			if migration.Version != int64(i+1) {
+				t.Fatalf("unexpected gap before %s", migration.Name)
+			}
+
+			if migration.Up == "" || migration.Down == "" {
+				t.Fatalf("%s must have Up and Down sections", migration.Name)
+			}
+		}
+	})
+}
@@ -0,0 +1,384 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// lockName is hashed into the advisory lock that serialises migration runs
+// across replicas and CLI invocations.
+const lockName = "chirpy:migrate"
+
+var (
+	errNothingToRollBack = errors.New("no migration to roll back")
+	errUnknownVersion    = errors.New("unknown migration version")
+)
+
+// Runner applies migrations to DB. Every operation holds a Postgres advisory
+// lock for its whole duration, so concurrent runs wait for each other
+// instead of racing.
+type Runner struct {
+	DB         *sql.DB
+	Migrations []Migration
+	// Log receives one line per applied or rolled back migration.
+	Log io.Writer
+}
+
+// Status is the state of a single migration.
+type Status struct {
+	Migration Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Up applies every pending migration.
+func (r *Runner) Up(ctx context.Context) error {
+	return r.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range r.Migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			if err := r.apply(ctx, conn, migration, true); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Down rolls back the most recently applied migration.
+func (r *Runner) Down(ctx context.Context) error {
+	return r.withLock(ctx, func(conn *sql.Conn) error {
+		latest, err := r.latestApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		return r.apply(ctx, conn, latest, false)
+	})
+}
+
+// Redo rolls back the most recently applied migration and applies it again.
+func (r *Runner) Redo(ctx context.Context) error {
+	return r.withLock(ctx, func(conn *sql.Conn) error {
+		latest, err := r.latestApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if err := r.apply(ctx, conn, latest, false); err != nil {
+			return err
+		}
+
+		return r.apply(ctx, conn, latest, true)
+	})
+}
+
+// To migrates up or down until version is the latest applied migration.
+// Version 0 rolls back everything.
+func (r *Runner) To(ctx context.Context, version int64) error {
+	if version != 0 && r.find(version) == nil {
+		return fmt.Errorf("%w %d", errUnknownVersion, version)
+	}
+
+	return r.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range r.Migrations {
+			_, ok := applied[migration.Version]
+			if ok || migration.Version > version {
+				continue
+			}
+
+			if err := r.apply(ctx, conn, migration, true); err != nil {
+				return err
+			}
+		}
+
+		for i := len(r.Migrations) - 1; i >= 0; i-- {
+			migration := r.Migrations[i]
+
+			_, ok := applied[migration.Version]
+			if !ok || migration.Version <= version {
+				continue
+			}
+
+			if err := r.apply(ctx, conn, migration, false); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Status reports every known migration and when it was applied.
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+
+	err := r.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		statuses = make([]Status, 0, len(r.Migrations))
+
+		for _, migration := range r.Migrations {
+			appliedAt, ok := applied[migration.Version]
+
+			statuses = append(statuses, Status{
+				Migration: migration,
+				Applied:   ok,
+				AppliedAt: appliedAt,
+			})
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// Pending lists the known migrations that are not applied yet. Unlike the
+// other operations it neither takes the migration lock nor creates the
+// version table, so it can be polled by readiness probes while a run is in
+// progress.
+func (r *Runner) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := appliedVersions(ctx, r.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+
+	for _, migration := range r.Migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
+}
+
+func (r *Runner) withLock(
+	ctx context.Context,
+	fn func(conn *sql.Conn) error,
+) error {
+	// Session advisory locks belong to a connection, so everything runs on
+	// the one that took it.
+	conn, err := r.DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(
+		ctx,
+		"SELECT pg_advisory_lock(hashtext($1))",
+		lockName,
+	)
+	if err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+
+	//nolint:errcheck
+	defer conn.ExecContext(
+		context.WithoutCancel(ctx),
+		"SELECT pg_advisory_unlock(hashtext($1))",
+		lockName,
+	)
+
+	if err := ensureVersionTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func (r *Runner) latestApplied(
+	ctx context.Context,
+	conn *sql.Conn,
+) (Migration, error) {
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return Migration{}, err
+	}
+
+	for i := len(r.Migrations) - 1; i >= 0; i-- {
+		if _, ok := applied[r.Migrations[i].Version]; ok {
+			return r.Migrations[i], nil
+		}
+	}
+
+	return Migration{}, errNothingToRollBack
+}
+
+func (r *Runner) find(version int64) *Migration {
+	for i := range r.Migrations {
+		if r.Migrations[i].Version == version {
+			return &r.Migrations[i]
+		}
+	}
+
+	return nil
+}
+
+// apply runs the Up or Down section of a migration and records it the way
+// goose does: a new row when applying, deleting the version's rows when
+// rolling back.
+func (r *Runner) apply(
+	ctx context.Context,
+	conn *sql.Conn,
+	migration Migration,
+	up bool,
+) error {
+	statements, record, direction := migration.Down, deleteVersionSQL, "down"
+	if up {
+		statements, record, direction = migration.Up, insertVersionSQL, "up"
+	}
+
+	run := func(exec execer) error {
+		if statements != "" {
+			if _, err := exec.ExecContext(ctx, statements); err != nil {
+				return fmt.Errorf("%s %s: %w", direction, migration.Name, err)
+			}
+		}
+
+		if _, err := exec.ExecContext(ctx, record, migration.Version); err != nil {
+			return fmt.Errorf("record %s: %w", migration.Name, err)
+		}
+
+		return nil
+	}
+
+	if migration.NoTx {
+		if err := run(conn); err != nil {
+			return err
+		}
+	} else {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("begin transaction: %w", err)
+		}
+
+		//nolint:errcheck
+		defer tx.Rollback()
+
+		if err := run(tx); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit %s: %w", migration.Name, err)
+		}
+	}
+
+	if r.Log != nil {
+		fmt.Fprintf(r.Log, "migrate %s: %s\n", direction, migration.Name)
+	}
+
+	return nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+const (
+	createVersionTableSQL = `CREATE TABLE IF NOT EXISTS goose_db_version (
+    id SERIAL PRIMARY KEY,
+    version_id BIGINT NOT NULL,
+    is_applied BOOLEAN NOT NULL,
+    tstamp TIMESTAMP DEFAULT NOW()
+)`
+	insertVersionSQL = `INSERT INTO goose_db_version (version_id, is_applied) VALUES ($1, TRUE)`
+	deleteVersionSQL = `DELETE FROM goose_db_version WHERE version_id = $1`
+	// goose keeps the history of each version; its latest row says whether
+	// it is currently applied.
+	appliedVersionsSQL = `SELECT DISTINCT ON (version_id) version_id, is_applied, tstamp
+FROM goose_db_version
+ORDER BY version_id, id DESC`
+)
+
+// ensureVersionTable creates goose_db_version with goose's initial version 0
+// row when the database has never been migrated.
+func ensureVersionTable(ctx context.Context, conn *sql.Conn) error {
+	if _, err := conn.ExecContext(ctx, createVersionTableSQL); err != nil {
+		return fmt.Errorf("create version table: %w", err)
+	}
+
+	var count int
+
+	err := conn.QueryRowContext(
+		ctx,
+		"SELECT COUNT(*) FROM goose_db_version",
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("read version table: %w", err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	if _, err := conn.ExecContext(ctx, insertVersionSQL, 0); err != nil {
+		return fmt.Errorf("initialise version table: %w", err)
+	}
+
+	return nil
+}
+
+// appliedVersions returns when each currently applied version was applied.
+func appliedVersions(
+	ctx context.Context,
+	db querier,
+) (map[int64]time.Time, error) {
+	rows, err := db.QueryContext(ctx, appliedVersionsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+
+	for rows.Next() {
+		var (
+			version   int64
+			isApplied bool
+			tstamp    sql.NullTime
+		)
+
+		if err := rows.Scan(&version, &isApplied, &tstamp); err != nil {
+			return nil, fmt.Errorf("read applied migrations: %w", err)
+		}
+
+		if isApplied && version > 0 {
+			applied[version] = tstamp.Time
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read applied migrations: %w", err)
+	}
+
+	return applied, nil
+}
@@ -0,0 +1,253 @@
+// Renders /api/openapi.json as a list of operations, each with a form to
+// try it against this server. Everything is built with DOM calls and
+// textContent, so nothing from the document is ever parsed as HTML.
+"use strict";
+
+const methods = ["get", "post", "put", "patch", "delete"];
+
+function el(tag, props, ...children) {
+  const node = document.createElement(tag);
+
+  Object.assign(node, props);
+  node.append(...children.filter((child) => child !== undefined));
+
+  return node;
+}
+
+// resolve follows a local $ref such as "#/components/schemas/Chirp".
+function resolve(spec, node) {
+  if (!node || typeof node.$ref !== "string") {
+    return node;
+  }
+
+  return node.$ref
+    .replace(/^#\//, "")
+    .split("/")
+    .reduce((obj, key) => (obj ? obj[key] : undefined), spec);
+}
+
+function operationsByTag(spec) {
+  const groups = new Map((spec.tags || []).map((tag) => [tag.name, []]));
+
+  for (const [path, item] of Object.entries(spec.paths || {})) {
+    for (const method of methods) {
+      const op = item[method];
+      if (!op) {
+        continue;
+      }
+
+      const tag = (op.tags && op.tags[0]) || "default";
+      if (!groups.has(tag)) {
+        groups.set(tag, []);
+      }
+
+      const params = [...(item.parameters || []), ...(op.parameters || [])]
+        .map((param) => resolve(spec, param));
+
+      groups.get(tag).push({ path, method, op, params });
+    }
+  }
+
+  return groups;
+}
+
+function paramsTable(params) {
+  if (params.length === 0) {
+    return undefined;
+  }
+
+  return el(
+    "table",
+    {},
+    el(
+      "tr",
+      {},
+      el("th", { textContent: "Name" }),
+      el("th", { textContent: "In" }),
+      el("th", { textContent: "Description" }),
+    ),
+    ...params.map((param) =>
+      el(
+        "tr",
+        {},
+        el("td", {}, el("code", {
+          textContent: param.name + (param.required ? " *" : ""),
+        })),
+        el("td", { textContent: param.in }),
+        el("td", { textContent: param.description || "" }),
+      ),
+    ),
+  );
+}
+
+function responsesList(spec, responses) {
+  return el(
+    "ul",
+    {},
+    ...Object.entries(responses || {}).map(([status, response]) =>
+      el(
+        "li",
+        {},
+        el("code", { textContent: status }),
+        " " + (resolve(spec, response).description || ""),
+      ),
+    ),
+  );
+}
+
+function requestSchema(spec, op) {
+  const body = resolve(spec, op.requestBody);
+  if (!body || !body.content) {
+    return undefined;
+  }
+
+  const [type, media] = Object.entries(body.content)[0];
+
+  return el(
+    "div",
+    {},
+    el("h4", { textContent: "Request body (" + type + ")" }),
+    el("pre", {
+      textContent: JSON.stringify(resolve(spec, media.schema), null, 2),
+    }),
+  );
+}
+
+async function send(form, entry, output) {
+  let path = entry.path;
+  const query = new URLSearchParams();
+
+  for (const param of entry.params) {
+    const value = form.elements[param.in + ":" + param.name].value;
+    if (value === "") {
+      continue;
+    }
+
+    if (param.in === "path") {
+      path = path.replace("{" + param.name + "}", encodeURIComponent(value));
+    } else if (param.in === "query") {
+      query.set(param.name, value);
+    }
+  }
+
+  const init = { method: entry.method.toUpperCase(), headers: {} };
+
+  const token = document.getElementById("token").value;
+  if (token !== "") {
+    init.headers.Authorization = "Bearer " + token;
+  }
+
+  if (form.elements.body && form.elements.body.value !== "") {
+    init.headers["Content-Type"] = "application/json";
+    init.body = form.elements.body.value;
+  }
+
+  const url = path + (query.size > 0 ? "?" + query : "");
+  output.textContent = init.method + " " + url + "…";
+
+  try {
+    const res = await fetch(url, init);
+    let text = await res.text();
+
+    try {
+      text = JSON.stringify(JSON.parse(text), null, 2);
+    } catch {
+      // Not JSON, shown as is.
+    }
+
+    output.textContent = res.status + " " + res.statusText + "\n\n" + text;
+  } catch (err) {
+    output.textContent = String(err);
+  }
+}
+
+function tryItForm(entry) {
+  const output = el("pre");
+  const form = el(
+    "form",
+    {},
+    ...entry.params
+      .filter((param) => param.in === "path" || param.in === "query")
+      .map((param) =>
+        el(
+          "label",
+          {},
+          param.name + " ",
+          el("input", {
+            name: param.in + ":" + param.name,
+            required: param.in === "path",
+          }),
+        ),
+      ),
+    entry.op.requestBody
+      ? el("label", {}, "Body", el("textarea", { name: "body" }))
+      : undefined,
+    el("button", { type: "submit", textContent: "Send" }),
+  );
+
+  form.addEventListener("submit", (event) => {
+    event.preventDefault();
+    send(form, entry, output);
+  });
+
+  return el("div", {}, el("h4", { textContent: "Try it" }), form, output);
+}
+
+function operation(spec, entry) {
+  const { op } = entry;
+
+  return el(
+    "details",
+    {},
+    el(
+      "summary",
+      { className: op.deprecated ? "deprecated" : "" },
+      el("span", {
+        className: "method method-" + entry.method,
+        textContent: entry.method,
+      }),
+      el("code", { textContent: entry.path }),
+      " " + (op.summary || ""),
+    ),
+    el(
+      "div",
+      {},
+      op.description ? el("pre", { textContent: op.description }) : undefined,
+      paramsTable(entry.params),
+      requestSchema(spec, op),
+      el("h4", { textContent: "Responses" }),
+      responsesList(spec, op.responses),
+      tryItForm(entry),
+    ),
+  );
+}
+
+function render(spec) {
+  const main = document.getElementById("operations");
+
+  document.getElementById("title").textContent =
+    spec.info.title + " " + spec.info.version;
+
+  main.replaceChildren(
+    el("pre", { textContent: spec.info.description || "" }),
+  );
+
+  for (const [tag, entries] of operationsByTag(spec)) {
+    if (entries.length === 0) {
+      continue;
+    }
+
+    main.append(
+      el("h2", { textContent: tag }),
+      ...entries.map((entry) => operation(spec, entry)),
+    );
+  }
+}
+
+fetch("/api/openapi.json")
+  .then((res) => res.json())
+  .then(render)
+  .catch((err) => {
+    document.getElementById("operations").textContent =
+      "Could not load the document: " + err;
+  });
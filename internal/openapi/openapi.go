@@ -0,0 +1,108 @@
+// Package openapi serves the OpenAPI 3.1 description of the chirpy API and
+// a page to browse it.
+//
+// The document is written by hand in openapi.yaml, next to this file, and
+// served as JSON. Every route registered by `chirpy serve` must appear in
+// it; a test in internal/cli enforces that.
+package openapi
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"sync"
+
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	//go:embed openapi.yaml
+	specYAML []byte
+
+	// docsFS holds the docs page and the script and stylesheet it loads.
+	//go:embed docs
+	docsFS embed.FS
+)
+
+// docsCSP only lets the docs page load what chirpy itself serves: the page
+// has no inline script or style, and its script only talks to this origin.
+const docsCSP = "default-src 'none'; " +
+	"script-src 'self'; " +
+	"style-src 'self'; " +
+	"img-src 'self' data:; " +
+	"connect-src 'self'; " +
+	"frame-ancestors 'none'"
+
+// Spec returns the OpenAPI document as JSON.
+var Spec = sync.OnceValues(func() ([]byte, error) {
+	var doc any
+
+	if err := yaml.Unmarshal(specYAML, &doc); err != nil {
+		return nil, fmt.Errorf("parse openapi.yaml: %w", err)
+	}
+
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("convert openapi.yaml to JSON: %w", err)
+	}
+
+	return spec, nil
+})
+
+// GET /api/openapi.json
+func GetSpec() http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			spec, err := Spec()
+			if err != nil {
+				problem.Internal(writer, req, err)
+
+				return
+			}
+
+			writer.Header().Set("Content-Type", "application/json")
+
+			_, _ = writer.Write(spec)
+		},
+	)
+}
+
+// GET /api/docs
+//
+// Renders the document as a browsable page, built from files embedded in
+// the binary rather than loaded from a CDN.
+func GetDocs() http.Handler {
+	return serveDocsFile("index.html")
+}
+
+// GET /api/docs/{asset}
+func GetDocsAsset() http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			serveDocsFile(req.PathValue("asset")).ServeHTTP(writer, req)
+		},
+	)
+}
+
+func serveDocsFile(name string) http.Handler {
+	return http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			file, err := fs.ReadFile(docsFS, path.Join("docs", name))
+			if err != nil {
+				problem.NotFound(writer)
+
+				return
+			}
+
+			writer.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(name)))
+			writer.Header().Set("Content-Security-Policy", docsCSP)
+
+			_, _ = writer.Write(file)
+		},
+	)
+}
@@ -0,0 +1,194 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zyrterviews/chirpy/internal/openapi"
+)
+
+// refs collects every $ref in a decoded JSON document.
+func refs(node any, found map[string]bool) {
+	switch node := node.(type) {
+	case map[string]any:
+		for key, value := range node {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				found[ref] = true
+			}
+
+			refs(value, found)
+		}
+	case []any:
+		for _, value := range node {
+			refs(value, found)
+		}
+	}
+}
+
+// resolve follows a local JSON pointer, such as
+// "#/components/schemas/Chirp", and reports whether it exists.
+func resolve(doc any, ref string) bool {
+	path, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return false
+	}
+
+	node := doc
+
+	for _, key := range strings.Split(path, "/") {
+		obj, ok := node.(map[string]any)
+		if !ok {
+			return false
+		}
+
+		if node, ok = obj[key]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestSpec(t *testing.T) {
+	t.Parallel()
+
+	spec, err := openapi.Spec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc map[string]any
+
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("should be an OpenAPI 3.1 document", func(t *testing.T) {
+		t.Parallel()
+
+		if doc["openapi"] != "3.1.0" {
+			t.Fatalf("expected openapi 3.1.0, got %v", doc["openapi"])
+		}
+	})
+
+	t.Run("should resolve every $ref", func(t *testing.T) {
+		t.Parallel()
+
+		found := map[string]bool{}
+		refs(doc, found)
+
+		for ref := range found {
+			if !resolve(doc, ref) {
+				t.Errorf("%s does not resolve", ref)
+			}
+		}
+	})
+}
+
+func TestGetSpec(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should serve the document as JSON", func(t *testing.T) {
+		t.Parallel()
+
+		rec := httptest.NewRecorder()
+		openapi.GetSpec().ServeHTTP(
+			rec,
+			httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil),
+		)
+
+		if rec.Code != http.StatusOK ||
+			rec.Header().Get("Content-Type") != "application/json" {
+			t.Fatalf("unexpected response: %d %v", rec.Code, rec.Header())
+		}
+
+		if !json.Valid(rec.Body.Bytes()) {
+			t.Fatal("expected a JSON body")
+		}
+	})
+}
+
+func TestGetDocs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should only allow the page's own scripts", func(t *testing.T) {
+		t.Parallel()
+
+		rec := httptest.NewRecorder()
+		openapi.GetDocs().ServeHTTP(
+			rec,
+			httptest.NewRequest(http.MethodGet, "/api/docs", nil),
+		)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unexpected status: %d", rec.Code)
+		}
+
+		csp := rec.Header().Get("Content-Security-Policy")
+		if !strings.Contains(csp, "script-src 'self';") {
+			t.Fatalf("expected script-src 'self' in %q", csp)
+		}
+
+		if strings.Contains(rec.Body.String(), "<script>") {
+			t.Fatal("expected no inline script")
+		}
+	})
+}
+
+func TestGetDocsAsset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		asset       string
+		status      int
+		contentType string
+	}{
+		{
+			name:        "should serve the script",
+			asset:       "docs.js",
+			status:      http.StatusOK,
+			contentType: "text/javascript; charset=utf-8",
+		},
+		{
+			name:        "should serve the stylesheet",
+			asset:       "docs.css",
+			status:      http.StatusOK,
+			contentType: "text/css; charset=utf-8",
+		},
+		{
+			name:   "should not serve unknown files",
+			asset:  "swagger-ui.js",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "should not serve files outside the page",
+			asset:  "..",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/docs/x", nil)
+			req.SetPathValue("asset", test.asset)
+
+			rec := httptest.NewRecorder()
+			openapi.GetDocsAsset().ServeHTTP(rec, req)
+
+			if rec.Code != test.status {
+				t.Fatalf("unexpected status: got %d, want %d", rec.Code, test.status)
+			}
+
+			if test.contentType != "" &&
+				rec.Header().Get("Content-Type") != test.contentType {
+				t.Fatalf("unexpected content type: %q", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Package pagination reads the limit/offset query parameters shared by the
+// paginated endpoints.
+package pagination
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+const (
+	DefaultLimit int32 = 20
+	MaxLimit     int32 = 100
+)
+
+var ErrInvalid = errors.New(
+	"limit and offset must be non-negative integers",
+)
+
+// Parse reads the `limit` and `offset` query parameters, falling back to
+// DefaultLimit and clamping the limit to MaxLimit.
+func Parse(req *http.Request) (int32, int32, error) {
+	limit := DefaultLimit
+	offset := int32(0)
+
+	if raw := req.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || n < 1 {
+			return 0, 0, ErrInvalid
+		}
+
+		limit = min(int32(n), MaxLimit)
+	}
+
+	if raw := req.URL.Query().Get("offset"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || n < 0 {
+			return 0, 0, ErrInvalid
+		}
+
+		offset = int32(n)
+	}
+
+	return limit, offset, nil
+}
@@ -0,0 +1,189 @@
+// Package problem writes every API error in one shape: an RFC 9457 problem
+// details object, served as application/problem+json, extended with a
+// stable machine-readable code. Clients should branch on the code; the
+// detail is meant for humans and may change.
+//
+//	{
+//	  "type": "about:blank",
+//	  "title": "Not Found",
+//	  "status": 404,
+//	  "detail": "chirp not found",
+//	  "code": "not_found",
+//	  "request_id": "0b6f3c9e-…"
+//	}
+//
+// request_id is added by middleware.Log.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/zyrterviews/chirpy/internal/logging"
+)
+
+const ContentType = "application/problem+json"
+
+// Code identifies a kind of error. Codes are part of the API: never change
+// or reuse one.
+type Code string
+
+const (
+	// CodeBadRequest covers request parameters or bodies chirpy cannot
+	// accept.
+	CodeBadRequest Code = "bad_request"
+	// CodeInvalidJSON means the request body is not the JSON expected.
+	CodeInvalidJSON Code = "invalid_json"
+	// CodeUnauthorized means the request lacks a valid access token or key.
+	CodeUnauthorized Code = "unauthorized"
+	// CodeInvalidCredentials means an email and password did not match.
+	CodeInvalidCredentials Code = "invalid_credentials"
+	// CodeForbidden means the user may not do this.
+	CodeForbidden Code = "forbidden"
+	// CodeNotFound means the resource does not exist or is hidden from the
+	// user.
+	CodeNotFound Code = "not_found"
+	// CodeMethodNotAllowed means the route exists for other methods.
+	CodeMethodNotAllowed Code = "method_not_allowed"
+	// CodeConflict means the request clashes with the current state of the
+	// resource.
+	CodeConflict Code = "conflict"
+	// CodeAlreadyTaken means a unique value, such as an email, is in use.
+	CodeAlreadyTaken Code = "already_taken"
+	// CodePollClosed means the poll no longer accepts votes.
+	CodePollClosed Code = "poll_closed"
+	// CodeExportInProgress means a data export is already being prepared.
+	CodeExportInProgress Code = "export_in_progress"
+	// CodeExportNotReady means a data export is still being prepared.
+	CodeExportNotReady Code = "export_not_ready"
+	// CodePayloadTooLarge means the request body is over the size limit.
+	CodePayloadTooLarge Code = "payload_too_large"
+	// CodeUnsupportedMediaType means an upload's type is not accepted.
+	CodeUnsupportedMediaType Code = "unsupported_media_type"
+	// CodeValidationFailed means the body is well-formed JSON but some fields
+	// hold values chirpy does not accept. Errors lists them.
+	CodeValidationFailed Code = "validation_failed"
+	// CodeMessagesRefused means a member of the conversation does not accept
+	// direct messages from the sender.
+	CodeMessagesRefused Code = "messages_refused"
+	// CodeRateLimited means the caller made too many requests. Retry-After
+	// says when to try again.
+	CodeRateLimited Code = "rate_limited"
+	// CodeInternal means chirpy failed. The cause is logged, never sent.
+	CodeInternal Code = "internal_error"
+)
+
+type Problem struct {
+	// Type is always about:blank: Code identifies the problem instead.
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Code   Code   `json:"code"`
+	// Errors lists the offending fields of a validation_failed problem.
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError explains why the value of one request field was rejected.
+// Field is the JSON name of the field, dotted for nested fields.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func New(status int, code Code, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+
+	return p.Title + ": " + p.Detail
+}
+
+// Write sends p as the response.
+func (p *Problem) Write(writer http.ResponseWriter) {
+	res, err := json.Marshal(p)
+	if err != nil {
+		// A Problem only holds strings and ints.
+		panic(err)
+	}
+
+	writer.Header().Set("Content-Type", ContentType)
+	writer.Header().Set("X-Content-Type-Options", "nosniff")
+	writer.Header().Del("Content-Length")
+	writer.WriteHeader(p.Status)
+
+	_, _ = writer.Write(append(res, '\n'))
+}
+
+// Write sends a problem with the given status, code and detail.
+func Write(writer http.ResponseWriter, status int, code Code, detail string) {
+	New(status, code, detail).Write(writer)
+}
+
+// Internal logs err with the request and sends a generic 500, so that
+// database and other internal errors never reach clients.
+func Internal(writer http.ResponseWriter, req *http.Request, err error) {
+	logging.FromContext(req.Context()).ErrorContext(
+		req.Context(),
+		"internal error",
+		"error", err,
+	)
+
+	Write(
+		writer,
+		http.StatusInternalServerError,
+		CodeInternal,
+		"something went wrong",
+	)
+}
+
+// Invalid sends a 422 validation_failed problem listing errs.
+func Invalid(writer http.ResponseWriter, errs []FieldError) {
+	p := New(
+		http.StatusUnprocessableEntity,
+		CodeValidationFailed,
+		"some fields are invalid",
+	)
+	p.Errors = errs
+	p.Write(writer)
+}
+
+// InvalidField sends a 422 validation_failed problem for a single field.
+func InvalidField(writer http.ResponseWriter, field, message string) {
+	Invalid(writer, []FieldError{{Field: field, Message: message}})
+}
+
+func BadRequest(writer http.ResponseWriter, detail string) {
+	Write(writer, http.StatusBadRequest, CodeBadRequest, detail)
+}
+
+func InvalidJSON(writer http.ResponseWriter) {
+	Write(
+		writer,
+		http.StatusBadRequest,
+		CodeInvalidJSON,
+		"request body is not valid JSON",
+	)
+}
+
+func Unauthorized(writer http.ResponseWriter) {
+	Write(writer, http.StatusUnauthorized, CodeUnauthorized, "")
+}
+
+func Forbidden(writer http.ResponseWriter) {
+	Write(writer, http.StatusForbidden, CodeForbidden, "")
+}
+
+func NotFound(writer http.ResponseWriter) {
+	Write(writer, http.StatusNotFound, CodeNotFound, "")
+}
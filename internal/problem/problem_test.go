@@ -0,0 +1,129 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zyrterviews/chirpy/internal/middleware"
+	"github.com/zyrterviews/chirpy/internal/problem"
+)
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder) problem.Problem {
+	t.Helper()
+
+	if got := rec.Header().Get("Content-Type"); got != problem.ContentType {
+		t.Fatalf("expected %s, got %q", problem.ContentType, got)
+	}
+
+	var body problem.Problem
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return body
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should send the status, title, detail and code", func(t *testing.T) {
+		t.Parallel()
+
+		rec := httptest.NewRecorder()
+		problem.Write(rec, http.StatusConflict, problem.CodePollClosed, "poll is closed")
+
+		body := decode(t, rec)
+
+		want := problem.Problem{
+			Type:   "about:blank",
+			Title:  "Conflict",
+			Status: http.StatusConflict,
+			Detail: "poll is closed",
+			Code:   problem.CodePollClosed,
+		}
+
+		if rec.Code != http.StatusConflict || !reflect.DeepEqual(body, want) {
+			t.Fatalf("unexpected problem: %d %+v", rec.Code, body)
+		}
+	})
+
+	t.Run("should hide internal errors", func(t *testing.T) {
+		t.Parallel()
+
+		rec := httptest.NewRecorder()
+		problem.Internal(
+			rec,
+			httptest.NewRequest(http.MethodGet, "/api/chirps", nil),
+			errors.New("pq: password authentication failed"),
+		)
+
+		body := decode(t, rec)
+
+		if rec.Code != http.StatusInternalServerError ||
+			body.Code != problem.CodeInternal {
+			t.Fatalf("unexpected problem: %d %+v", rec.Code, body)
+		}
+
+		if strings.Contains(rec.Body.String(), "pq:") {
+			t.Fatalf("internal error leaked: %s", rec.Body.String())
+		}
+	})
+}
+
+func TestUnmatched(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/chirps", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusNoContent)
+	})
+
+	handler := middleware.Unmatched(mux)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+
+		return rec
+	}
+
+	t.Run("should pass matched requests through", func(t *testing.T) {
+		t.Parallel()
+
+		if rec := serve(http.MethodGet, "/api/chirps"); rec.Code != http.StatusNoContent {
+			t.Fatalf("expected 204, got %d", rec.Code)
+		}
+	})
+
+	t.Run("should answer unknown paths with not_found", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serve(http.MethodGet, "/api/nope")
+
+		if body := decode(t, rec); rec.Code != http.StatusNotFound ||
+			body.Code != problem.CodeNotFound {
+			t.Fatalf("unexpected problem: %d %+v", rec.Code, body)
+		}
+	})
+
+	t.Run("should answer other methods with method_not_allowed", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serve(http.MethodDelete, "/api/chirps")
+
+		if body := decode(t, rec); rec.Code != http.StatusMethodNotAllowed ||
+			body.Code != problem.CodeMethodNotAllowed {
+			t.Fatalf("unexpected problem: %d %+v", rec.Code, body)
+		}
+
+		if allow := rec.Header().Get("Allow"); !strings.Contains(allow, http.MethodGet) {
+			t.Fatalf("expected Allow to list GET, got %q", allow)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+// Package purge permanently removes chirps and accounts that have sat in the
+// trash for longer than the retention period.
+package purge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/logging"
+)
+
+const (
+	// Retention is how long soft-deleted chirps and accounts can still be
+	// restored before they are purged.
+	Retention = 30 * 24 * time.Hour
+	// Interval is how often expired rows are looked for.
+	Interval = time.Hour
+	// batchSize bounds how many accounts a single pass loads.
+	batchSize int32 = 100
+)
+
+type Purger struct {
+	Env *appenv.Env
+}
+
+// Purge hard-deletes chirps and accounts deleted more than Retention ago,
+// along with the uploaded files of purged accounts. Deleting is idempotent,
+// so replicas running it at the same time only duplicate some work.
+func (p *Purger) Purge(ctx context.Context) error {
+	cutoff := time.Now().Add(-Retention)
+
+	purged, err := p.Env.DB.PurgeDeletedChirps(ctx, cutoff)
+	if err != nil {
+		return fmt.Errorf("purge chirps: %w", err)
+	}
+
+	if purged > 0 {
+		logging.FromContext(ctx).Info("purge: removed chirps", "count", purged)
+	}
+
+	for {
+		users, err := p.Env.DB.GetPurgeableUsers(
+			ctx,
+			database.GetPurgeableUsersParams{
+				DeletedBefore: cutoff,
+				RowLimit:      batchSize,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("load purgeable users: %w", err)
+		}
+
+		for _, user := range users {
+			if err := p.purgeUser(ctx, user); err != nil {
+				return err
+			}
+		}
+
+		if int32(len(users)) < batchSize { //nolint:gosec
+			return nil
+		}
+	}
+}
+
+// purgeUser deletes an account, which cascades to everything it owns, then
+// removes its files from blob storage.
+func (p *Purger) purgeUser(ctx context.Context, user database.User) error {
+	media, err := p.Env.DB.GetMediaForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("load media of user %s: %w", user.ID, err)
+	}
+
+	if err := p.Env.DB.DeleteUserByID(ctx, user.ID); err != nil {
+		return fmt.Errorf("purge user %s: %w", user.ID, err)
+	}
+
+	for _, medium := range media {
+		for _, key := range []string{medium.BlobKey, medium.ThumbnailKey} {
+			if err := p.Env.Blobs.Delete(ctx, key); err != nil {
+				logging.FromContext(ctx).Warn(
+					"purge: could not delete blob",
+					"key", key,
+					"error", err,
+				)
+			}
+		}
+	}
+
+	logging.FromContext(ctx).Info("purge: removed user", "user_id", user.ID)
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often a MemoryStore forgets buckets that have filled
+// up again, which behave exactly like missing ones.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps buckets in the memory of one process. Every replica has
+// its own, so callers get as many requests per replica.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	Bucket
+	window time.Duration
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*memoryBucket{},
+	}
+}
+
+func (s *MemoryStore) Take(
+	_ context.Context,
+	key string,
+	limit Limit,
+) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{Bucket: NewBucket(limit, now)}
+		s.buckets[key] = bucket
+	}
+
+	bucket.window = limit.Window
+
+	return bucket.Take(limit, now), nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.UpdatedAt) >= bucket.window {
+			delete(s.buckets, key)
+		}
+	}
+
+	s.lastSweep = now
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/logging"
+)
+
+const (
+	// IdleAfter is how long a bucket goes unused before Sweep deletes it. It
+	// must exceed the longest policy window, after which a bucket is full
+	// again and can be recreated as it was.
+	IdleAfter = 24 * time.Hour
+	// SweepInterval is how often idle buckets are looked for.
+	SweepInterval = time.Hour
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table, so that every
+// replica draws from the same ones. Buckets are refilled with the clock of
+// the replica serving the request.
+type PostgresStore struct {
+	Env *appenv.Env
+}
+
+func (s *PostgresStore) Take(
+	ctx context.Context,
+	key string,
+	limit Limit,
+) (Result, error) {
+	var result Result
+
+	err := s.Env.InTx(ctx, func(qtx *database.Queries) error {
+		now := time.Now().UTC()
+		full := NewBucket(limit, now)
+
+		// Inserts a full bucket unless there is one already, and holds the
+		// row lock until commit so concurrent requests queue up.
+		row, err := qtx.LockRateLimitBucket(
+			ctx,
+			database.LockRateLimitBucketParams{
+				Key:       key,
+				Tokens:    full.Tokens,
+				UpdatedAt: full.UpdatedAt,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		bucket := Bucket{Tokens: row.Tokens, UpdatedAt: row.UpdatedAt}
+		result = bucket.Take(limit, now)
+
+		return qtx.UpdateRateLimitBucket(
+			ctx,
+			database.UpdateRateLimitBucketParams{
+				Key:       key,
+				Tokens:    bucket.Tokens,
+				UpdatedAt: bucket.UpdatedAt,
+			},
+		)
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("take rate limit token: %w", err)
+	}
+
+	return result, nil
+}
+
+// Sweep deletes buckets unused for IdleAfter. Deleting is idempotent, so
+// replicas running it at the same time only duplicate some work.
+func (s *PostgresStore) Sweep(ctx context.Context) error {
+	deleted, err := s.Env.DB.DeleteIdleRateLimitBuckets(
+		ctx,
+		time.Now().Add(-IdleAfter),
+	)
+	if err != nil {
+		return fmt.Errorf("delete idle rate limit buckets: %w", err)
+	}
+
+	if deleted > 0 {
+		logging.FromContext(ctx).Info(
+			"ratelimit: removed idle buckets",
+			"count", deleted,
+		)
+	}
+
+	return nil
+}
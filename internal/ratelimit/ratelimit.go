@@ -0,0 +1,96 @@
+// Package ratelimit throttles callers with token buckets. Each caller of a
+// policy owns a bucket that holds up to Limit.Requests tokens and refills
+// evenly over Limit.Window; every request spends one token and is refused
+// when none is left. Store is implemented by an in-memory backend for single
+// replicas and by a Postgres one shared by every replica.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+type Limit struct {
+	// Requests is the bucket size: how many requests can be made at once.
+	Requests int
+	// Window is how long an empty bucket takes to refill.
+	Window time.Duration
+}
+
+// Policy is the limit applied to one route or group of routes.
+type Policy struct {
+	// Name keys the buckets of the policy. Routes sharing a name share
+	// buckets.
+	Name  string
+	Limit Limit
+	// RedLimit replaces Limit for Chirpy Red members when set.
+	RedLimit Limit
+}
+
+// String describes the limit as in the RateLimit-Policy header, such as
+// "10;w=60".
+func (l Limit) String() string {
+	return fmt.Sprintf("%d;w=%d", l.Requests, int(l.Window.Seconds()))
+}
+
+// Result is the outcome of spending a token.
+type Result struct {
+	Allowed bool
+	Limit   Limit
+	// Remaining is how many requests can still be made right away.
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next request is allowed. It is 0
+	// when Allowed is set.
+	RetryAfter time.Duration
+}
+
+type Store interface {
+	// Take spends a token from the bucket stored under key, which starts
+	// out full, and reports whether the request is allowed.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Bucket is the state of one caller's bucket.
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// NewBucket returns a full bucket.
+func NewBucket(limit Limit, now time.Time) Bucket {
+	return Bucket{Tokens: float64(limit.Requests), UpdatedAt: now}
+}
+
+// Take refills b for the time elapsed since it was last updated, then
+// spends a token if there is one.
+func (b *Bucket) Take(limit Limit, now time.Time) Result {
+	size := float64(limit.Requests)
+	rate := size / limit.Window.Seconds()
+
+	elapsed := max(now.Sub(b.UpdatedAt).Seconds(), 0)
+
+	b.Tokens = min(size, b.Tokens+elapsed*rate)
+	b.UpdatedAt = now
+
+	result := Result{Limit: limit}
+
+	if b.Tokens >= 1 {
+		b.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.Tokens) / rate)
+	}
+
+	result.Remaining = int(math.Floor(b.Tokens))
+	result.Reset = seconds((size - b.Tokens) / rate)
+
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
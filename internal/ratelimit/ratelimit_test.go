@@ -0,0 +1,125 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zyrterviews/chirpy/internal/ratelimit"
+)
+
+var perMinute = ratelimit.Limit{Requests: 3, Window: time.Minute}
+
+func TestBucket(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should allow a burst of the bucket size", func(t *testing.T) {
+		t.Parallel()
+
+		bucket := ratelimit.NewBucket(perMinute, start)
+
+		for i := range perMinute.Requests {
+			result := bucket.Take(perMinute, start)
+			if !result.Allowed {
+				t.Fatalf("expected request %d to be allowed", i+1)
+			}
+
+			if result.Remaining != perMinute.Requests-1-i {
+				t.Fatalf("unexpected remaining: %d", result.Remaining)
+			}
+		}
+
+		result := bucket.Take(perMinute, start)
+		if result.Allowed {
+			t.Fatal("expected the request to be refused")
+		}
+
+		if result.RetryAfter != 20*time.Second {
+			t.Fatalf("unexpected retry after: %v", result.RetryAfter)
+		}
+
+		if result.Reset != time.Minute {
+			t.Fatalf("unexpected reset: %v", result.Reset)
+		}
+	})
+
+	t.Run("should refill over the window", func(t *testing.T) {
+		t.Parallel()
+
+		bucket := ratelimit.Bucket{Tokens: 0, UpdatedAt: start}
+
+		if bucket.Take(perMinute, start.Add(10*time.Second)).Allowed {
+			t.Fatal("expected the request to be refused")
+		}
+
+		if !bucket.Take(perMinute, start.Add(20*time.Second)).Allowed {
+			t.Fatal("expected the request to be allowed")
+		}
+
+		result := bucket.Take(perMinute, start.Add(time.Hour))
+		if !result.Allowed || result.Remaining != perMinute.Requests-1 {
+			t.Fatalf("expected a full bucket, got %+v", result)
+		}
+	})
+
+	t.Run("should not refill when the clock goes back", func(t *testing.T) {
+		t.Parallel()
+
+		bucket := ratelimit.Bucket{Tokens: 0, UpdatedAt: start}
+
+		if bucket.Take(perMinute, start.Add(-time.Hour)).Allowed {
+			t.Fatal("expected the request to be refused")
+		}
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should keep one bucket per key", func(t *testing.T) {
+		t.Parallel()
+
+		store := ratelimit.NewMemoryStore()
+		ctx := context.Background()
+
+		for range perMinute.Requests {
+			if _, err := store.Take(ctx, "login:ip:192.0.2.1", perMinute); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		result, err := store.Take(ctx, "login:ip:192.0.2.1", perMinute)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result.Allowed {
+			t.Fatal("expected the request to be refused")
+		}
+
+		result, err = store.Take(ctx, "login:ip:192.0.2.2", perMinute)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !result.Allowed {
+			t.Fatal("expected another key to have its own bucket")
+		}
+	})
+}
+
+func TestLimitString(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should format the RateLimit-Policy header", func(t *testing.T) {
+		t.Parallel()
+
+		limit := ratelimit.Limit{Requests: 10, Window: 5 * time.Minute}
+
+		if got := limit.String(); got != "10;w=300" {
+			t.Fatalf("unexpected policy: %q", got)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+// Package scheduler publishes scheduled chirps once their time has come.
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/events"
+)
+
+const (
+	// PollInterval is how often due chirps are looked for.
+	PollInterval = 10 * time.Second
+	// batchSize bounds how many chirps a single run publishes.
+	batchSize int32 = 100
+)
+
+type Publisher struct {
+	Env *appenv.Env
+}
+
+// PublishDue publishes every scheduled chirp whose publish_at has passed.
+// Rows are claimed with FOR UPDATE SKIP LOCKED inside a single UPDATE, so
+// each chirp is published exactly once even when several replicas run the
+// scheduler at the same time.
+func (p *Publisher) PublishDue(ctx context.Context) error {
+	for {
+		chirps, err := p.Env.DB.PublishDueChirps(ctx, batchSize)
+		if err != nil {
+			return fmt.Errorf("publish due chirps: %w", err)
+		}
+
+		if len(chirps) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(chirps))
+		authors := make(map[uuid.UUID]uuid.UUID, len(chirps))
+
+		for _, chirp := range chirps {
+			ids = append(ids, chirp.ID)
+			authors[chirp.ID] = chirp.UserID
+		}
+
+		mentions, err := p.Env.DB.GetMentionsForChirps(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("load mentions: %w", err)
+		}
+
+		for _, mention := range mentions {
+			events.Dispatch(ctx, p.Env, events.Event{
+				Kind:      events.KindMention,
+				Recipient: mention.UserID,
+				Actor:     authors[mention.ChirpID],
+				Subject:   mention.ChirpID,
+			})
+		}
+
+		if err := p.notifyReplies(ctx, chirps); err != nil {
+			return err
+		}
+
+		if int32(len(chirps)) < batchSize { //nolint:gosec
+			return nil
+		}
+	}
+}
+
+// notifyReplies tells the authors of the chirps that just-published replies
+// answer. A reply whose parent has been deleted in the meantime is skipped.
+func (p *Publisher) notifyReplies(
+	ctx context.Context,
+	chirps []database.Chirp,
+) error {
+	for _, chirp := range chirps {
+		if !chirp.ReplyToID.Valid {
+			continue
+		}
+
+		parent, err := p.Env.DB.GetChirpByID(ctx, chirp.ReplyToID.UUID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+
+			return fmt.Errorf("load replied chirp: %w", err)
+		}
+
+		events.Dispatch(ctx, p.Env, events.Event{
+			Kind:      events.KindReply,
+			Recipient: parent.UserID,
+			Actor:     chirp.UserID,
+			Subject:   parent.ID,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/zyrterviews/chirpy/internal/database"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryNameKey holds the sqlc name of a query, e.g. GetAllChirps.
+const QueryNameKey = attribute.Key("db.sqlc.query")
+
+// unnamedQuery names spans for SQL that sqlc did not generate.
+const unnamedQuery = "sql"
+
+// DB wraps db so that every query runs in a span named after its sqlc query.
+// Spans for QueryContext end once the rows are returned, before they are
+// read.
+func DB(db database.DBTX, provider trace.TracerProvider) database.DBTX {
+	return &tracedDB{db: db, tracer: Tracer(provider)}
+}
+
+type tracedDB struct {
+	db     database.DBTX
+	tracer trace.Tracer
+}
+
+func (t *tracedDB) ExecContext(
+	ctx context.Context,
+	query string,
+	args ...interface{},
+) (sql.Result, error) {
+	ctx, span := t.start(ctx, query)
+	defer span.End()
+
+	result, err := t.db.ExecContext(ctx, query, args...)
+	recordError(span, err)
+
+	return result, err
+}
+
+func (t *tracedDB) PrepareContext(
+	ctx context.Context,
+	query string,
+) (*sql.Stmt, error) {
+	ctx, span := t.start(ctx, query)
+	defer span.End()
+
+	stmt, err := t.db.PrepareContext(ctx, query)
+	recordError(span, err)
+
+	return stmt, err
+}
+
+func (t *tracedDB) QueryContext(
+	ctx context.Context,
+	query string,
+	args ...interface{},
+) (*sql.Rows, error) {
+	ctx, span := t.start(ctx, query)
+	defer span.End()
+
+	rows, err := t.db.QueryContext(ctx, query, args...)
+	recordError(span, err)
+
+	return rows, err
+}
+
+func (t *tracedDB) QueryRowContext(
+	ctx context.Context,
+	query string,
+	args ...interface{},
+) *sql.Row {
+	ctx, span := t.start(ctx, query)
+	defer span.End()
+
+	row := t.db.QueryRowContext(ctx, query, args...)
+	recordError(span, row.Err())
+
+	return row
+}
+
+func (t *tracedDB) start(
+	ctx context.Context,
+	query string,
+) (context.Context, trace.Span) {
+	name := QueryName(query)
+
+	return t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			QueryNameKey.String(name),
+			semconv.DBQueryText(query),
+		),
+	)
+}
+
+// recordError marks span as failed. sql.ErrNoRows is an expected outcome,
+// not a failure.
+func recordError(span trace.Span, err error) {
+	if err == nil || errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// QueryName returns the name sqlc gave query in its leading
+// "-- name: GetAllChirps :many" comment.
+func QueryName(query string) string {
+	rest, ok := strings.CutPrefix(query, "-- name: ")
+	if !ok {
+		return unnamedQuery
+	}
+
+	name, _, _ := strings.Cut(rest, " ")
+	if name == "" {
+		return unnamedQuery
+	}
+
+	return name
+}
@@ -0,0 +1,77 @@
+// Package tracing sets up OpenTelemetry and records spans for database
+// queries. Spans for HTTP requests are started by middleware.Trace.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/zyrterviews/chirpy/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// ServiceName identifies chirpy in exported spans.
+const ServiceName = "chirpy"
+
+// instrumentationName names the tracer chirpy's own spans come from.
+const instrumentationName = "github.com/zyrterviews/chirpy"
+
+// Propagator reads and writes W3C traceparent and tracestate headers.
+var Propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+// Tracer returns chirpy's tracer from provider.
+func Tracer(provider trace.TracerProvider) trace.Tracer {
+	return provider.Tracer(instrumentationName)
+}
+
+// Setup builds the tracer provider for cfg and installs it, with the W3C
+// propagator, as the OpenTelemetry globals. Call shutdown before exiting to
+// flush buffered spans.
+func Setup(
+	ctx context.Context,
+	cfg config.Tracing,
+) (provider trace.TracerProvider, shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(Propagator)
+
+	var exporter sdktrace.SpanExporter
+
+	switch cfg.Exporter {
+	case config.TracingStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case config.TracingOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+		}
+
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		provider = noop.NewTracerProvider()
+		otel.SetTracerProvider(provider)
+
+		return provider, func(context.Context) error { return nil }, nil
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("create %s span exporter: %w", cfg.Exporter, err)
+	}
+
+	sdkProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(ServiceName),
+		)),
+	)
+	otel.SetTracerProvider(sdkProvider)
+
+	return sdkProvider, sdkProvider.Shutdown, nil
+}
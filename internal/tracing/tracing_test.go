@@ -0,0 +1,239 @@
+package tracing_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/middleware"
+	"github.com/zyrterviews/chirpy/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var errQuery = errors.New("connection reset")
+
+// fakeDB answers every query without a database, failing with err.
+type fakeDB struct {
+	err error
+}
+
+func (f fakeDB) ExecContext(
+	context.Context,
+	string,
+	...interface{},
+) (sql.Result, error) {
+	return nil, f.err
+}
+
+func (f fakeDB) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, f.err
+}
+
+func (f fakeDB) QueryContext(
+	context.Context,
+	string,
+	...interface{},
+) (*sql.Rows, error) {
+	return nil, f.err
+}
+
+func (f fakeDB) QueryRowContext(
+	context.Context,
+	string,
+	...interface{},
+) *sql.Row {
+	return nil
+}
+
+func newProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), exporter
+}
+
+func attr(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func TestQueryName(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should read the sqlc query name", func(t *testing.T) {
+		t.Parallel()
+
+		got := tracing.QueryName("-- name: GetAllChirps :many\nSELECT 1")
+		if got != "GetAllChirps" {
+			t.Fatalf("unexpected query name: %q", got)
+		}
+	})
+
+	t.Run("should fall back for other SQL", func(t *testing.T) {
+		t.Parallel()
+
+		for _, query := range []string{"SELECT 1", "-- name: "} {
+			if got := tracing.QueryName(query); got != "sql" {
+				t.Fatalf("unexpected query name for %q: %q", query, got)
+			}
+		}
+	})
+}
+
+func TestDB(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should record a span per query", func(t *testing.T) {
+		t.Parallel()
+
+		provider, exporter := newProvider()
+		db := tracing.DB(fakeDB{}, provider)
+
+		_, err := db.ExecContext(
+			context.Background(),
+			"-- name: RevokeRefreshToken :exec\nUPDATE refresh_tokens",
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		spans := exporter.GetSpans()
+		if len(spans) != 1 {
+			t.Fatalf("expected 1 span, got %d", len(spans))
+		}
+
+		if spans[0].Name != "RevokeRefreshToken" {
+			t.Fatalf("unexpected span name: %q", spans[0].Name)
+		}
+
+		if got := attr(spans[0], tracing.QueryNameKey).AsString(); got != "RevokeRefreshToken" {
+			t.Fatalf("unexpected query name attribute: %q", got)
+		}
+
+		if spans[0].Status.Code == codes.Error {
+			t.Fatal("did not expect an error status")
+		}
+	})
+
+	t.Run("should mark failed queries", func(t *testing.T) {
+		t.Parallel()
+
+		provider, exporter := newProvider()
+		db := tracing.DB(fakeDB{err: errQuery}, provider)
+
+		_, err := db.QueryContext(context.Background(), "-- name: GetAllChirps :many")
+		if !errors.Is(err, errQuery) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		spans := exporter.GetSpans()
+		if len(spans) != 1 || spans[0].Status.Code != codes.Error {
+			t.Fatalf("expected 1 failed span, got %+v", spans)
+		}
+	})
+
+	t.Run("should not treat missing rows as a failure", func(t *testing.T) {
+		t.Parallel()
+
+		provider, exporter := newProvider()
+		db := tracing.DB(fakeDB{err: sql.ErrNoRows}, provider)
+
+		_, _ = db.PrepareContext(context.Background(), "-- name: GetUser :one")
+
+		spans := exporter.GetSpans()
+		if len(spans) != 1 || spans[0].Status.Code == codes.Error {
+			t.Fatalf("expected 1 successful span, got %+v", spans)
+		}
+	})
+}
+
+func TestTraceMiddleware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should name request spans after the route", func(t *testing.T) {
+		t.Parallel()
+
+		provider, exporter := newProvider()
+		env := &appenv.Env{Tracing: provider}
+		queries := database.New(tracing.DB(fakeDB{}, provider))
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("DELETE /api/refresh/{token}",
+			func(writer http.ResponseWriter, req *http.Request) {
+				_ = queries.RevokeRefreshToken(req.Context(), req.PathValue("token"))
+
+				writer.WriteHeader(http.StatusNoContent)
+			},
+		)
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/refresh/abc", nil)
+		req.Header.Set(
+			"traceparent",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		)
+
+		middleware.Trace(env)(mux).ServeHTTP(httptest.NewRecorder(), req)
+
+		spans := exporter.GetSpans()
+		if len(spans) != 2 {
+			t.Fatalf("expected 2 spans, got %d", len(spans))
+		}
+
+		query, request := spans[0], spans[1]
+
+		if request.Name != "DELETE /api/refresh/{token}" {
+			t.Fatalf("unexpected request span name: %q", request.Name)
+		}
+
+		if got := request.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Fatalf("expected the incoming trace to continue, got %s", got)
+		}
+
+		if got := attr(request, "http.response.status_code").AsInt64(); got != http.StatusNoContent {
+			t.Fatalf("unexpected status attribute: %d", got)
+		}
+
+		if query.Parent.SpanID() != request.SpanContext.SpanID() {
+			t.Fatal("expected the query span to be a child of the request span")
+		}
+	})
+
+	t.Run("should mark server errors", func(t *testing.T) {
+		t.Parallel()
+
+		provider, exporter := newProvider()
+		env := &appenv.Env{Tracing: provider}
+
+		handler := middleware.Trace(env)(http.HandlerFunc(
+			func(writer http.ResponseWriter, _ *http.Request) {
+				http.Error(writer, "boom", http.StatusInternalServerError)
+			},
+		))
+
+		handler.ServeHTTP(
+			httptest.NewRecorder(),
+			httptest.NewRequest(http.MethodGet, "/nowhere", nil),
+		)
+
+		spans := exporter.GetSpans()
+		if len(spans) != 1 {
+			t.Fatalf("expected 1 span, got %d", len(spans))
+		}
+
+		if spans[0].Name != http.MethodGet || spans[0].Status.Code != codes.Error {
+			t.Fatalf("unexpected span: %q %v", spans[0].Name, spans[0].Status)
+		}
+	})
+}
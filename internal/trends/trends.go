@@ -0,0 +1,213 @@
+// Package trends ranks hashtags by how fast they are being used compared with
+// their usual rate. Rankings are computed in the background into the trends
+// table so that reading them never scans chirps.
+package trends
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/database"
+)
+
+const (
+	// DefaultWindows is used when TRENDS_WINDOWS is not set.
+	DefaultWindows = "1h,24h"
+	// RefreshInterval is how often the aggregator recomputes the rankings.
+	RefreshInterval = time.Minute
+	// baselinePeriods is how many windows before the current one make up
+	// the baseline rate.
+	baselinePeriods int64 = 7
+	// authorCap is the most chirps a single author can contribute to a tag
+	// within one window.
+	authorCap int64 = 3
+	// minCount is the least capped usage a tag needs to be ranked at all.
+	minCount int64 = 2
+	lockName       = "chirpy.trends"
+)
+
+var errNoWindows = errors.New("at least one trends window is required")
+
+type Window struct {
+	Name   string
+	Length time.Duration
+}
+
+// ParseWindows parses a comma-separated list of durations such as "1h,24h".
+// Each window keeps the text it was configured with as its name.
+func ParseWindows(raw string) ([]Window, error) {
+	var windows []Window
+
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		length, err := time.ParseDuration(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trends window %q: %w", name, err)
+		}
+
+		if length <= 0 {
+			return nil, fmt.Errorf("trends window %q must be positive", name)
+		}
+
+		windows = append(windows, Window{Name: name, Length: length})
+	}
+
+	if len(windows) == 0 {
+		return nil, errNoWindows
+	}
+
+	return windows, nil
+}
+
+type Aggregator struct {
+	Env     *appenv.Env
+	Windows []Window
+}
+
+// Aggregate recomputes every window. Only one replica does the work at a
+// time; the others skip the run while the lock is held.
+func (a *Aggregator) Aggregate(ctx context.Context) error {
+	tx, err := a.Env.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	qtx := a.Env.TxQueries(tx)
+
+	locked, err := qtx.TryAdvisoryXactLock(ctx, lockName)
+	if err != nil {
+		return fmt.Errorf("acquire trends lock: %w", err)
+	}
+
+	if !locked {
+		return nil
+	}
+
+	now := time.Now().UTC()
+
+	for _, window := range a.Windows {
+		if err := qtx.DeleteTrendsForWindow(ctx, window.Name); err != nil {
+			return fmt.Errorf("clear %s trends: %w", window.Name, err)
+		}
+
+		recentSince := now.Add(-window.Length)
+
+		rows, err := qtx.GetHashtagUsageByAuthor(
+			ctx,
+			database.GetHashtagUsageByAuthorParams{
+				RecentSince: recentSince,
+				BaselineSince: recentSince.Add(
+					-window.Length * time.Duration(baselinePeriods),
+				),
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("count %s hashtag usage: %w", window.Name, err)
+		}
+
+		usage := make([]Usage, 0, len(rows))
+		for _, row := range rows {
+			usage = append(usage, Usage(row))
+		}
+
+		opts := database.CreateTrendsParams{WindowName: window.Name}
+
+		for _, trend := range Rank(usage) {
+			opts.Tags = append(opts.Tags, trend.Tag)
+			opts.RecentCounts = append(opts.RecentCounts, trend.RecentCount)
+			opts.BaselineCounts = append(opts.BaselineCounts, trend.BaselineCount)
+			opts.Scores = append(opts.Scores, trend.Score)
+		}
+
+		if err := qtx.CreateTrends(ctx, opts); err != nil {
+			return fmt.Errorf("store %s trends: %w", window.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Usage is how many chirps one author tagged with Tag in the window being
+// ranked, and in the baseline periods before it.
+type Usage struct {
+	Tag      string
+	Recent   int64
+	Baseline int64
+}
+
+type Trend struct {
+	Tag           string
+	RecentCount   int64
+	BaselineCount float64
+	Score         float64
+}
+
+// Rank scores every tag in usage, highest score first. An author counts for
+// at most authorCap chirps per window, so that no single account can make a
+// tag trend. The baseline is the capped usage averaged over the
+// baselinePeriods windows before, and the score how many times it has been
+// exceeded, smoothed so that new tags do not divide by zero. Tags used fewer
+// than minCount times, once capped, are left out.
+func Rank(usage []Usage) []Trend {
+	type counts struct {
+		recent, baseline int64
+	}
+
+	byTag := make(map[string]*counts)
+
+	for _, u := range usage {
+		c, ok := byTag[u.Tag]
+		if !ok {
+			c = &counts{}
+			byTag[u.Tag] = c
+		}
+
+		c.recent += min(u.Recent, authorCap)
+		c.baseline += min(u.Baseline, authorCap*baselinePeriods)
+	}
+
+	ranked := make([]Trend, 0, len(byTag))
+
+	for tag, c := range byTag {
+		if c.recent < minCount {
+			continue
+		}
+
+		baseline := float64(c.baseline) / float64(baselinePeriods)
+
+		ranked = append(ranked, Trend{
+			Tag:           tag,
+			RecentCount:   c.recent,
+			BaselineCount: baseline,
+			Score:         float64(c.recent+1) / (baseline + 1),
+		})
+	}
+
+	// The order ListTrends reads them in.
+	slices.SortFunc(ranked, func(a, b Trend) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(b.RecentCount, a.RecentCount),
+			strings.Compare(a.Tag, b.Tag),
+		)
+	})
+
+	return ranked
+}
@@ -0,0 +1,112 @@
+package trends_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zyrterviews/chirpy/internal/trends"
+)
+
+func TestParseWindows(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should parse every window and keep its name", func(t *testing.T) {
+		t.Parallel()
+
+		windows, err := trends.ParseWindows("1h, 24h")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := []trends.Window{
+			{Name: "1h", Length: time.Hour},
+			{Name: "24h", Length: 24 * time.Hour},
+		}
+
+		if len(windows) != len(want) {
+			t.Fatalf("unexpected windows: got %+v, want %+v", windows, want)
+		}
+
+		for i := range want {
+			if windows[i] != want[i] {
+				t.Fatalf("unexpected window: got %+v, want %+v", windows[i], want[i])
+			}
+		}
+	})
+
+	t.Run("should error on an invalid duration", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := trends.ParseWindows("1h,soon"); err == nil {
+			t.Fatal("expected an error, but got none")
+		}
+	})
+
+	t.Run("should error if no window is given", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := trends.ParseWindows(" , "); err == nil {
+			t.Fatal("expected an error, but got none")
+		}
+	})
+}
+
+func TestRank(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should cap how much each author counts", func(t *testing.T) {
+		t.Parallel()
+
+		ranked := trends.Rank([]trends.Usage{
+			{Tag: "spam", Recent: 50, Baseline: 500},
+			{Tag: "spam", Recent: 1, Baseline: 0},
+		})
+
+		if len(ranked) != 1 {
+			t.Fatalf("unexpected trends: %+v", ranked)
+		}
+
+		// 3 recent and 3 per baseline period from the spammer, plus 1.
+		if ranked[0].RecentCount != 4 || ranked[0].BaselineCount != 3 {
+			t.Fatalf("unexpected counts: %+v", ranked[0])
+		}
+	})
+
+	t.Run("should score tags against their baseline", func(t *testing.T) {
+		t.Parallel()
+
+		ranked := trends.Rank([]trends.Usage{
+			{Tag: "usual", Recent: 2, Baseline: 7},
+			{Tag: "usual", Recent: 2, Baseline: 7},
+			{Tag: "new", Recent: 3, Baseline: 0},
+		})
+
+		want := []trends.Trend{
+			{Tag: "new", RecentCount: 3, BaselineCount: 0, Score: 4},
+			{Tag: "usual", RecentCount: 4, BaselineCount: 2, Score: 5.0 / 3},
+		}
+
+		if len(ranked) != len(want) {
+			t.Fatalf("unexpected trends: got %+v, want %+v", ranked, want)
+		}
+
+		for i := range want {
+			if ranked[i] != want[i] {
+				t.Fatalf("unexpected trend: got %+v, want %+v", ranked[i], want[i])
+			}
+		}
+	})
+
+	t.Run("should leave out rarely used tags", func(t *testing.T) {
+		t.Parallel()
+
+		ranked := trends.Rank([]trends.Usage{
+			{Tag: "once", Recent: 1, Baseline: 0},
+			{Tag: "past", Recent: 0, Baseline: 20},
+		})
+
+		if len(ranked) != 0 {
+			t.Fatalf("unexpected trends: %+v", ranked)
+		}
+	})
+}
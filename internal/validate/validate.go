@@ -0,0 +1,188 @@
+// Package validate decodes JSON request bodies and checks their fields.
+//
+// A body that is not the JSON a handler expects is answered with a 400
+// invalid_json problem; a well-formed body with unacceptable field values
+// with a 422 validation_failed problem. Both list the offending fields when
+// they are known.
+package validate
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zyrterviews/chirpy/internal/problem"
+)
+
+const (
+	// MaxBodySize is the largest JSON body Decode reads.
+	MaxBodySize int64 = 1 << 20
+
+	MinPasswordLength int = 8
+	// MaxPasswordBytes is the most bcrypt hashes; longer passwords would
+	// be silently truncated.
+	MaxPasswordBytes int = 72
+
+	maxEmailLength int = 254
+)
+
+// Decode reads the JSON body of req into dst. Bodies over MaxBodySize,
+// unknown fields, values of the wrong type and trailing data are rejected.
+// Decode reports whether dst was filled; when it was not, the problem has
+// already been sent.
+func Decode(writer http.ResponseWriter, req *http.Request, dst any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(writer, req.Body, MaxBodySize))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(dst)
+	if err == nil && decoder.Decode(&struct{}{}) != io.EOF {
+		err = errTrailingData
+	}
+
+	if err != nil {
+		decodeProblem(err).Write(writer)
+
+		return false
+	}
+
+	return true
+}
+
+var errTrailingData = errors.New("request body must hold a single JSON value")
+
+func decodeProblem(err error) *problem.Problem {
+	var (
+		maxBytesErr *http.MaxBytesError
+		typeErr     *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return problem.New(
+			http.StatusRequestEntityTooLarge,
+			problem.CodePayloadTooLarge,
+			fmt.Sprintf("request body must not exceed %d bytes", MaxBodySize),
+		)
+	case errors.Is(err, io.EOF):
+		return invalidJSON("request body is empty")
+	case errors.Is(err, errTrailingData):
+		return invalidJSON(err.Error())
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		p := invalidJSON("some fields have the wrong type")
+		p.Errors = []problem.FieldError{{
+			Field:   typeErr.Field,
+			Message: "must be " + jsonType(typeErr.Type),
+		}}
+
+		return p
+	}
+
+	// encoding/json has no error type for unknown fields.
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		p := invalidJSON("some fields are unknown")
+		p.Errors = []problem.FieldError{{
+			Field:   strings.Trim(field, `"`),
+			Message: "is not a known field",
+		}}
+
+		return p
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return invalidJSON("request body is not valid JSON")
+	}
+
+	// Errors from UnmarshalText, such as a malformed UUID or time, do not
+	// name their field.
+	return invalidJSON(
+		"request body is not the JSON expected: " +
+			strings.TrimPrefix(err.Error(), "json: "),
+	)
+}
+
+func invalidJSON(detail string) *problem.Problem {
+	return problem.New(http.StatusBadRequest, problem.CodeInvalidJSON, detail)
+}
+
+var textUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// jsonType names the JSON type that a value of type t is decoded from.
+func jsonType(t reflect.Type) string {
+	if reflect.PointerTo(t).Implements(textUnmarshaler) {
+		return "a string"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map, reflect.Pointer:
+		return "an object"
+	default:
+		return "a number"
+	}
+}
+
+// Errors collects the field errors found while checking a request.
+type Errors []problem.FieldError
+
+func (e *Errors) Add(field, message string) {
+	*e = append(*e, problem.FieldError{Field: field, Message: message})
+}
+
+// Email checks that value is a bare email address, such as
+// "jane@example.com".
+func (e *Errors) Email(field, value string) {
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value || len(value) > maxEmailLength {
+		e.Add(field, "must be an email address")
+	}
+}
+
+// Password checks value against the password policy: at least
+// MinPasswordLength characters and at most MaxPasswordBytes bytes.
+func (e *Errors) Password(field, value string) {
+	switch {
+	case utf8.RuneCountInString(value) < MinPasswordLength:
+		e.Add(
+			field,
+			fmt.Sprintf("must be at least %d characters", MinPasswordLength),
+		)
+	case len(value) > MaxPasswordBytes:
+		e.Add(
+			field,
+			fmt.Sprintf("must be at most %d bytes", MaxPasswordBytes),
+		)
+	}
+}
+
+// MaxLength checks that value is at most maxRunes characters long.
+// Characters are counted as runes, not bytes, so that "é" counts once.
+func (e *Errors) MaxLength(field, value string, maxRunes int) {
+	if utf8.RuneCountInString(value) > maxRunes {
+		e.Add(field, fmt.Sprintf("must be at most %d characters", maxRunes))
+	}
+}
+
+// Write sends a 422 problem listing e, if e is not empty, and reports
+// whether it did.
+func (e Errors) Write(writer http.ResponseWriter) bool {
+	if len(e) == 0 {
+		return false
+	}
+
+	problem.Invalid(writer, e)
+
+	return true
+}
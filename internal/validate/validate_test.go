@@ -0,0 +1,186 @@
+package validate_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zyrterviews/chirpy/internal/problem"
+	"github.com/zyrterviews/chirpy/internal/validate"
+)
+
+type input struct {
+	Email string `json:"email"`
+	Count int    `json:"count"`
+}
+
+func decode(t *testing.T, body string) (bool, input, *httptest.ResponseRecorder) {
+	t.Helper()
+
+	var data input
+
+	rec := httptest.NewRecorder()
+	ok := validate.Decode(
+		rec,
+		httptest.NewRequest(http.MethodPost, "/api/test", strings.NewReader(body)),
+		&data,
+	)
+
+	return ok, data, rec
+}
+
+func readProblem(t *testing.T, rec *httptest.ResponseRecorder) problem.Problem {
+	t.Helper()
+
+	var body problem.Problem
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return body
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should fill the destination", func(t *testing.T) {
+		t.Parallel()
+
+		ok, data, _ := decode(t, `{"email": "jane@example.com", "count": 2}`)
+		if !ok || data.Email != "jane@example.com" || data.Count != 2 {
+			t.Fatalf("unexpected result: %v %+v", ok, data)
+		}
+	})
+
+	tests := map[string]struct {
+		body   string
+		status int
+		code   problem.Code
+		field  string
+	}{
+		"empty body": {
+			body:   "",
+			status: http.StatusBadRequest,
+			code:   problem.CodeInvalidJSON,
+		},
+		"malformed JSON": {
+			body:   `{"email": `,
+			status: http.StatusBadRequest,
+			code:   problem.CodeInvalidJSON,
+		},
+		"unknown field": {
+			body:   `{"email": "jane@example.com", "admin": true}`,
+			status: http.StatusBadRequest,
+			code:   problem.CodeInvalidJSON,
+			field:  "admin",
+		},
+		"wrong type": {
+			body:   `{"count": "two"}`,
+			status: http.StatusBadRequest,
+			code:   problem.CodeInvalidJSON,
+			field:  "count",
+		},
+		"trailing data": {
+			body:   `{"count": 1}{"count": 2}`,
+			status: http.StatusBadRequest,
+			code:   problem.CodeInvalidJSON,
+		},
+		"oversized body": {
+			body: `{"email": "` +
+				strings.Repeat("a", int(validate.MaxBodySize)) + `"}`,
+			status: http.StatusRequestEntityTooLarge,
+			code:   problem.CodePayloadTooLarge,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run("should reject "+name, func(t *testing.T) {
+			t.Parallel()
+
+			ok, _, rec := decode(t, tt.body)
+			if ok {
+				t.Fatal("expected the body to be rejected")
+			}
+
+			body := readProblem(t, rec)
+
+			if rec.Code != tt.status || body.Code != tt.code {
+				t.Fatalf("unexpected problem: %d %+v", rec.Code, body)
+			}
+
+			if tt.field != "" &&
+				(len(body.Errors) != 1 || body.Errors[0].Field != tt.field) {
+				t.Fatalf("expected an error for %q, got %+v", tt.field, body.Errors)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should accept valid fields", func(t *testing.T) {
+		t.Parallel()
+
+		var errs validate.Errors
+
+		errs.Email("email", "jane@example.com")
+		errs.Password("password", "correct horse")
+		errs.MaxLength("body", strings.Repeat("é", 140), 140)
+
+		if len(errs) != 0 {
+			t.Fatalf("unexpected errors: %+v", errs)
+		}
+	})
+
+	t.Run("should reject invalid fields", func(t *testing.T) {
+		t.Parallel()
+
+		var errs validate.Errors
+
+		errs.Email("email", "not an email")
+		errs.Email("contact", "Jane <jane@example.com>")
+		errs.Password("password", "short")
+		errs.Password("passphrase", strings.Repeat("é", validate.MaxPasswordBytes))
+		errs.MaxLength("body", strings.Repeat("é", 141), 140)
+
+		want := []string{"email", "contact", "password", "passphrase", "body"}
+		if len(errs) != len(want) {
+			t.Fatalf("expected %d errors, got %+v", len(want), errs)
+		}
+
+		for i, field := range want {
+			if errs[i].Field != field {
+				t.Fatalf("expected an error for %q, got %+v", field, errs[i])
+			}
+		}
+	})
+
+	t.Run("should send a 422 listing the fields", func(t *testing.T) {
+		t.Parallel()
+
+		errs := validate.Errors{}
+
+		rec := httptest.NewRecorder()
+		if errs.Write(rec) {
+			t.Fatal("expected nothing to be sent without errors")
+		}
+
+		errs.Add("body", "must be at most 140 characters")
+
+		if !errs.Write(rec) {
+			t.Fatal("expected a problem to be sent")
+		}
+
+		body := readProblem(t, rec)
+
+		if rec.Code != http.StatusUnprocessableEntity ||
+			body.Code != problem.CodeValidationFailed ||
+			len(body.Errors) != 1 {
+			t.Fatalf("unexpected problem: %d %+v", rec.Code, body)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+// Package worker runs the background jobs that live inside the chirpy
+// process next to the HTTP server.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zyrterviews/chirpy/internal/logging"
+)
+
+// StalledAfter is how many intervals may pass without a successful run
+// before Check reports the worker as unhealthy.
+const StalledAfter = 3
+
+var errNotRunning = errors.New("not running")
+
+type Job func(ctx context.Context) error
+
+// Periodic runs Job once on start and then every Interval until the context
+// is cancelled. A failing run is logged and retried on the next tick.
+type Periodic struct {
+	Name     string
+	Interval time.Duration
+	Job      Job
+
+	mu          sync.Mutex
+	running     bool
+	lastSuccess time.Time
+	lastErr     error
+}
+
+func (p *Periodic) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	p.mu.Lock()
+	p.running = true
+	// Starting counts as a success so that a slow first run is not
+	// reported as stalled.
+	p.lastSuccess = time.Now()
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.running = false
+		p.mu.Unlock()
+	}()
+
+	for {
+		start := time.Now()
+
+		err := p.Job(ctx)
+		if err != nil && ctx.Err() == nil {
+			logging.FromContext(ctx).Error(
+				"worker: job failed",
+				"job", p.Name,
+				"error", err,
+				"duration_ms", time.Since(start).Milliseconds(),
+			)
+		}
+
+		p.record(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Periodic) record(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lastErr = err
+	if err == nil {
+		p.lastSuccess = time.Now()
+	}
+}
+
+// Check reports an error when the worker is not running, or when no run has
+// succeeded in the last StalledAfter intervals because the job keeps
+// failing or is stuck.
+func (p *Periodic) Check(context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.running {
+		return errNotRunning
+	}
+
+	since := time.Since(p.lastSuccess)
+	if since <= StalledAfter*p.Interval {
+		return nil
+	}
+
+	if p.lastErr != nil {
+		return fmt.Errorf(
+			"no successful run for %s: %w",
+			since.Round(time.Second),
+			p.lastErr,
+		)
+	}
+
+	return fmt.Errorf("no successful run for %s", since.Round(time.Second))
+}
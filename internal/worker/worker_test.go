@@ -0,0 +1,67 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zyrterviews/chirpy/internal/worker"
+)
+
+const interval = 20 * time.Millisecond
+
+var errJob = errors.New("job failed")
+
+func TestPeriodicCheck(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should fail before the worker runs", func(t *testing.T) {
+		t.Parallel()
+
+		periodic := &worker.Periodic{Name: "idle", Interval: interval}
+
+		if err := periodic.Check(context.Background()); err == nil {
+			t.Fatal("expected an error, but got none")
+		}
+	})
+
+	t.Run("should pass while runs succeed", func(t *testing.T) {
+		t.Parallel()
+
+		periodic := runFor(t, func(context.Context) error { return nil })
+
+		if err := periodic.Check(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("should fail once runs keep failing", func(t *testing.T) {
+		t.Parallel()
+
+		periodic := runFor(t, func(context.Context) error { return errJob })
+
+		err := periodic.Check(context.Background())
+		if err == nil || !strings.Contains(err.Error(), errJob.Error()) {
+			t.Fatalf("expected the last job error, got %v", err)
+		}
+	})
+}
+
+// runFor starts a worker running job and waits long enough for it to be
+// considered stalled if no run succeeds.
+func runFor(t *testing.T, job worker.Job) *worker.Periodic {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	periodic := &worker.Periodic{Name: "test", Interval: interval, Job: job}
+
+	go periodic.Run(ctx)
+
+	time.Sleep((worker.StalledAfter + 2) * interval)
+
+	return periodic
+}
@@ -0,0 +1,196 @@
+package chirpyclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// RefreshLeeway is how long before it expires an access token is refreshed.
+const RefreshLeeway = 30 * time.Second
+
+var errNoRefreshToken = errors.New("no refresh token")
+
+type User struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Email       string    `json:"email"`
+	Username    string    `json:"username,omitempty"`
+	IsChirpyRed bool      `json:"is_chirpy_red"`
+	// Token and RefreshToken are only set by Login.
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Signup creates an account. It does not log in.
+func (c *Client) Signup(ctx context.Context, email, password string) (*User, error) {
+	var user User
+
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/users",
+		body:   credentials{Email: email, Password: password},
+	}, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Login logs in and makes c send the user's access token, refreshing it as
+// needed, on every request.
+func (c *Client) Login(ctx context.Context, email, password string) (*User, error) {
+	var user User
+
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/login",
+		body:   credentials{Email: email, Password: password},
+	}, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setTokenSource(c.RefreshTokenSource(user.Token, user.RefreshToken))
+
+	return &user, nil
+}
+
+// Logout revokes the refresh token of the user logged in with Login and
+// stops authenticating requests.
+func (c *Client) Logout(ctx context.Context) error {
+	source, ok := c.tokenSource().(*RefreshTokenSource)
+	if !ok {
+		c.setTokenSource(nil)
+
+		return nil
+	}
+
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/revoke",
+		auth:   "Bearer " + source.refreshToken,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	c.setTokenSource(nil)
+
+	return nil
+}
+
+// TokenSource supplies the access token sent with authenticated requests.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+type staticToken string
+
+func (t staticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// StaticToken always supplies token, which is never refreshed.
+func StaticToken(token string) TokenSource {
+	return staticToken(token)
+}
+
+// RefreshTokenSource supplies an access token and uses a refresh token to
+// replace it shortly before it expires, or when the API rejects it.
+type RefreshTokenSource struct {
+	client       *Client
+	refreshToken string
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// RefreshTokenSource returns a token source that starts with accessToken,
+// which may be empty, and refreshes it with refreshToken through c.
+func (c *Client) RefreshTokenSource(
+	accessToken string,
+	refreshToken string,
+) *RefreshTokenSource {
+	return &RefreshTokenSource{
+		client:       c,
+		refreshToken: refreshToken,
+		accessToken:  accessToken,
+		expiresAt:    expiry(accessToken),
+	}
+}
+
+func (s *RefreshTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != "" && time.Until(s.expiresAt) > RefreshLeeway {
+		return s.accessToken, nil
+	}
+
+	return s.refreshLocked(ctx)
+}
+
+// refresh replaces stale, an access token the API rejected, unless another
+// request already did.
+func (s *RefreshTokenSource) refresh(ctx context.Context, stale string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != stale {
+		return s.accessToken, nil
+	}
+
+	return s.refreshLocked(ctx)
+}
+
+func (s *RefreshTokenSource) refreshLocked(ctx context.Context) (string, error) {
+	if s.refreshToken == "" {
+		return "", errNoRefreshToken
+	}
+
+	var res struct {
+		Token string `json:"token"`
+	}
+
+	err := s.client.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/refresh",
+		auth:   "Bearer " + s.refreshToken,
+	}, &res)
+	if err != nil {
+		return "", err
+	}
+
+	s.accessToken = res.Token
+	s.expiresAt = expiry(res.Token)
+
+	return s.accessToken, nil
+}
+
+// expiry reads the expiry of an access token without checking its
+// signature, which only the server can do. Tokens without one are treated
+// as expired.
+func expiry(token string) time.Time {
+	var claims jwt.RegisteredClaims
+
+	_, _, err := jwt.NewParser().ParseUnverified(token, &claims)
+	if err != nil || claims.ExpiresAt == nil {
+		return time.Time{}
+	}
+
+	return claims.ExpiresAt.Time
+}
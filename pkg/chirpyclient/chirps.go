@@ -0,0 +1,206 @@
+package chirpyclient
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ChirpStatusPublished = "published"
+	ChirpStatusScheduled = "scheduled"
+	ChirpStatusDraft     = "draft"
+)
+
+type Chirp struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Body      string     `json:"body"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Status    string     `json:"status"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	Entities  []Entity   `json:"entities"`
+	Media     []Media    `json:"media"`
+	Poll      *Poll      `json:"poll,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Entity is a mention, hashtag or link found in a chirp body. Start and End
+// are byte offsets into the body.
+type Entity struct {
+	Type   string     `json:"type"`
+	Text   string     `json:"text"`
+	Start  int        `json:"start"`
+	End    int        `json:"end"`
+	UserID *uuid.UUID `json:"user_id,omitempty"`
+}
+
+type Media struct {
+	ID           uuid.UUID `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	ContentType  string    `json:"content_type"`
+	Width        int32     `json:"width"`
+	Height       int32     `json:"height"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+}
+
+// Poll is a poll as seen by the caller. Vote counts are only set once the
+// caller has voted or the poll has closed.
+type Poll struct {
+	ID             uuid.UUID    `json:"id"`
+	ClosesAt       time.Time    `json:"closes_at"`
+	Closed         bool         `json:"closed"`
+	Options        []PollOption `json:"options"`
+	TotalVotes     *int64       `json:"total_votes,omitempty"`
+	ViewerOptionID *uuid.UUID   `json:"viewer_option_id,omitempty"`
+}
+
+type PollOption struct {
+	ID    uuid.UUID `json:"id"`
+	Label string    `json:"label"`
+	Votes *int64    `json:"votes,omitempty"`
+}
+
+type PollInput struct {
+	Options  []string  `json:"options"`
+	ClosesAt time.Time `json:"closes_at"`
+}
+
+type ChirpInput struct {
+	Body     string      `json:"body"`
+	MediaIDs []uuid.UUID `json:"media_ids,omitempty"`
+	// PublishAt schedules the chirp instead of publishing it right away.
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// Draft keeps the chirp unpublished until it is published.
+	Draft bool       `json:"draft,omitempty"`
+	Poll  *PollInput `json:"poll,omitempty"`
+}
+
+// CreateChirp posts a chirp as the logged-in user.
+func (c *Client) CreateChirp(ctx context.Context, input ChirpInput) (*Chirp, error) {
+	var chirp Chirp
+
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/chirps",
+		body:   input,
+	}, &chirp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chirp, nil
+}
+
+// GetChirp returns a published chirp.
+func (c *Client) GetChirp(ctx context.Context, id uuid.UUID) (*Chirp, error) {
+	var chirp Chirp
+
+	err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/api/chirps/" + id.String(),
+	}, &chirp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chirp, nil
+}
+
+// DeleteChirp moves one of the logged-in user's chirps to their trash.
+func (c *Client) DeleteChirp(ctx context.Context, id uuid.UUID) error {
+	return c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   "/api/chirps/" + id.String(),
+	}, nil)
+}
+
+// RestoreChirp takes a chirp out of the logged-in user's trash.
+func (c *Client) RestoreChirp(ctx context.Context, id uuid.UUID) (*Chirp, error) {
+	var chirp Chirp
+
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/trash/" + id.String() + "/restore",
+	}, &chirp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chirp, nil
+}
+
+type ChirpsOptions struct {
+	// AuthorID restricts the chirps to those of one user.
+	AuthorID uuid.UUID
+	// Sort is "asc", the default, or "desc" by creation time.
+	Sort string
+}
+
+// Chirps iterates over published chirps. The API returns them in a single
+// response, so the iterator yields at most one error, before any chirp.
+func (c *Client) Chirps(
+	ctx context.Context,
+	opts ChirpsOptions,
+) iter.Seq2[Chirp, error] {
+	query := url.Values{}
+
+	if opts.AuthorID != uuid.Nil {
+		query.Set("author_id", opts.AuthorID.String())
+	}
+
+	if opts.Sort != "" {
+		query.Set("sort", opts.Sort)
+	}
+
+	return func(yield func(Chirp, error) bool) {
+		var chirps []Chirp
+
+		err := c.do(ctx, request{
+			method: http.MethodGet,
+			path:   "/api/chirps",
+			query:  query,
+		}, &chirps)
+		if err != nil {
+			yield(Chirp{}, err)
+
+			return
+		}
+
+		for _, chirp := range chirps {
+			if !yield(chirp, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Bookmarks iterates over the logged-in user's bookmarked chirps.
+func (c *Client) Bookmarks(ctx context.Context) iter.Seq2[Chirp, error] {
+	return paginate[Chirp](ctx, c, "/api/bookmarks", nil)
+}
+
+// Trash iterates over the logged-in user's deleted chirps that can still be
+// restored.
+func (c *Client) Trash(ctx context.Context) iter.Seq2[Chirp, error] {
+	return paginate[Chirp](ctx, c, "/api/trash", nil)
+}
+
+// ListChirps iterates over the chirps of the members of a list.
+func (c *Client) ListChirps(
+	ctx context.Context,
+	listID uuid.UUID,
+) iter.Seq2[Chirp, error] {
+	return paginate[Chirp](
+		ctx,
+		c,
+		"/api/lists/"+listID.String()+"/chirps",
+		nil,
+	)
+}
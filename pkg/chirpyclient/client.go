@@ -0,0 +1,241 @@
+// Package chirpyclient is the Go client for the chirpy API.
+//
+//	client := chirpyclient.New("https://chirpy.example.com")
+//
+//	if _, err := client.Login(ctx, "jane@example.com", password); err != nil {
+//		return err
+//	}
+//
+//	chirp, err := client.CreateChirp(ctx, chirpyclient.ChirpInput{
+//		Body: "Hello, world!",
+//	})
+//
+// Login installs a token source that refreshes the access token before it
+// expires, so a logged-in client can be used for as long as its refresh
+// token is valid. Services holding tokens of their own pass them with
+// WithRefreshToken or WithTokenSource instead.
+//
+// Errors returned by the API are *Error values. Compare them with the
+// sentinels of this package:
+//
+//	if errors.Is(err, chirpyclient.ErrNotFound) { ... }
+package chirpyclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds every request made with the default HTTP client.
+// It must outlast the 25 second long poll of GET /api/messages/poll.
+const DefaultTimeout = 30 * time.Second
+
+// Client calls the chirpy API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+
+	mu     sync.RWMutex
+	tokens TokenSource
+}
+
+type Option func(c *Client)
+
+// WithHTTPClient makes the client send its requests with httpClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTokenSource authenticates requests with the tokens of source.
+func WithTokenSource(source TokenSource) Option {
+	return func(c *Client) {
+		c.tokens = source
+	}
+}
+
+// WithRefreshToken authenticates requests like a client logged in with
+// Login, starting from a saved pair of tokens. accessToken may be empty.
+func WithRefreshToken(accessToken, refreshToken string) Option {
+	return func(c *Client) {
+		c.tokens = c.RefreshTokenSource(accessToken, refreshToken)
+	}
+}
+
+// New returns a client for the chirpy server at baseURL, such as
+// "https://chirpy.example.com". It panics if baseURL is not an absolute
+// URL.
+func New(baseURL string, opts ...Option) *Client {
+	parsed, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || !parsed.IsAbs() {
+		panic(fmt.Sprintf("chirpyclient: invalid base URL %q", baseURL))
+	}
+
+	c := &Client{
+		baseURL:    parsed,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+func (c *Client) tokenSource() TokenSource {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.tokens
+}
+
+func (c *Client) setTokenSource(source TokenSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokens = source
+}
+
+// request describes a call to the API.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	// body is sent as JSON when it is not nil.
+	body any
+	// auth is the Authorization header. When empty, the access token of
+	// the client's token source is sent, if it has one.
+	auth string
+}
+
+// do sends req and decodes a JSON response into out, which may be nil. An
+// authenticated request answered with a 401 is retried once with a fresh
+// token when the token source can refresh.
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	var body []byte
+
+	if req.body != nil {
+		var err error
+
+		body, err = json.Marshal(req.body)
+		if err != nil {
+			return fmt.Errorf("chirpyclient: encode request: %w", err)
+		}
+	}
+
+	var (
+		source TokenSource
+		token  string
+	)
+
+	if req.auth == "" {
+		source = c.tokenSource()
+	}
+
+	if source != nil {
+		var err error
+
+		token, err = source.Token(ctx)
+		if err != nil {
+			return fmt.Errorf("chirpyclient: get token: %w", err)
+		}
+	}
+
+	res, err := c.send(ctx, req, body, token)
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusUnauthorized {
+		if refresher, ok := source.(*RefreshTokenSource); ok {
+			token, err = refresher.refresh(ctx, token)
+			if err != nil {
+				return fmt.Errorf("chirpyclient: refresh token: %w", err)
+			}
+
+			res, err = c.send(ctx, req, body, token)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("chirpyclient: decode response: %w", err)
+	}
+
+	return nil
+}
+
+// send makes one attempt at req, authenticated with token unless req sets
+// its own Authorization header. API errors are returned as *Error.
+func (c *Client) send(
+	ctx context.Context,
+	req request,
+	body []byte,
+	token string,
+) (*http.Response, error) {
+	endpoint := c.baseURL.JoinPath(req.path)
+	endpoint.RawQuery = req.query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(
+		ctx,
+		req.method,
+		endpoint.String(),
+		reader,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("chirpyclient: %w", err)
+	}
+
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	httpReq.Header.Set("Accept", "application/json")
+
+	switch {
+	case req.auth != "":
+		httpReq.Header.Set("Authorization", req.auth)
+	case token != "":
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"chirpyclient: %s %s: %w",
+			req.method,
+			req.path,
+			err,
+		)
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		defer res.Body.Close()
+
+		return nil, readError(res)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,479 @@
+package chirpyclient_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zyrterviews/chirpy/internal/auth"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/pkg/chirpyclient"
+)
+
+const password = "correct horse battery"
+
+// loggedIn signs up a user and returns a client logged in as them.
+func loggedIn(
+	t *testing.T,
+	srv *server,
+	email string,
+) (*chirpyclient.Client, *chirpyclient.User) {
+	t.Helper()
+
+	client := chirpyclient.New(srv.URL)
+	ctx := context.Background()
+
+	if _, err := client.Signup(ctx, email, password); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	user, err := client.Login(ctx, email, password)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return client, user
+}
+
+func TestAuth(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t, time.Hour)
+	ctx := context.Background()
+
+	t.Run("should sign up and log in", func(t *testing.T) {
+		client, user := loggedIn(t, srv, "jane@example.com")
+
+		if user.Email != "jane@example.com" || user.ID == uuid.Nil {
+			t.Fatalf("unexpected user: %+v", user)
+		}
+
+		if user.Token == "" || user.RefreshToken == "" {
+			t.Fatal("expected tokens")
+		}
+
+		if _, err := client.CreateChirp(ctx, chirpyclient.ChirpInput{
+			Body: "logged in",
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("should report a taken email", func(t *testing.T) {
+		client := chirpyclient.New(srv.URL)
+
+		_, err := client.Signup(ctx, "jane@example.com", password)
+		if !errors.Is(err, chirpyclient.ErrAlreadyTaken) {
+			t.Fatalf("expected ErrAlreadyTaken, got %v", err)
+		}
+	})
+
+	t.Run("should report wrong credentials", func(t *testing.T) {
+		client := chirpyclient.New(srv.URL)
+
+		_, err := client.Login(ctx, "jane@example.com", "not the password")
+		if !errors.Is(err, chirpyclient.ErrInvalidCredentials) {
+			t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+
+	t.Run("should map field errors", func(t *testing.T) {
+		client := chirpyclient.New(srv.URL)
+
+		_, err := client.Signup(ctx, "not an email", "short")
+		if !errors.Is(err, chirpyclient.ErrValidationFailed) {
+			t.Fatalf("expected ErrValidationFailed, got %v", err)
+		}
+
+		var apiErr *chirpyclient.Error
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected an *Error, got %T", err)
+		}
+
+		if apiErr.Status != http.StatusUnprocessableEntity ||
+			len(apiErr.Fields) != 2 ||
+			apiErr.Fields[0].Field != "email" ||
+			apiErr.Fields[1].Field != "password" {
+			t.Fatalf("unexpected error: %+v", apiErr)
+		}
+	})
+
+	t.Run("should revoke the refresh token on logout", func(t *testing.T) {
+		client, user := loggedIn(t, srv, "john@example.com")
+
+		if err := client.Logout(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, err := client.CreateChirp(ctx, chirpyclient.ChirpInput{Body: "hi"})
+		if !errors.Is(err, chirpyclient.ErrUnauthorized) {
+			t.Fatalf("expected ErrUnauthorized, got %v", err)
+		}
+
+		source := client.RefreshTokenSource("", user.RefreshToken)
+
+		if _, err := source.Token(ctx); !errors.Is(err, chirpyclient.ErrUnauthorized) {
+			t.Fatalf("expected ErrUnauthorized, got %v", err)
+		}
+	})
+}
+
+func TestRefreshTokenSource(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("should refresh a token about to expire", func(t *testing.T) {
+		t.Parallel()
+
+		srv := newServer(t, 10*time.Second)
+		client, _ := loggedIn(t, srv, "jane@example.com")
+
+		if _, err := client.CreateChirp(ctx, chirpyclient.ChirpInput{
+			Body: "fresh",
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if calls := srv.calls("POST /api/refresh"); calls != 1 {
+			t.Fatalf("expected 1 refresh, got %d", calls)
+		}
+	})
+
+	t.Run("should refresh a rejected token and retry", func(t *testing.T) {
+		t.Parallel()
+
+		srv := newServer(t, time.Hour)
+		_, user := loggedIn(t, srv, "jane@example.com")
+
+		forged, err := auth.MakeJWT(user.ID, "another secret", time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		client := chirpyclient.New(
+			srv.URL,
+			chirpyclient.WithRefreshToken(forged, user.RefreshToken),
+		)
+
+		if _, err := client.CreateChirp(ctx, chirpyclient.ChirpInput{
+			Body: "retried",
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if calls := srv.calls("POST /api/chirps"); calls != 2 {
+			t.Fatalf("expected 2 attempts, got %d", calls)
+		}
+
+		if calls := srv.calls("POST /api/refresh"); calls != 1 {
+			t.Fatalf("expected 1 refresh, got %d", calls)
+		}
+	})
+
+	t.Run("should not retry with a static token", func(t *testing.T) {
+		t.Parallel()
+
+		srv := newServer(t, time.Hour)
+		client := chirpyclient.New(
+			srv.URL,
+			chirpyclient.WithTokenSource(chirpyclient.StaticToken("bogus")),
+		)
+
+		_, err := client.CreateChirp(ctx, chirpyclient.ChirpInput{Body: "hi"})
+		if !errors.Is(err, chirpyclient.ErrUnauthorized) {
+			t.Fatalf("expected ErrUnauthorized, got %v", err)
+		}
+
+		if calls := srv.calls("POST /api/chirps"); calls != 1 {
+			t.Fatalf("expected 1 attempt, got %d", calls)
+		}
+	})
+}
+
+func TestChirps(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t, time.Hour)
+	client, user := loggedIn(t, srv, "jane@example.com")
+	ctx := context.Background()
+
+	var created *chirpyclient.Chirp
+
+	t.Run("should create a chirp", func(t *testing.T) {
+		var err error
+
+		created, err = client.CreateChirp(ctx, chirpyclient.ChirpInput{
+			Body: "What a kerfuffle",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if created.Body != "What a ****" || created.UserID != user.ID ||
+			created.Status != chirpyclient.ChirpStatusPublished {
+			t.Fatalf("unexpected chirp: %+v", created)
+		}
+	})
+
+	t.Run("should get a chirp", func(t *testing.T) {
+		chirp, err := client.GetChirp(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if chirp.ID != created.ID || chirp.Body != created.Body {
+			t.Fatalf("unexpected chirp: %+v", chirp)
+		}
+	})
+
+	t.Run("should list chirps", func(t *testing.T) {
+		var ids []uuid.UUID
+
+		for chirp, err := range client.Chirps(ctx, chirpyclient.ChirpsOptions{}) {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			ids = append(ids, chirp.ID)
+		}
+
+		if len(ids) != 1 || ids[0] != created.ID {
+			t.Fatalf("unexpected chirps: %v", ids)
+		}
+	})
+
+	t.Run("should delete a chirp", func(t *testing.T) {
+		if err := client.DeleteChirp(ctx, created.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, err := client.GetChirp(ctx, created.ID)
+		if !errors.Is(err, chirpyclient.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("should reject a chirp that is too long", func(t *testing.T) {
+		_, err := client.CreateChirp(ctx, chirpyclient.ChirpInput{
+			Body: strings.Repeat("a", 141),
+		})
+
+		var apiErr *chirpyclient.Error
+		if !errors.As(err, &apiErr) ||
+			apiErr.Code != chirpyclient.CodeValidationFailed ||
+			len(apiErr.Fields) != 1 || apiErr.Fields[0].Field != "body" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestPagination(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t, time.Hour)
+	client, user := loggedIn(t, srv, "jane@example.com")
+	ctx := context.Background()
+
+	const deleted = chirpyclient.PageSize + 50
+
+	now := time.Now().UTC()
+
+	for i := range deleted {
+		srv.store.addChirp(database.Chirp{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Body:      "gone",
+			UserID:    user.ID,
+			Status:    chirpyclient.ChirpStatusPublished,
+			DeletedAt: sql.NullTime{
+				Time:  now.Add(-time.Duration(i) * time.Second),
+				Valid: true,
+			},
+		})
+	}
+
+	t.Run("should fetch every page", func(t *testing.T) {
+		seen := map[uuid.UUID]bool{}
+
+		for chirp, err := range client.Trash(ctx) {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			seen[chirp.ID] = true
+		}
+
+		if len(seen) != deleted {
+			t.Fatalf("expected %d chirps, got %d", deleted, len(seen))
+		}
+
+		if calls := srv.calls("GET /api/trash"); calls != 2 {
+			t.Fatalf("expected 2 pages, got %d", calls)
+		}
+	})
+
+	t.Run("should stop when the caller stops", func(t *testing.T) {
+		before := srv.calls("GET /api/trash")
+
+		for range client.Trash(ctx) {
+			break
+		}
+
+		if calls := srv.calls("GET /api/trash") - before; calls != 1 {
+			t.Fatalf("expected 1 page, got %d", calls)
+		}
+	})
+
+	t.Run("should yield errors", func(t *testing.T) {
+		anonymous := chirpyclient.New(srv.URL)
+
+		for _, err := range anonymous.Trash(ctx) {
+			if !errors.Is(err, chirpyclient.ErrUnauthorized) {
+				t.Fatalf("expected ErrUnauthorized, got %v", err)
+			}
+		}
+	})
+}
+
+func TestWebhooks(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t, time.Hour)
+	client, user := loggedIn(t, srv, "jane@example.com")
+	ctx := context.Background()
+
+	event := chirpyclient.WebhookEvent{Event: chirpyclient.EventUserUpgraded}
+	event.Data.UserID = user.ID
+
+	t.Run("should reject the wrong key", func(t *testing.T) {
+		err := client.SendWebhook(ctx, "wrong key", event)
+		if !errors.Is(err, chirpyclient.ErrUnauthorized) {
+			t.Fatalf("expected ErrUnauthorized, got %v", err)
+		}
+	})
+
+	t.Run("should upgrade the user", func(t *testing.T) {
+		if err := client.SendWebhook(ctx, polkaKey, event); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		user, err := client.Login(ctx, "jane@example.com", password)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !user.IsChirpyRed {
+			t.Fatal("expected the user to have Chirpy Red")
+		}
+	})
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		header string
+		key    string
+		ok     bool
+	}{
+		{"should accept the key", chirpyclient.WebhookAuthorization("k"), "k", true},
+		{"should reject another key", chirpyclient.WebhookAuthorization("x"), "k", false},
+		{"should reject a missing header", "", "k", false},
+		{"should reject a bearer token", "Bearer k", "k", false},
+		{"should reject an unset key", chirpyclient.WebhookAuthorization(""), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			err := chirpyclient.VerifyWebhook(req, tt.key)
+			if (err == nil) != tt.ok {
+				t.Fatalf("unexpected result: %v", err)
+			}
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should derive a code from responses without problem details", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(
+			func(writer http.ResponseWriter, _ *http.Request) {
+				http.Error(writer, "upstream down", http.StatusBadGateway)
+			},
+		))
+		t.Cleanup(srv.Close)
+
+		_, err := chirpyclient.New(srv.URL).GetChirp(
+			context.Background(),
+			uuid.New(),
+		)
+
+		var apiErr *chirpyclient.Error
+		if !errors.As(err, &apiErr) || !errors.Is(err, chirpyclient.ErrInternal) {
+			t.Fatalf("expected ErrInternal, got %v", err)
+		}
+
+		if apiErr.Status != http.StatusBadGateway ||
+			apiErr.Detail != "upstream down" {
+			t.Fatalf("unexpected error: %+v", apiErr)
+		}
+	})
+
+	t.Run("should read when to retry a rate limited request", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.HandlerFunc(
+			func(writer http.ResponseWriter, _ *http.Request) {
+				writer.Header().Set("Retry-After", "30")
+				writer.Header().Set("Content-Type", "application/problem+json")
+				writer.WriteHeader(http.StatusTooManyRequests)
+				_, _ = writer.Write([]byte(`{"status":429,"code":"rate_limited"}`))
+			},
+		))
+		t.Cleanup(srv.Close)
+
+		_, err := chirpyclient.New(srv.URL).Login(
+			context.Background(),
+			"jane@example.com",
+			password,
+		)
+
+		var apiErr *chirpyclient.Error
+		if !errors.As(err, &apiErr) || !errors.Is(err, chirpyclient.ErrRateLimited) {
+			t.Fatalf("expected ErrRateLimited, got %v", err)
+		}
+
+		if apiErr.RetryAfter != 30*time.Second {
+			t.Fatalf("unexpected retry after: %v", apiErr.RetryAfter)
+		}
+	})
+
+	t.Run("should not match another code", func(t *testing.T) {
+		t.Parallel()
+
+		err := &chirpyclient.Error{Status: 404, Code: chirpyclient.CodeNotFound}
+
+		if errors.Is(err, chirpyclient.ErrForbidden) {
+			t.Fatal("did not expect ErrForbidden to match")
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package chirpyclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Code identifies a kind of API error. It is the `code` member of the
+// problem details the API answers errors with.
+type Code string
+
+const (
+	CodeBadRequest           Code = "bad_request"
+	CodeInvalidJSON          Code = "invalid_json"
+	CodeUnauthorized         Code = "unauthorized"
+	CodeInvalidCredentials   Code = "invalid_credentials"
+	CodeForbidden            Code = "forbidden"
+	CodeNotFound             Code = "not_found"
+	CodeMethodNotAllowed     Code = "method_not_allowed"
+	CodeConflict             Code = "conflict"
+	CodeAlreadyTaken         Code = "already_taken"
+	CodePollClosed           Code = "poll_closed"
+	CodeExportInProgress     Code = "export_in_progress"
+	CodeExportNotReady       Code = "export_not_ready"
+	CodePayloadTooLarge      Code = "payload_too_large"
+	CodeUnsupportedMediaType Code = "unsupported_media_type"
+	CodeValidationFailed     Code = "validation_failed"
+	CodeMessagesRefused      Code = "messages_refused"
+	CodeRateLimited          Code = "rate_limited"
+	CodeInternal             Code = "internal_error"
+)
+
+// Sentinel errors to compare API errors with errors.Is. An *Error matches
+// the sentinel of its code.
+var (
+	ErrBadRequest         = &Error{Code: CodeBadRequest}
+	ErrInvalidJSON        = &Error{Code: CodeInvalidJSON}
+	ErrUnauthorized       = &Error{Code: CodeUnauthorized}
+	ErrInvalidCredentials = &Error{Code: CodeInvalidCredentials}
+	ErrForbidden          = &Error{Code: CodeForbidden}
+	ErrNotFound           = &Error{Code: CodeNotFound}
+	ErrConflict           = &Error{Code: CodeConflict}
+	ErrAlreadyTaken       = &Error{Code: CodeAlreadyTaken}
+	ErrPollClosed         = &Error{Code: CodePollClosed}
+	ErrPayloadTooLarge    = &Error{Code: CodePayloadTooLarge}
+	ErrValidationFailed   = &Error{Code: CodeValidationFailed}
+	ErrRateLimited        = &Error{Code: CodeRateLimited}
+	ErrInternal           = &Error{Code: CodeInternal}
+)
+
+// Error is an error answered by the API.
+type Error struct {
+	// Status is the HTTP status code of the response.
+	Status int
+	Code   Code
+	Title  string
+	// Detail explains the error to humans. It may change; branch on Code.
+	Detail string
+	// Fields lists the rejected fields of a validation_failed error, and
+	// sometimes of an invalid_json one.
+	Fields []FieldError
+	// RequestID identifies the request in the server logs.
+	RequestID string
+	// RetryAfter is how long to wait before retrying a rate_limited
+	// request.
+	RetryAfter time.Duration
+}
+
+// FieldError explains why the value of one request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	var msg strings.Builder
+
+	fmt.Fprintf(&msg, "chirpy: %d %s", e.Status, e.Code)
+
+	if e.Detail != "" {
+		fmt.Fprintf(&msg, ": %s", e.Detail)
+	}
+
+	for _, field := range e.Fields {
+		fmt.Fprintf(&msg, "; %s %s", field.Field, field.Message)
+	}
+
+	return msg.String()
+}
+
+// Is reports whether target is the sentinel error of e's code.
+func (e *Error) Is(target error) bool {
+	sentinel, ok := target.(*Error)
+
+	return ok && sentinel.Status == 0 && sentinel.Code == e.Code
+}
+
+// readError turns an error response into an *Error. Responses that are not
+// problem details, such as those of a proxy in front of chirpy, get a code
+// derived from their status.
+func readError(res *http.Response) *Error {
+	var body struct {
+		Title     string       `json:"title"`
+		Detail    string       `json:"detail"`
+		Code      Code         `json:"code"`
+		Errors    []FieldError `json:"errors"`
+		RequestID string       `json:"request_id"`
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+
+	if err := json.Unmarshal(data, &body); err != nil || body.Code == "" {
+		body.Title = http.StatusText(res.StatusCode)
+		body.Detail = strings.TrimSpace(string(data))
+		body.Code = statusCode(res.StatusCode)
+	}
+
+	apiErr := &Error{
+		Status:    res.StatusCode,
+		Code:      body.Code,
+		Title:     body.Title,
+		Detail:    body.Detail,
+		Fields:    body.Errors,
+		RequestID: body.RequestID,
+	}
+
+	if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return apiErr
+}
+
+func statusCode(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return CodeValidationFailed
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	}
+
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+
+	return CodeBadRequest
+}
@@ -0,0 +1,37 @@
+package chirpyclient
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Notification struct {
+	ID         uuid.UUID   `json:"id"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+	Kind       string      `json:"kind"`
+	SubjectID  *uuid.UUID  `json:"subject_id"`
+	ActorIDs   []uuid.UUID `json:"actor_ids"`
+	ActorCount int64       `json:"actor_count"`
+	Summary    string      `json:"summary"`
+	Read       bool        `json:"read"`
+}
+
+// Notifications iterates over the logged-in user's notifications, newest
+// first, or only the unread ones when unreadOnly is set.
+func (c *Client) Notifications(
+	ctx context.Context,
+	unreadOnly bool,
+) iter.Seq2[Notification, error] {
+	query := url.Values{}
+
+	if unreadOnly {
+		query.Set("unread", "true")
+	}
+
+	return paginate[Notification](ctx, c, "/api/notifications", query)
+}
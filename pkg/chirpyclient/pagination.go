@@ -0,0 +1,62 @@
+package chirpyclient
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// PageSize is the number of items requested per page, the most the API
+// returns at once.
+const PageSize = 100
+
+// paginate iterates over every item of a limit/offset paginated endpoint,
+// fetching pages as the previous one is used up. It stops at the first
+// short page, or after yielding an error.
+func paginate[T any](
+	ctx context.Context,
+	c *Client,
+	path string,
+	query url.Values,
+) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		page := url.Values{}
+
+		for key, values := range query {
+			page[key] = values
+		}
+
+		page.Set("limit", strconv.Itoa(PageSize))
+
+		for offset := 0; ; offset += PageSize {
+			page.Set("offset", strconv.Itoa(offset))
+
+			var items []T
+
+			err := c.do(ctx, request{
+				method: http.MethodGet,
+				path:   path,
+				query:  page,
+			}, &items)
+			if err != nil {
+				var zero T
+
+				yield(zero, err)
+
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if len(items) < PageSize {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,488 @@
+package chirpyclient_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zyrterviews/chirpy/internal/appenv"
+	"github.com/zyrterviews/chirpy/internal/cli"
+	"github.com/zyrterviews/chirpy/internal/config"
+	"github.com/zyrterviews/chirpy/internal/database"
+	"github.com/zyrterviews/chirpy/internal/dm"
+	"github.com/zyrterviews/chirpy/internal/health"
+	"github.com/zyrterviews/chirpy/internal/metrics"
+	"github.com/zyrterviews/chirpy/internal/ratelimit"
+	"github.com/zyrterviews/chirpy/internal/tracing"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	jwtSecret = "test-jwt-secret"
+	polkaKey  = "test-polka-key"
+)
+
+// store is an in-memory stand-in for Postgres. It answers the sqlc queries
+// behind the endpoints the client tests call, by query name, with rows in
+// the column order sqlc scans them in. Other queries find no rows.
+type store struct {
+	mu     sync.Mutex
+	users  map[string]*database.User
+	tokens map[string]*database.RefreshToken
+	chirps []*database.Chirp
+}
+
+func newStore() *store {
+	return &store{
+		users:  map[string]*database.User{},
+		tokens: map[string]*database.RefreshToken{},
+	}
+}
+
+func userRow(user *database.User) []driver.Value {
+	return []driver.Value{
+		user.ID.String(),
+		user.CreatedAt,
+		user.UpdatedAt,
+		user.Email,
+		user.HashedPassword,
+		user.IsChirpyRed,
+		nil,
+		user.DisplayName,
+		user.Bio,
+		nil,
+		nil,
+		nil,
+		user.IsModerator,
+	}
+}
+
+func tokenRow(token *database.RefreshToken) []driver.Value {
+	var revokedAt driver.Value
+	if token.RevokedAt.Valid {
+		revokedAt = token.RevokedAt.Time
+	}
+
+	return []driver.Value{
+		token.Token,
+		token.CreatedAt,
+		token.UpdatedAt,
+		token.UserID.String(),
+		token.ExpiresAt,
+		revokedAt,
+	}
+}
+
+func chirpRow(chirp *database.Chirp) []driver.Value {
+	var publishAt, deletedAt, replyToID driver.Value
+
+	if chirp.PublishAt.Valid {
+		publishAt = chirp.PublishAt.Time
+	}
+
+	if chirp.DeletedAt.Valid {
+		deletedAt = chirp.DeletedAt.Time
+	}
+
+	if chirp.ReplyToID.Valid {
+		replyToID = chirp.ReplyToID.UUID.String()
+	}
+
+	return []driver.Value{
+		chirp.ID.String(),
+		chirp.CreatedAt,
+		chirp.UpdatedAt,
+		chirp.Body,
+		chirp.UserID.String(),
+		chirp.Status,
+		publishAt,
+		deletedAt,
+		replyToID,
+	}
+}
+
+func (s *store) userByID(id string) *database.User {
+	for _, user := range s.users {
+		if user.ID.String() == id {
+			return user
+		}
+	}
+
+	return nil
+}
+
+func (s *store) chirpByID(id string) *database.Chirp {
+	for _, chirp := range s.chirps {
+		if chirp.ID.String() == id {
+			return chirp
+		}
+	}
+
+	return nil
+}
+
+// addChirp stores a chirp directly, bypassing the API.
+func (s *store) addChirp(chirp database.Chirp) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chirps = append(s.chirps, &chirp)
+}
+
+// run executes the query called name and returns its rows and the number
+// of rows it affected.
+func (s *store) run(name string, args []any) ([][]driver.Value, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	switch name {
+	case "CreateUser":
+		email := args[0].(string)
+		if _, ok := s.users[email]; ok {
+			return nil, 0, &pq.Error{Code: "23505"}
+		}
+
+		user := &database.User{
+			ID:             uuid.New(),
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			Email:          email,
+			HashedPassword: args[1].(string),
+		}
+		s.users[email] = user
+
+		return [][]driver.Value{userRow(user)}, 1, nil
+	case "GetUserByEmail":
+		if user, ok := s.users[args[0].(string)]; ok {
+			return [][]driver.Value{userRow(user)}, 0, nil
+		}
+	case "GetUserByID":
+		if user := s.userByID(args[0].(string)); user != nil {
+			return [][]driver.Value{userRow(user)}, 0, nil
+		}
+	case "SetUserAsChirpyRed":
+		if user := s.userByID(args[0].(string)); user != nil {
+			user.IsChirpyRed = true
+
+			return [][]driver.Value{userRow(user)}, 1, nil
+		}
+	case "UpsertNotification":
+		return [][]driver.Value{{
+			uuid.NewString(),
+			now,
+			now,
+			args[0].(string),
+			args[1].(string),
+			args[2],
+			args[3].(string),
+			nil,
+		}}, 1, nil
+	case "CreateRefreshToken":
+		token := &database.RefreshToken{
+			Token:     args[0].(string),
+			CreatedAt: now,
+			UpdatedAt: now,
+			UserID:    uuid.MustParse(args[1].(string)),
+			ExpiresAt: args[2].(time.Time),
+		}
+		s.tokens[token.Token] = token
+
+		return [][]driver.Value{tokenRow(token)}, 1, nil
+	case "GetRefreshToken":
+		if token, ok := s.tokens[args[0].(string)]; ok {
+			return [][]driver.Value{tokenRow(token)}, 0, nil
+		}
+	case "GetUserFromRefreshToken":
+		if token, ok := s.tokens[args[0].(string)]; ok {
+			user := s.userByID(token.UserID.String())
+
+			return [][]driver.Value{
+				append(userRow(user), tokenRow(token)...),
+			}, 0, nil
+		}
+	case "RevokeRefreshToken":
+		if token, ok := s.tokens[args[0].(string)]; ok {
+			token.RevokedAt = sql.NullTime{Time: now, Valid: true}
+
+			return nil, 1, nil
+		}
+	case "CreateChirp":
+		chirp := &database.Chirp{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Body:      args[0].(string),
+			UserID:    uuid.MustParse(args[1].(string)),
+			Status:    args[2].(string),
+		}
+
+		if publishAt, ok := args[3].(time.Time); ok {
+			chirp.PublishAt = sql.NullTime{Time: publishAt, Valid: true}
+		}
+
+		s.chirps = append(s.chirps, chirp)
+
+		return [][]driver.Value{chirpRow(chirp)}, 1, nil
+	case "GetChirpByID":
+		if chirp := s.chirpByID(args[0].(string)); chirp != nil &&
+			!chirp.DeletedAt.Valid {
+			return [][]driver.Value{chirpRow(chirp)}, 0, nil
+		}
+	case "SoftDeleteChirp":
+		chirp := s.chirpByID(args[0].(string))
+		if chirp != nil && chirp.UserID.String() == args[1].(string) {
+			chirp.DeletedAt = sql.NullTime{Time: now, Valid: true}
+
+			return nil, 1, nil
+		}
+	case "GetAllChirps":
+		var rows [][]driver.Value
+
+		for _, chirp := range s.chirps {
+			if chirp.Status == "published" && !chirp.DeletedAt.Valid {
+				rows = append(rows, chirpRow(chirp))
+			}
+		}
+
+		return rows, 0, nil
+	case "GetDeletedChirpsForUser":
+		var deleted []*database.Chirp
+
+		for _, chirp := range s.chirps {
+			if chirp.UserID.String() == args[0].(string) &&
+				chirp.DeletedAt.Valid {
+				deleted = append(deleted, chirp)
+			}
+		}
+
+		sort.SliceStable(deleted, func(i, j int) bool {
+			return deleted[i].DeletedAt.Time.After(deleted[j].DeletedAt.Time)
+		})
+
+		limit, offset := args[2].(int64), args[3].(int64)
+		deleted = deleted[min(offset, int64(len(deleted))):]
+		deleted = deleted[:min(limit, int64(len(deleted)))]
+
+		rows := make([][]driver.Value, 0, len(deleted))
+		for _, chirp := range deleted {
+			rows = append(rows, chirpRow(chirp))
+		}
+
+		return rows, 0, nil
+	}
+
+	return nil, 0, nil
+}
+
+type connector struct {
+	store *store
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return conn(c), nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return nil
+}
+
+type conn struct {
+	store *store
+}
+
+func (c conn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c conn) Close() error {
+	return nil
+}
+
+func (c conn) Begin() (driver.Tx, error) {
+	return tx{}, nil
+}
+
+func (c conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return tx{}, nil
+}
+
+// CheckNamedValue passes arguments through, converting those with a driver
+// value, such as UUIDs, so that queries see plain Go values.
+func (c conn) CheckNamedValue(value *driver.NamedValue) error {
+	if valuer, ok := value.Value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return err
+		}
+
+		value.Value = v
+	}
+
+	switch v := value.Value.(type) {
+	case int32:
+		value.Value = int64(v)
+	case int:
+		value.Value = int64(v)
+	}
+
+	return nil
+}
+
+func (c conn) QueryContext(
+	_ context.Context,
+	query string,
+	args []driver.NamedValue,
+) (driver.Rows, error) {
+	rows, _, err := c.store.run(tracing.QueryName(query), values(args))
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{rows: rows}, nil
+}
+
+func (c conn) ExecContext(
+	_ context.Context,
+	query string,
+	args []driver.NamedValue,
+) (driver.Result, error) {
+	_, affected, err := c.store.run(tracing.QueryName(query), values(args))
+	if err != nil {
+		return nil, err
+	}
+
+	return driver.RowsAffected(affected), nil
+}
+
+func values(args []driver.NamedValue) []any {
+	values := make([]any, 0, len(args))
+
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+
+	return values
+}
+
+// tx does nothing: the store applies every query right away.
+type tx struct{}
+
+func (tx) Commit() error {
+	return nil
+}
+
+func (tx) Rollback() error {
+	return nil
+}
+
+type result struct {
+	rows [][]driver.Value
+}
+
+func (r *result) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+
+	columns := make([]string, len(r.rows[0]))
+	for i := range columns {
+		columns[i] = fmt.Sprintf("column%d", i)
+	}
+
+	return columns
+}
+
+func (r *result) Close() error {
+	return nil
+}
+
+func (r *result) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+
+	return nil
+}
+
+// server runs `chirpy serve`'s handler, with all its routes and
+// middleware, on top of a store.
+type server struct {
+	*httptest.Server
+	store *store
+
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+// calls returns how many requests were made to route, a pattern without
+// wildcards such as "POST /api/refresh".
+func (s *server) calls(route string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[route]
+}
+
+func newServer(t *testing.T, accessTokenTTL time.Duration) *server {
+	t.Helper()
+
+	data := newStore()
+	db := sql.OpenDB(connector{store: data})
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	cfg := config.Default()
+	cfg.JWTSecret = jwtSecret
+	cfg.PolkaKey = polkaKey
+	cfg.AccessTokenTTL = accessTokenTTL
+
+	env := &appenv.Env{
+		DB:      database.New(db),
+		SQL:     db,
+		Config:  cfg,
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Metrics: metrics.New(nil),
+		Tracing: noop.NewTracerProvider(),
+	}
+
+	handler := cli.Handler(
+		env,
+		dm.NewHub(),
+		&health.Checker{},
+		ratelimit.NewMemoryStore(),
+		nil,
+	)
+
+	srv := &server{store: data, requests: map[string]int{}}
+
+	srv.Server = httptest.NewServer(http.HandlerFunc(
+		func(writer http.ResponseWriter, req *http.Request) {
+			srv.mu.Lock()
+			srv.requests[req.Method+" "+req.URL.Path]++
+			srv.mu.Unlock()
+
+			handler.ServeHTTP(writer, req)
+		},
+	))
+
+	t.Cleanup(srv.Close)
+
+	return srv
+}
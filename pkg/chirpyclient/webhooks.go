@@ -0,0 +1,60 @@
+package chirpyclient
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// EventUserUpgraded is the webhook event that gives a user Chirpy Red.
+const EventUserUpgraded = "user.upgraded"
+
+var ErrInvalidWebhookKey = errors.New("chirpy: invalid webhook API key")
+
+// WebhookEvent is the payload of POST /api/polka/webhooks. Events other
+// than EventUserUpgraded are acknowledged and ignored.
+type WebhookEvent struct {
+	Event string `json:"event"`
+	Data  struct {
+		UserID uuid.UUID `json:"user_id"`
+	} `json:"data"`
+}
+
+// WebhookAuthorization returns the Authorization header that authenticates
+// a webhook with the shared key, chirpy's POLKA_KEY. Webhooks are not
+// signed: the key itself is sent, so they must only travel over TLS.
+func WebhookAuthorization(key string) string {
+	return "ApiKey " + key
+}
+
+// VerifyWebhook returns ErrInvalidWebhookKey unless req carries the
+// Authorization header of WebhookAuthorization(key), comparing keys in
+// constant time. It is meant for services that receive webhooks in the same
+// format as chirpy.
+func VerifyWebhook(req *http.Request, key string) error {
+	got, ok := strings.CutPrefix(req.Header.Get("Authorization"), "ApiKey ")
+	if !ok || key == "" ||
+		subtle.ConstantTimeCompare([]byte(got), []byte(key)) != 1 {
+		return ErrInvalidWebhookKey
+	}
+
+	return nil
+}
+
+// SendWebhook delivers event to chirpy, authenticated with key.
+func (c *Client) SendWebhook(
+	ctx context.Context,
+	key string,
+	event WebhookEvent,
+) error {
+	return c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/polka/webhooks",
+		body:   event,
+		auth:   WebhookAuthorization(key),
+	}, nil)
+}
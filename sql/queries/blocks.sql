@@ -0,0 +1,31 @@
+-- name: CreateBlock :exec
+INSERT INTO
+    blocks (blocker_id, blocked_id)
+VALUES
+    ($1, $2)
+ON CONFLICT (blocker_id, blocked_id) DO NOTHING;
+
+-- name: DeleteBlock :execrows
+DELETE FROM
+    blocks
+WHERE
+    blocker_id = $1
+    AND blocked_id = $2;
+
+-- name: IsBlockedBetween :one
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            blocks
+        WHERE
+            (
+                blocker_id = @user_id
+                AND blocked_id = @other_id
+            )
+            OR (
+                blocker_id = @other_id
+                AND blocked_id = @user_id
+            )
+    );
@@ -0,0 +1,30 @@
+-- name: CreateBookmark :exec
+INSERT INTO
+    bookmarks (user_id, chirp_id)
+VALUES
+    ($1, $2)
+ON CONFLICT (user_id, chirp_id) DO NOTHING;
+
+-- name: DeleteBookmark :execrows
+DELETE FROM
+    bookmarks
+WHERE
+    user_id = $1
+    AND chirp_id = $2;
+
+-- name: GetBookmarkedChirps :many
+SELECT
+    chirps.*
+FROM
+    bookmarks
+    INNER JOIN chirps ON chirps.id = bookmarks.chirp_id
+WHERE
+    bookmarks.user_id = @user_id
+    AND chirps.status = 'published'
+    AND chirps.deleted_at IS NULL
+ORDER BY
+    bookmarks.created_at DESC
+LIMIT
+    @row_limit
+OFFSET
+    @row_offset;
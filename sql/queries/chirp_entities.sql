@@ -0,0 +1,92 @@
+-- name: CreateChirpMention :exec
+INSERT INTO
+    chirp_mentions (chirp_id, user_id, start_offset, end_offset)
+VALUES
+    ($1, $2, $3, $4);
+
+-- name: CreateChirpHashtag :exec
+INSERT INTO
+    chirp_hashtags (chirp_id, tag, start_offset, end_offset)
+VALUES
+    ($1, $2, $3, $4);
+
+-- name: DeleteChirpMentions :exec
+DELETE FROM
+    chirp_mentions
+WHERE
+    chirp_id = $1;
+
+-- name: DeleteChirpHashtags :exec
+DELETE FROM
+    chirp_hashtags
+WHERE
+    chirp_id = $1;
+
+-- name: GetMentionsForChirps :many
+SELECT
+    chirp_mentions.*,
+    users.username
+FROM
+    chirp_mentions
+    INNER JOIN users ON users.id = chirp_mentions.user_id
+WHERE
+    chirp_mentions.chirp_id = ANY(@chirp_ids::UUID [])
+    AND users.deleted_at IS NULL
+ORDER BY
+    chirp_mentions.start_offset ASC;
+
+-- name: GetHashtagsForChirps :many
+SELECT
+    *
+FROM
+    chirp_hashtags
+WHERE
+    chirp_id = ANY(@chirp_ids::UUID [])
+ORDER BY
+    start_offset ASC;
+
+-- name: GetAllChirpsForHashtag :many
+SELECT
+    chirps.*
+FROM
+    chirps
+WHERE
+    EXISTS (
+        SELECT
+            1
+        FROM
+            chirp_hashtags
+        WHERE
+            chirp_hashtags.chirp_id = chirps.id
+            AND chirp_hashtags.tag = @tag
+    )
+    AND chirps.status = 'published'
+    AND chirps.deleted_at IS NULL
+ORDER BY
+    CASE
+        WHEN @sort::TEXT LIKE 'desc' THEN chirps.created_at
+    END DESC,
+    chirps.created_at ASC;
+
+-- name: GetAllChirpsMentioningUser :many
+SELECT
+    chirps.*
+FROM
+    chirps
+WHERE
+    EXISTS (
+        SELECT
+            1
+        FROM
+            chirp_mentions
+        WHERE
+            chirp_mentions.chirp_id = chirps.id
+            AND chirp_mentions.user_id = @user_id
+    )
+    AND chirps.status = 'published'
+    AND chirps.deleted_at IS NULL
+ORDER BY
+    CASE
+        WHEN @sort::TEXT LIKE 'desc' THEN chirps.created_at
+    END DESC,
+    chirps.created_at ASC;
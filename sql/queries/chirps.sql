@@ -1,8 +1,8 @@
 -- name: CreateChirp :one
 INSERT INTO
-    chirps (body, user_id)
+    chirps (body, user_id, status, publish_at, reply_to_id)
 VALUES
-    ($1, $2)
+    ($1, $2, $3, $4, $5)
 RETURNING
     *;
 
@@ -12,7 +12,8 @@ SELECT
 FROM
     chirps
 WHERE
-    id = $1;
+    id = $1
+    AND deleted_at IS NULL;
 
 -- name: GetAllChirpsForUser :many
 SELECT
@@ -21,6 +22,8 @@ FROM
     chirps
 WHERE
     user_id = $1
+    AND status = 'published'
+    AND deleted_at IS NULL
 ORDER BY
     CASE
         WHEN $2 LIKE 'asc' THEN created_at
@@ -38,6 +41,9 @@ SELECT
     *
 FROM
     chirps
+WHERE
+    status = 'published'
+    AND deleted_at IS NULL
 ORDER BY
     CASE
         WHEN $1 LIKE 'asc' THEN created_at
@@ -50,8 +56,177 @@ ORDER BY
         AND $1 NOT LIKE 'desc' THEN created_at
     END ASC;
 
--- name: DeleteChirpByID :exec
+-- name: SoftDeleteChirp :execrows
+UPDATE
+    chirps
+SET
+    deleted_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    id = $1
+    AND user_id = $2
+    AND deleted_at IS NULL;
+
+-- name: GetUnpublishedChirpsForUser :many
+SELECT
+    *
+FROM
+    chirps
+WHERE
+    user_id = $1
+    AND status <> 'published'
+    AND deleted_at IS NULL
+ORDER BY
+    publish_at ASC NULLS LAST,
+    created_at ASC;
+
+-- name: UpdateUnpublishedChirp :one
+UPDATE
+    chirps
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    body = @body,
+    status = @status,
+    publish_at = sqlc.narg(publish_at)
+WHERE
+    id = @id
+    AND user_id = @user_id
+    AND status <> 'published'
+    AND deleted_at IS NULL
+RETURNING
+    *;
+
+-- name: DeleteUnpublishedChirp :execrows
+UPDATE
+    chirps
+SET
+    deleted_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    id = $1
+    AND user_id = $2
+    AND status <> 'published'
+    AND deleted_at IS NULL;
+
+-- name: PublishChirp :one
+UPDATE
+    chirps
+SET
+    created_at = (NOW() AT TIME ZONE 'utc'),
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    status = 'published',
+    publish_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    id = $1
+    AND user_id = $2
+    AND status <> 'published'
+    AND deleted_at IS NULL
+RETURNING
+    *;
+
+-- name: PublishDueChirps :many
+UPDATE
+    chirps
+SET
+    created_at = (NOW() AT TIME ZONE 'utc'),
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    status = 'published',
+    publish_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    id IN (
+        SELECT
+            due.id
+        FROM
+            chirps AS due
+        WHERE
+            due.status = 'scheduled'
+            AND due.publish_at <= NOW()
+            AND due.deleted_at IS NULL
+        ORDER BY
+            due.publish_at ASC
+        LIMIT
+            $1
+        FOR UPDATE
+            SKIP LOCKED
+    )
+RETURNING
+    *;
+
+-- name: GetDeletedChirpsForUser :many
+SELECT
+    *
+FROM
+    chirps
+WHERE
+    user_id = @user_id
+    AND deleted_at >= @deleted_since::TIMESTAMPTZ
+ORDER BY
+    deleted_at DESC
+LIMIT
+    @row_limit
+OFFSET
+    @row_offset;
+
+-- name: RestoreChirp :one
+UPDATE
+    chirps
+SET
+    deleted_at = NULL
+WHERE
+    id = @id
+    AND user_id = @user_id
+    AND deleted_at >= @deleted_since::TIMESTAMPTZ
+RETURNING
+    *;
+
+-- name: SoftDeleteChirpsForUser :exec
+UPDATE
+    chirps
+SET
+    deleted_at = @deleted_at
+WHERE
+    user_id = @user_id
+    AND deleted_at IS NULL;
+
+-- name: RestoreChirpsForUser :exec
+UPDATE
+    chirps
+SET
+    deleted_at = NULL
+WHERE
+    user_id = @user_id
+    AND deleted_at = @deleted_at;
+
+-- name: PurgeDeletedChirps :execrows
 DELETE FROM
     chirps
+WHERE
+    deleted_at < @deleted_before::TIMESTAMPTZ;
+
+-- name: GetChirpByIDIncludingDeleted :one
+SELECT
+    *
+FROM
+    chirps
 WHERE
     id = $1;
+
+-- name: GetAllChirpsForUserIncludingDeleted :many
+SELECT
+    *
+FROM
+    chirps
+WHERE
+    user_id = @user_id
+ORDER BY
+    created_at DESC
+LIMIT
+    @row_limit
+OFFSET
+    @row_offset;
+
+-- name: ImportChirp :one
+INSERT INTO
+    chirps (body, user_id, created_at, updated_at)
+VALUES
+    (@body, @user_id, @created_at, @created_at)
+RETURNING
+    *;
@@ -0,0 +1,101 @@
+-- name: CreateDataExport :one
+INSERT INTO
+    data_exports (user_id, token_hash, expires_at)
+VALUES
+    ($1, $2, $3)
+RETURNING
+    *;
+
+-- name: ClaimPendingDataExport :one
+UPDATE
+    data_exports
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    claimed_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    id = (
+        SELECT
+            id
+        FROM
+            data_exports
+        WHERE
+            status = 'pending'
+            AND (
+                claimed_at IS NULL
+                OR claimed_at < @claimed_before
+            )
+        ORDER BY
+            created_at ASC
+        LIMIT
+            1
+        FOR UPDATE
+            SKIP LOCKED
+    )
+RETURNING
+    *;
+
+-- name: MarkDataExportReady :exec
+UPDATE
+    data_exports
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    status = 'ready',
+    blob_key = @blob_key
+WHERE
+    id = @id;
+
+-- name: MarkDataExportFailed :exec
+UPDATE
+    data_exports
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    status = 'failed'
+WHERE
+    id = $1;
+
+-- name: GetDataExportByTokenHash :one
+SELECT
+    *
+FROM
+    data_exports
+WHERE
+    token_hash = $1;
+
+-- name: ClaimDataExportDownload :one
+UPDATE
+    data_exports
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    downloaded_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    token_hash = $1
+    AND status = 'ready'
+    AND downloaded_at IS NULL
+    AND expires_at > NOW()
+RETURNING
+    *;
+
+-- name: ReleaseDataExportDownload :exec
+UPDATE
+    data_exports
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    downloaded_at = NULL
+WHERE
+    id = $1;
+
+-- name: DeleteStaleDataExports :many
+DELETE FROM
+    data_exports
+WHERE
+    (
+        expires_at <= NOW()
+        OR downloaded_at IS NOT NULL
+    )
+    -- An archive may still be streaming to whoever claimed it.
+    AND (
+        downloaded_at IS NULL
+        OR downloaded_at <= @downloaded_before
+    )
+RETURNING
+    *;
@@ -0,0 +1,187 @@
+-- name: CreateConversation :one
+INSERT INTO
+    conversations (direct_key)
+VALUES
+    ($1)
+RETURNING
+    *;
+
+-- name: GetConversationByDirectKey :one
+SELECT
+    *
+FROM
+    conversations
+WHERE
+    direct_key = $1;
+
+-- name: AddConversationMember :exec
+INSERT INTO
+    conversation_members (conversation_id, user_id)
+VALUES
+    ($1, $2);
+
+-- name: GetConversationForMember :one
+SELECT
+    conversations.*
+FROM
+    conversations
+    INNER JOIN conversation_members ON conversation_members.conversation_id = conversations.id
+WHERE
+    conversations.id = @id
+    AND conversation_members.user_id = @user_id;
+
+-- name: ListConversationsForUser :many
+SELECT
+    conversations.*,
+    (
+        SELECT
+            COUNT(*)
+        FROM
+            direct_messages
+        WHERE
+            direct_messages.conversation_id = conversations.id
+            AND direct_messages.sender_id <> @user_id
+            AND (
+                conversation_members.last_read_at IS NULL
+                OR direct_messages.created_at > conversation_members.last_read_at
+            )
+    ) AS unread_count
+FROM
+    conversations
+    INNER JOIN conversation_members ON conversation_members.conversation_id = conversations.id
+WHERE
+    conversation_members.user_id = @user_id
+ORDER BY
+    conversations.updated_at DESC
+LIMIT
+    @row_limit
+OFFSET
+    @row_offset;
+
+-- name: GetConversationMembers :many
+SELECT
+    *
+FROM
+    conversation_members
+WHERE
+    conversation_id = ANY(@conversation_ids::UUID [])
+ORDER BY
+    created_at ASC;
+
+-- name: CreateDirectMessage :one
+INSERT INTO
+    direct_messages (conversation_id, sender_id, body)
+VALUES
+    ($1, $2, $3)
+RETURNING
+    *;
+
+-- name: TouchConversation :exec
+UPDATE
+    conversations
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    id = $1;
+
+-- name: GetDirectMessages :many
+SELECT
+    *
+FROM
+    direct_messages
+WHERE
+    conversation_id = @conversation_id
+ORDER BY
+    seq DESC
+LIMIT
+    @row_limit
+OFFSET
+    @row_offset;
+
+-- name: GetDirectMessageFence :one
+SELECT
+    pg_snapshot_xmin(pg_current_snapshot())::TEXT::BIGINT AS fence;
+
+-- name: GetDirectMessagesAfter :many
+SELECT
+    direct_messages.*
+FROM
+    direct_messages
+    INNER JOIN conversation_members ON conversation_members.conversation_id = direct_messages.conversation_id
+WHERE
+    conversation_members.user_id = @user_id
+    AND direct_messages.xid >= @from_xid
+    AND direct_messages.xid < @fence
+ORDER BY
+    direct_messages.xid ASC,
+    direct_messages.seq ASC
+LIMIT
+    @row_limit;
+
+-- name: MarkConversationRead :execrows
+UPDATE
+    conversation_members
+SET
+    last_read_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    conversation_id = $1
+    AND user_id = $2;
+
+-- name: GetDMSettings :one
+SELECT
+    *
+FROM
+    dm_settings
+WHERE
+    user_id = $1;
+
+-- name: UpsertDMSettings :one
+INSERT INTO
+    dm_settings (user_id, followed_only)
+VALUES
+    ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET
+    followed_only = EXCLUDED.followed_only,
+    updated_at = (NOW() AT TIME ZONE 'utc')
+RETURNING
+    *;
+
+-- name: GetRefusingRecipients :many
+SELECT
+    conversation_members.user_id
+FROM
+    conversation_members
+    LEFT JOIN dm_settings ON dm_settings.user_id = conversation_members.user_id
+WHERE
+    conversation_members.conversation_id = @conversation_id
+    AND conversation_members.user_id <> @sender_id
+    AND (
+        EXISTS (
+            SELECT
+                1
+            FROM
+                blocks
+            WHERE
+                (
+                    blocks.blocker_id = conversation_members.user_id
+                    AND blocks.blocked_id = @sender_id
+                )
+                OR (
+                    blocks.blocker_id = @sender_id
+                    AND blocks.blocked_id = conversation_members.user_id
+                )
+        )
+        OR (
+            COALESCE(dm_settings.followed_only, FALSE)
+            AND NOT EXISTS (
+                SELECT
+                    1
+                FROM
+                    follows
+                WHERE
+                    follows.follower_id = conversation_members.user_id
+                    AND follows.followee_id = @sender_id
+            )
+        )
+    );
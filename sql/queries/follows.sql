@@ -0,0 +1,49 @@
+-- name: CreateFollow :execrows
+INSERT INTO
+    follows (follower_id, followee_id)
+VALUES
+    ($1, $2)
+ON CONFLICT (follower_id, followee_id) DO NOTHING;
+
+-- name: DeleteFollow :execrows
+DELETE FROM
+    follows
+WHERE
+    follower_id = $1
+    AND followee_id = $2;
+
+-- name: GetFollowCounts :one
+SELECT
+    (
+        SELECT
+            COUNT(*)
+        FROM
+            follows
+            INNER JOIN users ON users.id = follows.follower_id
+        WHERE
+            follows.followee_id = @user_id
+            AND users.deleted_at IS NULL
+    ) AS follower_count,
+    (
+        SELECT
+            COUNT(*)
+        FROM
+            follows
+            INNER JOIN users ON users.id = follows.followee_id
+        WHERE
+            follows.follower_id = @user_id
+            AND users.deleted_at IS NULL
+    ) AS following_count;
+
+-- name: DeleteFollowsBetween :exec
+DELETE FROM
+    follows
+WHERE
+    (
+        follower_id = @user_id
+        AND followee_id = @other_id
+    )
+    OR (
+        follower_id = @other_id
+        AND followee_id = @user_id
+    );
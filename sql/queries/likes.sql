@@ -0,0 +1,26 @@
+-- name: CreateLike :execrows
+INSERT INTO
+    likes (user_id, chirp_id)
+VALUES
+    ($1, $2)
+ON CONFLICT (user_id, chirp_id) DO NOTHING;
+
+-- name: DeleteLike :execrows
+DELETE FROM
+    likes
+WHERE
+    user_id = $1
+    AND chirp_id = $2;
+
+-- name: CountLikesForChirps :many
+SELECT
+    likes.chirp_id,
+    COUNT(*) AS like_count
+FROM
+    likes
+    INNER JOIN users ON users.id = likes.user_id
+WHERE
+    likes.chirp_id = ANY(@chirp_ids::UUID [])
+    AND users.deleted_at IS NULL
+GROUP BY
+    likes.chirp_id;
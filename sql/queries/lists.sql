@@ -0,0 +1,100 @@
+-- name: CreateList :one
+INSERT INTO
+    lists (user_id, name, is_public)
+VALUES
+    ($1, $2, $3)
+RETURNING
+    *;
+
+-- name: GetListByID :one
+SELECT
+    lists.*
+FROM
+    lists
+    INNER JOIN users ON users.id = lists.user_id
+WHERE
+    lists.id = $1
+    AND users.deleted_at IS NULL;
+
+-- name: GetListsForUser :many
+SELECT
+    *
+FROM
+    lists
+WHERE
+    user_id = $1
+ORDER BY
+    created_at ASC;
+
+-- name: UpdateList :one
+UPDATE
+    lists
+SET
+    name = @name,
+    is_public = @is_public,
+    updated_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    id = @id
+    AND user_id = @user_id
+RETURNING
+    *;
+
+-- name: DeleteList :execrows
+DELETE FROM
+    lists
+WHERE
+    id = $1
+    AND user_id = $2;
+
+-- name: AddListMember :exec
+INSERT INTO
+    list_members (list_id, user_id)
+VALUES
+    ($1, $2)
+ON CONFLICT (list_id, user_id) DO NOTHING;
+
+-- name: RemoveListMember :execrows
+DELETE FROM
+    list_members
+WHERE
+    list_id = $1
+    AND user_id = $2;
+
+-- name: GetListMemberIDs :many
+SELECT
+    list_members.user_id
+FROM
+    list_members
+    INNER JOIN users ON users.id = list_members.user_id
+WHERE
+    list_members.list_id = $1
+    AND users.deleted_at IS NULL
+ORDER BY
+    list_members.created_at ASC;
+
+-- name: GetAllChirpsForList :many
+SELECT
+    chirps.*
+FROM
+    chirps
+WHERE
+    EXISTS (
+        SELECT
+            1
+        FROM
+            list_members
+        WHERE
+            list_members.user_id = chirps.user_id
+            AND list_members.list_id = @list_id
+    )
+    AND chirps.status = 'published'
+    AND chirps.deleted_at IS NULL
+ORDER BY
+    CASE
+        WHEN @sort::TEXT LIKE 'asc' THEN chirps.created_at
+    END ASC,
+    chirps.created_at DESC
+LIMIT
+    @row_limit
+OFFSET
+    @row_offset;
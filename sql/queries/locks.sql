@@ -0,0 +1,3 @@
+-- name: TryAdvisoryXactLock :one
+SELECT
+    pg_try_advisory_xact_lock(hashtext(@name::TEXT));
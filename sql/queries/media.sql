@@ -0,0 +1,82 @@
+-- name: CreateMedia :one
+INSERT INTO
+    media (
+        id,
+        user_id,
+        content_type,
+        size_bytes,
+        width,
+        height,
+        blob_key,
+        thumbnail_key
+    )
+VALUES
+    ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING
+    *;
+
+-- name: GetMediaByIDsForUser :many
+SELECT
+    *
+FROM
+    media
+WHERE
+    id = ANY(@ids::UUID [])
+    AND user_id = @user_id;
+
+-- name: AttachMediaToChirp :exec
+INSERT INTO
+    chirp_media (chirp_id, media_id, position)
+VALUES
+    ($1, $2, $3);
+
+-- name: GetMediaForChirps :many
+SELECT
+    chirp_media.chirp_id,
+    chirp_media.position,
+    media.*
+FROM
+    chirp_media
+    INNER JOIN media ON media.id = chirp_media.media_id
+WHERE
+    chirp_media.chirp_id = ANY(@chirp_ids::UUID [])
+ORDER BY
+    chirp_media.position ASC;
+
+-- name: GetMediaForUser :many
+SELECT
+    *
+FROM
+    media
+WHERE
+    user_id = $1;
+
+-- name: GetServableMediaByID :one
+SELECT
+    *
+FROM
+    media
+WHERE
+    id = $1
+    -- Media attached to chirps go away with them; media no chirp uses yet,
+    -- such as avatars and fresh uploads, stay available.
+    AND (
+        NOT EXISTS (
+            SELECT
+                1
+            FROM
+                chirp_media
+            WHERE
+                chirp_media.media_id = media.id
+        )
+        OR EXISTS (
+            SELECT
+                1
+            FROM
+                chirp_media
+                INNER JOIN chirps ON chirps.id = chirp_media.chirp_id
+            WHERE
+                chirp_media.media_id = media.id
+                AND chirps.deleted_at IS NULL
+        )
+    );
@@ -0,0 +1,81 @@
+-- name: UpsertNotification :one
+INSERT INTO
+    notifications (user_id, kind, subject_id, group_key)
+VALUES
+    ($1, $2, $3, $4)
+ON CONFLICT (user_id, group_key)
+WHERE
+    read_at IS NULL DO
+UPDATE
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc')
+RETURNING
+    *;
+
+-- name: AddNotificationActor :exec
+INSERT INTO
+    notification_actors (notification_id, actor_id)
+VALUES
+    ($1, $2)
+ON CONFLICT (notification_id, actor_id) DO
+UPDATE
+SET
+    created_at = (NOW() AT TIME ZONE 'utc');
+
+-- name: ListNotificationsForUser :many
+SELECT
+    notifications.*,
+    COUNT(notification_actors.actor_id) AS actor_count,
+    COALESCE(
+        ARRAY_AGG(
+            notification_actors.actor_id
+            ORDER BY
+                notification_actors.created_at DESC
+        ) FILTER (
+            WHERE
+                notification_actors.actor_id IS NOT NULL
+        ),
+        '{}'
+    )::UUID [] AS actor_ids
+FROM
+    notifications
+    LEFT JOIN notification_actors ON notifications.id = notification_actors.notification_id
+WHERE
+    notifications.user_id = @user_id
+    AND (
+        NOT @unread_only::BOOLEAN
+        OR notifications.read_at IS NULL
+    )
+GROUP BY
+    notifications.id
+ORDER BY
+    notifications.updated_at DESC
+LIMIT
+    @row_limit OFFSET @row_offset;
+
+-- name: GetUnreadNotificationCount :one
+SELECT
+    COUNT(*)
+FROM
+    notifications
+WHERE
+    user_id = $1
+    AND read_at IS NULL;
+
+-- name: MarkNotificationRead :execrows
+UPDATE
+    notifications
+SET
+    read_at = COALESCE(read_at, (NOW() AT TIME ZONE 'utc'))
+WHERE
+    id = $1
+    AND user_id = $2;
+
+-- name: MarkAllNotificationsRead :exec
+UPDATE
+    notifications
+SET
+    read_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    user_id = $1
+    AND read_at IS NULL;
@@ -0,0 +1,74 @@
+-- name: CreatePoll :one
+INSERT INTO
+    polls (chirp_id, closes_at)
+VALUES
+    ($1, $2)
+RETURNING
+    *;
+
+-- name: CreatePollOption :one
+INSERT INTO
+    poll_options (poll_id, position, label)
+VALUES
+    ($1, $2, $3)
+RETURNING
+    *;
+
+-- name: GetPollByChirpID :one
+SELECT
+    *
+FROM
+    polls
+WHERE
+    chirp_id = $1;
+
+-- name: GetPollsForChirps :many
+SELECT
+    *
+FROM
+    polls
+WHERE
+    chirp_id = ANY(@chirp_ids::UUID []);
+
+-- name: GetPollOptionsWithVotes :many
+SELECT
+    poll_options.*,
+    COUNT(poll_votes.user_id) AS votes
+FROM
+    poll_options
+    LEFT JOIN poll_votes ON poll_votes.option_id = poll_options.id
+WHERE
+    poll_options.poll_id = ANY(@poll_ids::UUID [])
+GROUP BY
+    poll_options.id
+ORDER BY
+    poll_options.position ASC;
+
+-- name: GetPollVotesForUser :many
+SELECT
+    *
+FROM
+    poll_votes
+WHERE
+    user_id = @user_id
+    AND poll_id = ANY(@poll_ids::UUID []);
+
+-- name: CastPollVote :execrows
+INSERT INTO
+    poll_votes (poll_id, user_id, option_id)
+SELECT
+    polls.id,
+    @user_id::UUID,
+    poll_options.id
+FROM
+    polls
+    INNER JOIN poll_options ON poll_options.poll_id = polls.id
+WHERE
+    polls.id = @poll_id
+    AND poll_options.id = @option_id
+    AND polls.closes_at > NOW()
+ON CONFLICT (poll_id, user_id) DO
+UPDATE
+SET
+    option_id = EXCLUDED.option_id,
+    updated_at = (NOW() AT TIME ZONE 'utc');
@@ -0,0 +1,25 @@
+-- name: LockRateLimitBucket :one
+INSERT INTO
+    rate_limit_buckets (key, tokens, updated_at)
+VALUES
+    (@key, @tokens, @updated_at)
+ON CONFLICT (key) DO UPDATE
+SET
+    key = EXCLUDED.key
+RETURNING
+    *;
+
+-- name: UpdateRateLimitBucket :exec
+UPDATE
+    rate_limit_buckets
+SET
+    tokens = @tokens,
+    updated_at = @updated_at
+WHERE
+    key = @key;
+
+-- name: DeleteIdleRateLimitBuckets :execrows
+DELETE FROM
+    rate_limit_buckets
+WHERE
+    updated_at < @updated_before::TIMESTAMPTZ;
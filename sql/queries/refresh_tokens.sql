@@ -21,7 +21,8 @@ FROM
     users
     INNER JOIN refresh_tokens ON users.id = refresh_tokens.user_id
 WHERE
-    refresh_tokens.token = $1;
+    refresh_tokens.token = $1
+    AND users.deleted_at IS NULL;
 
 -- name: RevokeRefreshToken :exec
 UPDATE
@@ -31,3 +32,23 @@ SET
     revoked_at = (NOW() AT TIME ZONE 'utc')
 WHERE
     token = $1;
+
+-- name: RevokeRefreshTokensForUser :exec
+UPDATE
+    refresh_tokens
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    revoked_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    user_id = $1
+    AND revoked_at IS NULL;
+
+-- name: GetRefreshTokensForUser :many
+SELECT
+    *
+FROM
+    refresh_tokens
+WHERE
+    user_id = $1
+ORDER BY
+    created_at ASC;
@@ -0,0 +1,15 @@
+-- name: CreateSubscriptionEvent :exec
+INSERT INTO
+    subscription_events (user_id, event)
+VALUES
+    ($1, $2);
+
+-- name: GetSubscriptionEventsForUser :many
+SELECT
+    *
+FROM
+    subscription_events
+WHERE
+    user_id = $1
+ORDER BY
+    created_at ASC;
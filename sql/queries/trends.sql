@@ -0,0 +1,57 @@
+-- name: DeleteTrendsForWindow :exec
+DELETE FROM
+    trends
+WHERE
+    window_name = $1;
+
+-- name: GetHashtagUsageByAuthor :many
+SELECT
+    chirp_hashtags.tag,
+    COUNT(*) FILTER (
+        WHERE
+            chirps.created_at >= @recent_since::TIMESTAMPTZ
+    ) AS recent,
+    COUNT(*) FILTER (
+        WHERE
+            chirps.created_at < @recent_since::TIMESTAMPTZ
+    ) AS baseline
+FROM
+    chirp_hashtags
+    INNER JOIN chirps ON chirps.id = chirp_hashtags.chirp_id
+WHERE
+    chirps.created_at >= @baseline_since::TIMESTAMPTZ
+    AND chirps.status = 'published'
+    AND chirps.deleted_at IS NULL
+GROUP BY
+    chirp_hashtags.tag,
+    chirps.user_id;
+
+-- name: CreateTrends :exec
+INSERT INTO
+    trends (
+        window_name,
+        tag,
+        recent_count,
+        baseline_count,
+        score
+    )
+SELECT
+    @window_name::TEXT,
+    UNNEST(@tags::TEXT []),
+    UNNEST(@recent_counts::BIGINT []),
+    UNNEST(@baseline_counts::DOUBLE PRECISION []),
+    UNNEST(@scores::DOUBLE PRECISION []);
+
+-- name: ListTrends :many
+SELECT
+    *
+FROM
+    trends
+WHERE
+    window_name = $1
+ORDER BY
+    score DESC,
+    recent_count DESC,
+    tag ASC
+LIMIT
+    $2;
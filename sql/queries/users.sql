@@ -12,7 +12,8 @@ SELECT
 FROM
     users
 WHERE
-    email = $1;
+    email = $1
+    AND deleted_at IS NULL;
 
 -- name: GetUserByID :one
 SELECT
@@ -20,17 +21,20 @@ SELECT
 FROM
     users
 WHERE
-    id = $1;
+    id = $1
+    AND deleted_at IS NULL;
 
 -- name: UpdateUser :one
 UPDATE
     users
 SET
     updated_at = (NOW() AT TIME ZONE 'utc'),
-    email = $1,
-    hashed_password = $2
+    email = @email,
+    hashed_password = @hashed_password,
+    username = COALESCE(sqlc.narg(username), username)
 WHERE
-    id = $3
+    id = @id
+    AND deleted_at IS NULL
 RETURNING
     *;
 
@@ -46,5 +50,136 @@ SET
     is_chirpy_red = TRUE
 WHERE
     id = $1
+    AND deleted_at IS NULL
+RETURNING
+    *;
+
+-- name: GetUsersByUsernames :many
+SELECT
+    *
+FROM
+    users
+WHERE
+    username = ANY(@usernames::TEXT [])
+    AND deleted_at IS NULL;
+
+-- name: GetUserByUsername :one
+SELECT
+    *
+FROM
+    users
+WHERE
+    username = $1
+    AND deleted_at IS NULL;
+
+-- name: UpdateUserProfile :one
+UPDATE
+    users
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    display_name = @display_name,
+    bio = @bio,
+    avatar_media_id = @avatar_media_id
+WHERE
+    id = @id
+RETURNING
+    *;
+
+-- name: SetPinnedChirp :one
+UPDATE
+    users
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    pinned_chirp_id = @pinned_chirp_id
+WHERE
+    id = @id
+RETURNING
+    *;
+
+-- name: CountPublishedChirpsForUser :one
+SELECT
+    COUNT(*)
+FROM
+    chirps
+WHERE
+    user_id = $1
+    AND status = 'published'
+    AND deleted_at IS NULL;
+
+-- name: SoftDeleteUser :one
+UPDATE
+    users
+SET
+    deleted_at = (NOW() AT TIME ZONE 'utc')
+WHERE
+    id = $1
+    AND deleted_at IS NULL
 RETURNING
     *;
+
+-- name: GetDeletedUserByEmail :one
+SELECT
+    *
+FROM
+    users
+WHERE
+    email = @email
+    AND deleted_at >= @deleted_since::TIMESTAMPTZ;
+
+-- name: RestoreUser :one
+UPDATE
+    users
+SET
+    deleted_at = NULL
+WHERE
+    id = $1
+RETURNING
+    *;
+
+-- name: GetPurgeableUsers :many
+SELECT
+    *
+FROM
+    users
+WHERE
+    deleted_at < @deleted_before::TIMESTAMPTZ
+LIMIT
+    @row_limit;
+
+-- name: DeleteUserByID :exec
+DELETE FROM
+    users
+WHERE
+    id = $1;
+
+-- name: ListUsers :many
+SELECT
+    *
+FROM
+    users
+WHERE
+    deleted_at IS NULL
+ORDER BY
+    created_at ASC
+LIMIT
+    @row_limit OFFSET @row_offset;
+
+-- name: SetUserModerator :one
+UPDATE
+    users
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    is_moderator = @is_moderator
+WHERE
+    id = @id
+RETURNING
+    *;
+
+-- name: UpdateUserPassword :exec
+UPDATE
+    users
+SET
+    updated_at = (NOW() AT TIME ZONE 'utc'),
+    hashed_password = @hashed_password
+WHERE
+    id = @id;
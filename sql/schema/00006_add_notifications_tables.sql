@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    user_id UUID NOT NULL,
+    kind TEXT NOT NULL,
+    subject_id UUID,
+    group_key TEXT NOT NULL,
+    read_at TIMESTAMPTZ,
+    CONSTRAINT fk__notifications__user_id__users__id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Only one unread notification per group, so that similar events collapse
+-- into it until the user reads it.
+CREATE UNIQUE INDEX uq__notifications__user_id__group_key__unread ON notifications (user_id, group_key)
+WHERE
+    read_at IS NULL;
+
+CREATE INDEX idx__notifications__user_id__updated_at ON notifications (user_id, updated_at DESC);
+
+CREATE TABLE notification_actors (
+    notification_id UUID NOT NULL,
+    actor_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    PRIMARY KEY (notification_id, actor_id),
+    CONSTRAINT fk__notification_actors__notification_id__notifications__id FOREIGN KEY (notification_id) REFERENCES notifications(id) ON DELETE CASCADE,
+    CONSTRAINT fk__notification_actors__actor_id__users__id FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE notification_actors;
+
+DROP TABLE notifications;
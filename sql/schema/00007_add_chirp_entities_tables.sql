@@ -0,0 +1,32 @@
+-- +goose Up
+ALTER TABLE users ADD username TEXT UNIQUE;
+
+CREATE TABLE chirp_mentions (
+    chirp_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    start_offset INTEGER NOT NULL,
+    end_offset INTEGER NOT NULL,
+    PRIMARY KEY (chirp_id, start_offset),
+    CONSTRAINT fk__chirp_mentions__chirp_id__chirps__id FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+    CONSTRAINT fk__chirp_mentions__user_id__users__id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx__chirp_mentions__user_id ON chirp_mentions (user_id);
+
+CREATE TABLE chirp_hashtags (
+    chirp_id UUID NOT NULL,
+    tag TEXT NOT NULL,
+    start_offset INTEGER NOT NULL,
+    end_offset INTEGER NOT NULL,
+    PRIMARY KEY (chirp_id, start_offset),
+    CONSTRAINT fk__chirp_hashtags__chirp_id__chirps__id FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx__chirp_hashtags__tag ON chirp_hashtags (tag);
+
+-- +goose Down
+DROP TABLE chirp_hashtags;
+
+DROP TABLE chirp_mentions;
+
+ALTER TABLE users DROP COLUMN username;
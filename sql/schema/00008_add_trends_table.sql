@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE trends (
+    window_name TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    recent_count BIGINT NOT NULL,
+    baseline_count DOUBLE PRECISION NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    PRIMARY KEY (window_name, tag)
+);
+
+CREATE INDEX idx__chirps__created_at ON chirps (created_at);
+
+-- +goose Down
+DROP INDEX idx__chirps__created_at;
+
+DROP TABLE trends;
@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE media (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    user_id UUID NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    blob_key TEXT NOT NULL,
+    thumbnail_key TEXT NOT NULL,
+    CONSTRAINT fk__media__user_id__users__id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE chirp_media (
+    chirp_id UUID NOT NULL,
+    media_id UUID NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (chirp_id, media_id),
+    CONSTRAINT uq__chirp_media__chirp_id__position UNIQUE (chirp_id, position),
+    CONSTRAINT ck__chirp_media__position CHECK (
+        position >= 0
+        AND position < 4
+    ),
+    CONSTRAINT fk__chirp_media__chirp_id__chirps__id FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+    CONSTRAINT fk__chirp_media__media_id__media__id FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE chirp_media;
+
+DROP TABLE media;
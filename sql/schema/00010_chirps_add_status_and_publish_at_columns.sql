@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD status TEXT NOT NULL DEFAULT 'published',
+ADD publish_at TIMESTAMPTZ,
+ADD CONSTRAINT ck__chirps__status CHECK (status IN ('published', 'scheduled', 'draft')),
+ADD CONSTRAINT ck__chirps__publish_at CHECK (
+    status <> 'scheduled'
+    OR publish_at IS NOT NULL
+);
+
+CREATE INDEX idx__chirps__publish_at__scheduled ON chirps (publish_at)
+WHERE
+    status = 'scheduled';
+
+-- +goose Down
+DROP INDEX idx__chirps__publish_at__scheduled;
+
+ALTER TABLE chirps
+DROP CONSTRAINT ck__chirps__publish_at,
+DROP CONSTRAINT ck__chirps__status,
+DROP COLUMN publish_at,
+DROP COLUMN status;
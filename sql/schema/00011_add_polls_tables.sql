@@ -0,0 +1,40 @@
+-- +goose Up
+CREATE TABLE polls (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    chirp_id UUID NOT NULL UNIQUE,
+    closes_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT fk__polls__chirp_id__chirps__id FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE
+);
+
+CREATE TABLE poll_options (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    poll_id UUID NOT NULL,
+    position INTEGER NOT NULL,
+    label TEXT NOT NULL,
+    CONSTRAINT uq__poll_options__poll_id__position UNIQUE (poll_id, position),
+    CONSTRAINT fk__poll_options__poll_id__polls__id FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE
+);
+
+-- One row per voter: changing a vote updates the row, so totals are always
+-- an exact COUNT(*) and concurrent votes cannot double count.
+CREATE TABLE poll_votes (
+    poll_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    option_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    PRIMARY KEY (poll_id, user_id),
+    CONSTRAINT fk__poll_votes__poll_id__polls__id FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE,
+    CONSTRAINT fk__poll_votes__user_id__users__id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk__poll_votes__option_id__poll_options__id FOREIGN KEY (option_id) REFERENCES poll_options(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx__poll_votes__option_id ON poll_votes (option_id);
+
+-- +goose Down
+DROP TABLE poll_votes;
+
+DROP TABLE poll_options;
+
+DROP TABLE polls;
@@ -0,0 +1,39 @@
+-- +goose Up
+CREATE TABLE bookmarks (
+    user_id UUID NOT NULL,
+    chirp_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    PRIMARY KEY (user_id, chirp_id),
+    CONSTRAINT fk__bookmarks__user_id__users__id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk__bookmarks__chirp_id__chirps__id FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx__bookmarks__user_id__created_at ON bookmarks (user_id, created_at DESC);
+
+CREATE TABLE lists (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    user_id UUID NOT NULL,
+    name TEXT NOT NULL,
+    is_public BOOLEAN NOT NULL DEFAULT FALSE,
+    CONSTRAINT fk__lists__user_id__users__id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx__lists__user_id ON lists (user_id);
+
+CREATE TABLE list_members (
+    list_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    PRIMARY KEY (list_id, user_id),
+    CONSTRAINT fk__list_members__list_id__lists__id FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
+    CONSTRAINT fk__list_members__user_id__users__id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE list_members;
+
+DROP TABLE lists;
+
+DROP TABLE bookmarks;
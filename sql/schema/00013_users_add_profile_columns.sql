@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE users
+ADD display_name TEXT NOT NULL DEFAULT '',
+ADD bio TEXT NOT NULL DEFAULT '',
+ADD avatar_media_id UUID,
+ADD pinned_chirp_id UUID,
+ADD CONSTRAINT fk__users__avatar_media_id__media__id FOREIGN KEY (avatar_media_id) REFERENCES media(id) ON DELETE SET NULL,
+ADD CONSTRAINT fk__users__pinned_chirp_id__chirps__id FOREIGN KEY (pinned_chirp_id) REFERENCES chirps(id) ON DELETE SET NULL;
+
+CREATE INDEX idx__chirps__user_id ON chirps (user_id);
+
+-- +goose Down
+DROP INDEX idx__chirps__user_id;
+
+ALTER TABLE users
+DROP CONSTRAINT fk__users__pinned_chirp_id__chirps__id,
+DROP CONSTRAINT fk__users__avatar_media_id__media__id,
+DROP COLUMN pinned_chirp_id,
+DROP COLUMN avatar_media_id,
+DROP COLUMN bio,
+DROP COLUMN display_name;
@@ -0,0 +1,43 @@
+-- +goose Up
+CREATE TABLE conversations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    -- direct_key is "<lower user id>:<higher user id>" for one-to-one
+    -- conversations so that each pair of users shares a single one. It is
+    -- NULL for group conversations.
+    direct_key TEXT UNIQUE
+);
+
+CREATE TABLE conversation_members (
+    conversation_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    last_read_at TIMESTAMPTZ,
+    PRIMARY KEY (conversation_id, user_id),
+    CONSTRAINT fk__conversation_members__conversation_id__conversations__id FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
+    CONSTRAINT fk__conversation_members__user_id__users__id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx__conversation_members__user_id ON conversation_members (user_id);
+
+CREATE TABLE direct_messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- seq orders messages globally and is the cursor used by long polling.
+    seq BIGSERIAL NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    conversation_id UUID NOT NULL,
+    sender_id UUID NOT NULL,
+    body TEXT NOT NULL,
+    CONSTRAINT fk__direct_messages__conversation_id__conversations__id FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
+    CONSTRAINT fk__direct_messages__sender_id__users__id FOREIGN KEY (sender_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx__direct_messages__conversation_id__seq ON direct_messages (conversation_id, seq);
+
+-- +goose Down
+DROP TABLE direct_messages;
+
+DROP TABLE conversation_members;
+
+DROP TABLE conversations;
@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE users
+ADD deleted_at TIMESTAMPTZ,
+ADD is_moderator BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE chirps ADD deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx__users__deleted_at ON users (deleted_at)
+WHERE
+    deleted_at IS NOT NULL;
+
+CREATE INDEX idx__chirps__deleted_at ON chirps (deleted_at)
+WHERE
+    deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX idx__chirps__deleted_at;
+
+DROP INDEX idx__users__deleted_at;
+
+ALTER TABLE chirps DROP COLUMN deleted_at;
+
+ALTER TABLE users
+DROP COLUMN is_moderator,
+DROP COLUMN deleted_at;
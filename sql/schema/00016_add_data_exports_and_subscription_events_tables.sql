@@ -0,0 +1,36 @@
+-- +goose Up
+CREATE TABLE data_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    user_id UUID NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    -- Only a hash of the download token is kept; the token itself is handed
+    -- to the user once, when the export is requested.
+    token_hash TEXT NOT NULL UNIQUE,
+    blob_key TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    downloaded_at TIMESTAMPTZ,
+    CONSTRAINT fk__data_exports__user_id__users__id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT ck__data_exports__status CHECK (status IN ('pending', 'ready', 'failed'))
+);
+
+-- A user can only have one export being built at a time.
+CREATE UNIQUE INDEX uq__data_exports__user_id__pending ON data_exports (user_id)
+WHERE
+    status = 'pending';
+
+CREATE TABLE subscription_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    user_id UUID NOT NULL,
+    event TEXT NOT NULL,
+    CONSTRAINT fk__subscription_events__user_id__users__id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx__subscription_events__user_id ON subscription_events (user_id);
+
+-- +goose Down
+DROP TABLE subscription_events;
+
+DROP TABLE data_exports;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Token buckets shared by every replica when RATE_LIMIT_STORE is postgres.
+-- Rows are keyed by policy and caller, such as "login:ip:203.0.113.7".
+CREATE TABLE rate_limit_buckets (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx__rate_limit_buckets__updated_at ON rate_limit_buckets (updated_at);
+
+-- +goose Down
+DROP TABLE rate_limit_buckets;
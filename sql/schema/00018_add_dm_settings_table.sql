@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE dm_settings (
+    user_id UUID PRIMARY KEY,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    -- followed_only limits who may message the user to the people they
+    -- follow.
+    followed_only BOOLEAN NOT NULL DEFAULT FALSE,
+    CONSTRAINT fk__dm_settings__user_id__users__id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE dm_settings;
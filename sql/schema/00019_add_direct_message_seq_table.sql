@@ -0,0 +1,37 @@
+-- +goose Up
+-- direct_messages.seq came from a sequence, whose values can commit out of
+-- order: a long poll could move its cursor past a message still being
+-- committed and never return it. Seqs now come from this one-row counter.
+-- Sending a message holds its row lock until commit, so seqs become
+-- visible in order.
+CREATE TABLE direct_message_seq (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    last_seq BIGINT NOT NULL
+);
+
+INSERT INTO
+    direct_message_seq (last_seq)
+SELECT
+    COALESCE(MAX(seq), 0)
+FROM
+    direct_messages;
+
+ALTER TABLE direct_messages ALTER COLUMN seq DROP DEFAULT;
+
+DROP SEQUENCE direct_messages_seq_seq;
+
+-- +goose Down
+CREATE SEQUENCE direct_messages_seq_seq OWNED BY direct_messages.seq;
+
+SELECT
+    setval(
+        'direct_messages_seq_seq',
+        GREATEST(last_seq, 1),
+        last_seq > 0
+    )
+FROM
+    direct_message_seq;
+
+ALTER TABLE direct_messages ALTER COLUMN seq SET DEFAULT nextval('direct_messages_seq_seq');
+
+DROP TABLE direct_message_seq;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE follows (
+    follower_id UUID NOT NULL,
+    followee_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    PRIMARY KEY (follower_id, followee_id),
+    CONSTRAINT ck__follows__not_self CHECK (follower_id <> followee_id),
+    CONSTRAINT fk__follows__follower_id__users__id FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk__follows__followee_id__users__id FOREIGN KEY (followee_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx__follows__followee_id ON follows (followee_id);
+
+-- +goose Down
+DROP TABLE follows;
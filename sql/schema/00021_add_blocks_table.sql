@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE blocks (
+    blocker_id UUID NOT NULL,
+    blocked_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    PRIMARY KEY (blocker_id, blocked_id),
+    CONSTRAINT ck__blocks__not_self CHECK (blocker_id <> blocked_id),
+    CONSTRAINT fk__blocks__blocker_id__users__id FOREIGN KEY (blocker_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk__blocks__blocked_id__users__id FOREIGN KEY (blocked_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx__blocks__blocked_id ON blocks (blocked_id);
+
+-- +goose Down
+DROP TABLE blocks;
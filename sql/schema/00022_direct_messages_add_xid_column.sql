@@ -0,0 +1,49 @@
+-- +goose Up
+-- The one-row counter behind direct_messages.seq made every message wait
+-- for the one sent before it, in any conversation, to commit. Seqs come
+-- from a sequence again and only order history. Long polls page by xid
+-- instead, the id of the transaction that sent the message, and only
+-- return messages below the oldest transaction still running: those have
+-- all committed or rolled back, so no message can later appear behind a
+-- poll's cursor.
+ALTER TABLE direct_messages ADD xid BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE direct_messages ALTER COLUMN xid SET DEFAULT (pg_current_xact_id()::TEXT::BIGINT);
+
+CREATE INDEX idx__direct_messages__xid ON direct_messages (xid);
+
+CREATE SEQUENCE direct_messages_seq_seq OWNED BY direct_messages.seq;
+
+SELECT
+    setval(
+        'direct_messages_seq_seq',
+        GREATEST(last_seq, 1),
+        last_seq > 0
+    )
+FROM
+    direct_message_seq;
+
+ALTER TABLE direct_messages ALTER COLUMN seq SET DEFAULT nextval('direct_messages_seq_seq');
+
+DROP TABLE direct_message_seq;
+
+-- +goose Down
+CREATE TABLE direct_message_seq (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    last_seq BIGINT NOT NULL
+);
+
+INSERT INTO
+    direct_message_seq (last_seq)
+SELECT
+    COALESCE(MAX(seq), 0)
+FROM
+    direct_messages;
+
+ALTER TABLE direct_messages ALTER COLUMN seq DROP DEFAULT;
+
+DROP SEQUENCE direct_messages_seq_seq;
+
+DROP INDEX idx__direct_messages__xid;
+
+ALTER TABLE direct_messages DROP COLUMN xid;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD reply_to_id UUID,
+ADD CONSTRAINT fk__chirps__reply_to_id__chirps__id FOREIGN KEY (reply_to_id) REFERENCES chirps(id) ON DELETE SET NULL;
+
+CREATE INDEX idx__chirps__reply_to_id ON chirps (reply_to_id);
+
+-- +goose Down
+DROP INDEX idx__chirps__reply_to_id;
+
+ALTER TABLE chirps
+DROP CONSTRAINT fk__chirps__reply_to_id__chirps__id,
+DROP COLUMN reply_to_id;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE likes (
+    user_id UUID NOT NULL,
+    chirp_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    PRIMARY KEY (user_id, chirp_id),
+    CONSTRAINT fk__likes__user_id__users__id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk__likes__chirp_id__chirps__id FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx__likes__chirp_id ON likes (chirp_id);
+
+-- +goose Down
+DROP TABLE likes;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Archives are uploaded outside the transaction that claims an export, so
+-- the claim is recorded rather than held as a row lock. A claim older than
+-- the build timeout belongs to a builder that died and is taken over.
+ALTER TABLE data_exports ADD claimed_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE data_exports DROP COLUMN claimed_at;
@@ -0,0 +1,8 @@
+// Package schema embeds the goose migrations in this directory so the binary
+// can apply them without the SQL files or the goose CLI at hand.
+package schema
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS